@@ -1,32 +1,295 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	attachmentHandler "todo_list_api/internal/attachment/handler"
+	attachmentRepository "todo_list_api/internal/attachment/repository"
+	attachmentService "todo_list_api/internal/attachment/service"
+	"todo_list_api/internal/attachment/storage"
+	"todo_list_api/internal/auth"
+	collabHandler "todo_list_api/internal/collab/handler"
+	collabService "todo_list_api/internal/collab/service"
+	commentHandler "todo_list_api/internal/comment/handler"
+	commentRepository "todo_list_api/internal/comment/repository"
+	commentService "todo_list_api/internal/comment/service"
 	"todo_list_api/internal/db"
+	labelHandler "todo_list_api/internal/label/handler"
+	labelRepository "todo_list_api/internal/label/repository"
+	labelService "todo_list_api/internal/label/service"
+	"todo_list_api/internal/middleware"
+	outboxRepository "todo_list_api/internal/outbox/repository"
+	outboxService "todo_list_api/internal/outbox/service"
+	projectHandler "todo_list_api/internal/project/handler"
+	projectRepository "todo_list_api/internal/project/repository"
+	projectService "todo_list_api/internal/project/service"
+	streamHandler "todo_list_api/internal/stream/handler"
+	streamRepository "todo_list_api/internal/stream/repository"
+	streamService "todo_list_api/internal/stream/service"
 	"todo_list_api/internal/task/handler"
 	"todo_list_api/internal/task/repository"
 	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	userHandler "todo_list_api/internal/user/handler"
+	userRepository "todo_list_api/internal/user/repository"
+	userService "todo_list_api/internal/user/service"
+	webhookHandler "todo_list_api/internal/webhook/handler"
+	webhookRepository "todo_list_api/internal/webhook/repository"
+	webhookService "todo_list_api/internal/webhook/service"
+	"todo_list_api/pkg/models"
 )
 
 func main() {
-	db, err := db.Connect()
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+
+	jwtTTL := durationEnv("JWT_TTL", 24*time.Hour)
+	trashRetention := durationEnv("TRASH_RETENTION", 30*24*time.Hour)
+	purgeInterval := durationEnv("TRASH_PURGE_INTERVAL", time.Hour)
+	recurrenceHorizon := durationEnv("RECURRENCE_HORIZON", 7*24*time.Hour)
+	recurrenceInterval := durationEnv("RECURRENCE_INTERVAL", time.Hour)
+	webhookInterval := durationEnv("WEBHOOK_INTERVAL", 10*time.Second)
+	webhookTimeout := durationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	outboxInterval := durationEnv("OUTBOX_INTERVAL", time.Second)
+	outboxBackoff := durationEnv("OUTBOX_BACKOFF", 5*time.Second)
+	maxAttachmentSize := int64Env("MAX_ATTACHMENT_SIZE", models.DefaultMaxAttachmentSize)
+	allowedOrigins := listEnv("ALLOWED_ORIGINS")
+	collabLockTTL := durationEnv("COLLAB_LOCK_TTL", 2*time.Minute)
+
+	taskWorkflow := workflow.Default()
+	if path := os.Getenv("WORKFLOW_FILE"); path != "" {
+		loaded, err := workflow.Load(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		taskWorkflow = loaded
+	}
+
+	conn, err := db.Connect()
 	if err != nil {
 		log.Fatalf("could not connect to the database: %v", err)
 	}
 
+	if err := db.MigrateUp(conn); err != nil {
+		log.Fatalf("could not apply migrations: %v", err)
+	}
+
+	queryTimeout, err := db.QueryTimeout()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	mux := http.NewServeMux()
 
-	taskRepo := repository.NewTaskRepository(db)
-	taskService := service.NewTaskService(taskRepo)
+	tokens := auth.NewTokenManager([]byte(jwtSecret), jwtTTL)
+	protected := func(h http.HandlerFunc) http.Handler {
+		return auth.RequireAuth(tokens, h)
+	}
+	// Streams are opened by browsers from other origins and without headers,
+	// so they are also reachable with a stream token, from allowed origins.
+	streaming := func(h http.HandlerFunc) http.Handler {
+		return middleware.AllowOrigins(allowedOrigins, auth.RequireStreamAuth(tokens, h))
+	}
+
+	userRepo := userRepository.NewUserRepository(conn, queryTimeout)
+	userSvc := userService.NewUserService(userRepo, tokens)
+	usersHandler := userHandler.NewHandler(userSvc)
+
+	mux.HandleFunc("POST /auth/signup", usersHandler.SignUp)
+	mux.HandleFunc("POST /auth/login", usersHandler.Login)
+	mux.Handle("POST /auth/stream-token", protected(usersHandler.StreamToken))
+
+	webhookRepo := webhookRepository.NewWebhookRepository(conn, queryTimeout)
+	webhookSvc := webhookService.NewWebhookService(webhookRepo)
+	webhooksHandler := webhookHandler.NewHandler(webhookSvc)
+
+	webhookClient := webhookService.NewClient(webhookTimeout)
+	go webhookService.NewDispatcher(webhookRepo, webhookClient, 8, 30*time.Second).Run(context.Background(), webhookInterval)
+
+	mux.Handle("POST /webhooks", protected(webhooksHandler.CreateWebhook))
+	mux.Handle("GET /webhooks", protected(webhooksHandler.ListWebhooks))
+	mux.Handle("GET /webhooks/{id}", protected(webhooksHandler.GetWebhook))
+	mux.Handle("DELETE /webhooks/{id}", protected(webhooksHandler.DeleteWebhook))
+	mux.Handle("GET /webhooks/{id}/deliveries", protected(webhooksHandler.ListDeliveries))
+
+	streamRepo := streamRepository.NewStreamRepository(conn, queryTimeout)
+	broker := streamService.NewBroker(streamRepo)
+	streamsHandler := streamHandler.NewHandler(broker)
+
+	notified, err := streamRepository.Listen(context.Background(), db.DSN())
+	if err != nil {
+		log.Fatalf("could not listen for task events: %v", err)
+	}
+	go broker.Run(context.Background(), notified)
+
+	outboxRepo := outboxRepository.NewOutboxRepository(conn, queryTimeout)
+	publishers := outboxService.Publishers{
+		webhookService.NewPublisher(webhookRepo),
+		streamService.NewPublisher(streamRepo),
+	}
+	go outboxService.NewRelay(outboxRepo, publishers, outboxBackoff).Run(context.Background(), outboxInterval)
+
+	taskRepo := repository.NewTaskRepository(conn, queryTimeout)
+	taskService := service.NewTaskService(taskRepo, taskWorkflow)
 	taskHandler := handler.NewHandler(taskService)
 
-	mux.HandleFunc("POST /tasks", taskHandler.CreateTask)
-	mux.HandleFunc("GET /tasks/{id}", taskHandler.GetTask)
-	mux.HandleFunc("PUT /tasks/{id}", taskHandler.UpdateTask)
-	mux.HandleFunc("DELETE /tasks/{id}", taskHandler.DeleteTask)
-	mux.HandleFunc("GET /tasks", taskHandler.ListTasks)
+	go service.NewScheduler(taskRepo, taskWorkflow, recurrenceHorizon).Run(context.Background(), recurrenceInterval)
+
+	mux.Handle("POST /tasks", protected(taskHandler.CreateTask))
+	mux.Handle("GET /tasks/search", protected(taskHandler.SearchTasks))
+	mux.Handle("GET /tasks/trash", protected(taskHandler.ListTrash))
+	mux.Handle("GET /tasks/events", streaming(streamsHandler.StreamTaskEvents))
+	mux.Handle("GET /tasks/{id}", protected(taskHandler.GetTask))
+	mux.Handle("PUT /tasks/{id}", protected(taskHandler.UpdateTask))
+	mux.Handle("PATCH /tasks/{id}", protected(taskHandler.PatchTask))
+	mux.Handle("DELETE /tasks/{id}", protected(taskHandler.DeleteTask))
+	mux.Handle("POST /tasks/{id}/restore", protected(taskHandler.RestoreTask))
+	mux.Handle("GET /tasks/{id}/history", protected(taskHandler.ListTaskEvents))
+	mux.Handle("GET /tasks/{id}/subtasks", protected(taskHandler.ListSubtasks))
+	mux.Handle("GET /tasks", protected(taskHandler.ListTasks))
+	mux.Handle("GET /tasks/{id}/members", protected(taskHandler.ListTaskMembers))
+	mux.Handle("POST /tasks/{id}/members", protected(taskHandler.ShareTask))
+	mux.Handle("DELETE /tasks/{id}/members/{userID}", protected(taskHandler.UnshareTask))
+	mux.Handle("GET /tasks/{id}/dependencies", protected(taskHandler.ListTaskDependencies))
+	mux.Handle("POST /tasks/{id}/dependencies", protected(taskHandler.AddTaskDependency))
+	mux.Handle("DELETE /tasks/{id}/dependencies/{blockerID}", protected(taskHandler.RemoveTaskDependency))
+
+	projectRepo := projectRepository.NewProjectRepository(conn, queryTimeout)
+	projectSvc := projectService.NewProjectService(projectRepo, taskService)
+	projectsHandler := projectHandler.NewHandler(projectSvc)
+
+	mux.Handle("POST /projects", protected(projectsHandler.CreateProject))
+	mux.Handle("GET /projects", protected(projectsHandler.ListProjects))
+	mux.Handle("GET /projects/{id}", protected(projectsHandler.GetProject))
+	mux.Handle("PUT /projects/{id}", protected(projectsHandler.UpdateProject))
+	mux.Handle("DELETE /projects/{id}", protected(projectsHandler.DeleteProject))
+	mux.Handle("POST /projects/{id}/archive", protected(projectsHandler.ArchiveProject))
+	mux.Handle("POST /projects/{id}/unarchive", protected(projectsHandler.UnarchiveProject))
+	mux.Handle("GET /projects/{id}/tasks", protected(projectsHandler.ListProjectTasks))
+	mux.Handle("GET /projects/{id}/members", protected(projectsHandler.ListProjectMembers))
+	mux.Handle("POST /projects/{id}/members", protected(projectsHandler.ShareProject))
+	mux.Handle("DELETE /projects/{id}/members/{userID}", protected(projectsHandler.UnshareProject))
+
+	collabHub := collabService.NewHub(taskService, projectSvc, taskRepo, collabLockTTL)
+	collabsHandler := collabHandler.NewHandler(collabHub)
+
+	go collabHub.Run(context.Background())
+
+	mux.Handle("GET /projects/{id}/live", streaming(collabsHandler.ProjectChannel))
+
+	commentRepo := commentRepository.NewCommentRepository(conn, queryTimeout)
+	commentSvc := commentService.NewCommentService(commentRepo, taskRepo)
+	commentsHandler := commentHandler.NewHandler(commentSvc)
+
+	mux.Handle("GET /tasks/{id}/comments", protected(commentsHandler.ListComments))
+	mux.Handle("POST /tasks/{id}/comments", protected(commentsHandler.CreateComment))
+	mux.Handle("PUT /tasks/{id}/comments/{commentID}", protected(commentsHandler.UpdateComment))
+	mux.Handle("DELETE /tasks/{id}/comments/{commentID}", protected(commentsHandler.DeleteComment))
+
+	blobs, err := blobStore()
+	if err != nil {
+		log.Fatalf("could not open the blob store: %v", err)
+	}
+
+	go service.NewPurger(taskRepo, blobs, trashRetention).Run(context.Background(), purgeInterval)
+
+	attachmentRepo := attachmentRepository.NewAttachmentRepository(conn, queryTimeout)
+	attachmentSvc := attachmentService.NewAttachmentService(attachmentRepo, taskRepo, blobs, maxAttachmentSize)
+	attachmentsHandler := attachmentHandler.NewHandler(attachmentSvc, maxAttachmentSize)
+
+	mux.Handle("GET /tasks/{id}/attachments", protected(attachmentsHandler.ListAttachments))
+	mux.Handle("POST /tasks/{id}/attachments", protected(attachmentsHandler.UploadAttachment))
+	mux.Handle("GET /tasks/{id}/attachments/{attachmentID}", protected(attachmentsHandler.DownloadAttachment))
+	mux.Handle("DELETE /tasks/{id}/attachments/{attachmentID}", protected(attachmentsHandler.DeleteAttachment))
+
+	labelRepo := labelRepository.NewLabelRepository(conn, queryTimeout)
+	labelSvc := labelService.NewLabelService(labelRepo, taskRepo)
+	labelsHandler := labelHandler.NewHandler(labelSvc)
+
+	mux.Handle("POST /labels", protected(labelsHandler.CreateLabel))
+	mux.Handle("GET /labels", protected(labelsHandler.ListLabels))
+	mux.Handle("GET /labels/{id}", protected(labelsHandler.GetLabel))
+	mux.Handle("PUT /labels/{id}", protected(labelsHandler.UpdateLabel))
+	mux.Handle("DELETE /labels/{id}", protected(labelsHandler.DeleteLabel))
+	mux.Handle("PUT /tasks/{id}/labels/{labelID}", protected(labelsHandler.AttachLabel))
+	mux.Handle("DELETE /tasks/{id}/labels/{labelID}", protected(labelsHandler.DetachLabel))
 
 	log.Println("Server running on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", mux))
+	log.Fatal(http.ListenAndServe(":8080", middleware.RequestID(mux)))
+}
+
+// durationEnv reads a duration variable. Every duration configured here is
+// a period or a lifetime, and tickers panic on a period that is not
+// positive, so such values fall back to the default.
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", name, err)
+	}
+	if parsed <= 0 {
+		log.Printf("invalid %s: %s is not positive, using %s", name, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// listEnv splits a comma separated variable, dropping empty entries.
+func listEnv(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func int64Env(name string, fallback int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed <= 0 {
+		log.Fatalf("invalid %s: %q", name, value)
+	}
+	return parsed
+}
+
+// blobStore picks where attachment content lives from BLOB_STORE: "local"
+// (the default) keeps it under ATTACHMENT_DIR, "s3" in an S3-compatible
+// bucket configured by the S3_* variables.
+func blobStore() (storage.BlobStore, error) {
+	switch kind := os.Getenv("BLOB_STORE"); kind {
+	case "", "local":
+		dir := os.Getenv("ATTACHMENT_DIR")
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		return storage.NewLocalStore(dir)
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", kind)
+	}
 }
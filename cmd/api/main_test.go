@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationEnv(t *testing.T) {
+	t.Run("should return the fallback if the variable is unset", func(t *testing.T) {
+		t.Setenv("COLLAB_LOCK_TTL", "")
+		assert.Equal(t, 2*time.Minute, durationEnv("COLLAB_LOCK_TTL", 2*time.Minute))
+	})
+
+	t.Run("should parse a positive duration", func(t *testing.T) {
+		t.Setenv("COLLAB_LOCK_TTL", "30s")
+		assert.Equal(t, 30*time.Second, durationEnv("COLLAB_LOCK_TTL", 2*time.Minute))
+	})
+
+	t.Run("must fall back on a duration that is not positive", func(t *testing.T) {
+		for _, value := range []string{"0", "0s", "-1m"} {
+			t.Setenv("COLLAB_LOCK_TTL", value)
+			assert.Equal(t, 2*time.Minute, durationEnv("COLLAB_LOCK_TTL", 2*time.Minute), value)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"todo_list_api/internal/db"
+)
+
+func main() {
+	steps := flag.Int("steps", 1, "number of migrations to roll back with down")
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = "up"
+	}
+
+	conn, err := db.Connect()
+	if err != nil {
+		log.Fatalf("could not connect to the database: %v", err)
+	}
+	defer conn.Close()
+
+	switch command {
+	case "up":
+		if err := db.MigrateUp(conn); err != nil {
+			log.Fatalf("could not apply migrations: %v", err)
+		}
+		log.Println("Migrations applied")
+	case "down":
+		reverted, err := db.MigrateDown(conn, *steps)
+		if err != nil {
+			log.Fatalf("could not roll back migrations: %v", err)
+		}
+		log.Printf("Rolled back %d migration(s)", reverted)
+	case "status":
+		statuses, err := db.Status(conn)
+		if err != nil {
+			log.Fatalf("could not read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		log.Fatalf("unknown command %q, expected up, down or status", command)
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	s "todo_list_api/internal/attachment/service"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// filePart is the name of the multipart form field that carries the upload.
+const filePart = "file"
+
+// formOverhead is how much a multipart body may exceed the attachment size
+// limit, to leave room for boundaries, part headers and small form fields.
+const formOverhead = 64 << 10
+
+type Handler struct {
+	service s.Service
+	maxBody int64
+}
+
+func NewHandler(service s.Service, maxSize int64) *Handler {
+	return &Handler{service: service, maxBody: maxSize + formOverhead}
+}
+
+// UploadAttachment reads a multipart/form-data body and streams its "file"
+// part to the service without buffering it. Other parts are ignored, but
+// count towards the cap on the whole body.
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBody)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		utils.WriteError(w, utils.ErrUnsupportedMedia)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			utils.WriteError(w, utils.ErrMissingFile)
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, bodyError(err, utils.ErrInvalidPayload))
+			return
+		}
+
+		if part.FormName() != filePart {
+			part.Close()
+			continue
+		}
+
+		attachment := models.Attachment{
+			TaskID:     taskID,
+			UploaderID: userID,
+			Filename:   part.FileName(),
+		}
+		err = h.service.UploadAttachment(r.Context(), &attachment, part)
+		part.Close()
+		if err != nil {
+			utils.WriteError(w, bodyError(err, err))
+			return
+		}
+
+		httputil.WriteJSON(w, http.StatusCreated, &attachment)
+		return
+	}
+}
+
+func (h *Handler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.ListAttachments(r.Context(), userID, taskID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, attachments)
+}
+
+// DownloadAttachment streams the content of an attachment. A single byte
+// range is honoured with 206 Partial Content; a Range header asking for
+// several ranges is ignored and the whole file is sent.
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "attachmentID")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service.GetAttachment(r.Context(), userID, taskID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	offset, length, partial, err := parseRange(r.Header.Get("Range"), attachment.Size)
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", attachment.Size))
+		utils.WriteError(w, err)
+		return
+	}
+
+	var body io.ReadCloser
+	if r.Method != http.MethodHead {
+		body, err = h.service.OpenAttachment(r.Context(), attachment, offset, length)
+		if err != nil {
+			utils.WriteError(w, err)
+			return
+		}
+		defer body.Close()
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", attachment.ContentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	status := http.StatusOK
+	if partial {
+		status = http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, attachment.Size))
+	}
+	w.WriteHeader(status)
+
+	if body != nil {
+		if _, err := io.Copy(w, body); err != nil {
+			log.Printf("could not stream attachment %d: %v", attachment.ID, err)
+		}
+	}
+}
+
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "attachmentID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(r.Context(), userID, taskID, ID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bodyError reports a read that hit the body cap as ErrAttachmentTooLarge
+// and any other error as fallback.
+func bodyError(err, fallback error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return utils.ErrAttachmentTooLarge
+	}
+	return fallback
+}
+
+// parseRange resolves a Range header against a file of the given size. It
+// returns the whole file, with partial unset, when there is no header or the
+// header is not a single well-formed byte range.
+func parseRange(header string, size int64) (offset, length int64, partial bool, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, size, false, nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, size, false, nil
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, size, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, utils.ErrInvalidRange
+		}
+		suffix = min(suffix, size)
+		return size - suffix, suffix, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size, false, nil
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, size, false, nil
+		}
+		end = min(end, size-1)
+	}
+
+	if start >= size {
+		return 0, 0, false, utils.ErrInvalidRange
+	}
+
+	return start, end - start + 1, true, nil
+}
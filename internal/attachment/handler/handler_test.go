@@ -0,0 +1,200 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo_list_api/internal/attachment/handler"
+	m "todo_list_api/internal/attachment/mocks"
+	"todo_list_api/internal/auth"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func multipartBody(t *testing.T, field, filename, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("note", "ignored"))
+	part, err := writer.CreateFormFile(field, filename)
+	assert.NoError(t, err)
+	io.WriteString(part, content)
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestUploadAttachment(t *testing.T) {
+	mockService := new(m.MockService)
+	capped := handler.NewHandler(mockService, 0)
+	handler := handler.NewHandler(mockService, 1<<20)
+
+	t.Run("should return 415 if the body is not multipart", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/tasks/1/attachments", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.UploadAttachment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	})
+
+	t.Run("should return 400 if there is no file part", func(t *testing.T) {
+		body, contentType := multipartBody(t, "other", "crash.log", "boom")
+		req, _ := http.NewRequest("POST", "/tasks/1/attachments", body)
+		req.Header.Set("Content-Type", contentType)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.UploadAttachment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "missing_file", decodeError(t, rr).Code)
+	})
+
+	t.Run("should return 201 and stream the file part to the service", func(t *testing.T) {
+		mockService.On("UploadAttachment", mock.Anything, &models.Attachment{TaskID: 1, UploaderID: userID, Filename: "crash.log"}, mock.MatchedBy(func(body io.Reader) bool {
+			data, _ := io.ReadAll(body)
+			return string(data) == "boom"
+		})).Return(nil).Once()
+
+		body, contentType := multipartBody(t, "file", "crash.log", "boom")
+		req, _ := http.NewRequest("POST", "/tasks/1/attachments", body)
+		req.Header.Set("Content-Type", contentType)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.UploadAttachment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 413 if the file is too large", func(t *testing.T) {
+		mockService.On("UploadAttachment", mock.Anything, mock.Anything, mock.Anything).Return(utils.ErrAttachmentTooLarge).Once()
+
+		body, contentType := multipartBody(t, "file", "huge.log", "boom")
+		req, _ := http.NewRequest("POST", "/tasks/1/attachments", body)
+		req.Header.Set("Content-Type", contentType)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.UploadAttachment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("should return 413 if the form fields exceed the body cap", func(t *testing.T) {
+		body, contentType := multipartBody(t, "other", "padding.txt", strings.Repeat("x", 128<<10))
+		req, _ := http.NewRequest("POST", "/tasks/1/attachments", body)
+		req.Header.Set("Content-Type", contentType)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		capped.UploadAttachment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Equal(t, "attachment_too_large", decodeError(t, rr).Code)
+	})
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService, 1<<20)
+
+	attachment := &models.Attachment{ID: 3, TaskID: 1, Filename: "crash.log", ContentType: "text/plain; charset=utf-8", Size: 12, StorageKey: "tasks/1/abc"}
+
+	download := func(rangeHeader string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/tasks/1/attachments/3", nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		req.SetPathValue("id", "1")
+		req.SetPathValue("attachmentID", "3")
+		rr := httptest.NewRecorder()
+		handler.DownloadAttachment(rr, withUser(req))
+		return rr
+	}
+
+	t.Run("must stream the whole file", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return(attachment, nil).Once()
+		mockService.On("OpenAttachment", mock.Anything, attachment, int64(0), int64(12)).Return(io.NopCloser(strings.NewReader("hello, world")), nil).Once()
+
+		rr := download("")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "hello, world", rr.Body.String())
+		assert.Equal(t, "12", rr.Header().Get("Content-Length"))
+		assert.Equal(t, "bytes", rr.Header().Get("Accept-Ranges"))
+		assert.Equal(t, `attachment; filename=crash.log`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("must serve a single byte range", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return(attachment, nil).Once()
+		mockService.On("OpenAttachment", mock.Anything, attachment, int64(7), int64(5)).Return(io.NopCloser(strings.NewReader("world")), nil).Once()
+
+		rr := download("bytes=7-")
+
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, "world", rr.Body.String())
+		assert.Equal(t, "bytes 7-11/12", rr.Header().Get("Content-Range"))
+	})
+
+	t.Run("must serve a suffix range", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return(attachment, nil).Once()
+		mockService.On("OpenAttachment", mock.Anything, attachment, int64(10), int64(2)).Return(io.NopCloser(strings.NewReader("ld")), nil).Once()
+
+		rr := download("bytes=-2")
+
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, "bytes 10-11/12", rr.Header().Get("Content-Range"))
+	})
+
+	t.Run("should return 416 if the range starts past the end", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return(attachment, nil).Once()
+
+		rr := download("bytes=12-20")
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rr.Code)
+		assert.Equal(t, "bytes */12", rr.Header().Get("Content-Range"))
+	})
+
+	t.Run("should ignore a request for several ranges", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return(attachment, nil).Once()
+		mockService.On("OpenAttachment", mock.Anything, attachment, int64(0), int64(12)).Return(io.NopCloser(strings.NewReader("hello, world")), nil).Once()
+
+		rr := download("bytes=0-1,4-5")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 404 if the attachment is not on the task", func(t *testing.T) {
+		mockService.On("GetAttachment", mock.Anything, userID, int64(1), int64(3)).Return((*models.Attachment)(nil), utils.ErrAttachmentNotFound).Once()
+
+		rr := download("")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "attachment_not_found", decodeError(t, rr).Code)
+	})
+}
@@ -0,0 +1,36 @@
+package attachment
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBlobStore struct {
+	mock.Mock
+}
+
+// Delete implements storage.BlobStore.
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// Get implements storage.BlobStore.
+func (m *MockBlobStore) Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, key, offset, length)
+	body, _ := args.Get(0).(io.ReadCloser)
+	return body, args.Error(1)
+}
+
+// Put implements storage.BlobStore. The body is drained so that callers see
+// the same reads as with a real store.
+func (m *MockBlobStore) Put(ctx context.Context, key, contentType string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	args := m.Called(ctx, key, contentType, data)
+	return args.Error(0)
+}
@@ -0,0 +1,36 @@
+package attachment
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// CreateAttachment implements Repository.
+func (m *MockRepository) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+// DeleteAttachment implements Repository.
+func (m *MockRepository) DeleteAttachment(ctx context.Context, taskID, id int64) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+// GetAttachment implements Repository.
+func (m *MockRepository) GetAttachment(ctx context.Context, taskID, id int64) (*models.Attachment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+// ListAttachments implements Repository.
+func (m *MockRepository) ListAttachments(ctx context.Context, taskID int64) ([]*models.Attachment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
@@ -0,0 +1,44 @@
+package attachment
+
+import (
+	"context"
+	"io"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// DeleteAttachment implements attachment.Service.
+func (m *MockService) DeleteAttachment(ctx context.Context, userID, taskID, id int64) error {
+	args := m.Called(ctx, userID, taskID, id)
+	return args.Error(0)
+}
+
+// GetAttachment implements attachment.Service.
+func (m *MockService) GetAttachment(ctx context.Context, userID, taskID, id int64) (*models.Attachment, error) {
+	args := m.Called(ctx, userID, taskID, id)
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+// ListAttachments implements attachment.Service.
+func (m *MockService) ListAttachments(ctx context.Context, userID, taskID int64) ([]*models.Attachment, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+// OpenAttachment implements attachment.Service.
+func (m *MockService) OpenAttachment(ctx context.Context, attachment *models.Attachment, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, attachment, offset, length)
+	body, _ := args.Get(0).(io.ReadCloser)
+	return body, args.Error(1)
+}
+
+// UploadAttachment implements attachment.Service.
+func (m *MockService) UploadAttachment(ctx context.Context, attachment *models.Attachment, body io.Reader) error {
+	args := m.Called(ctx, attachment, body)
+	return args.Error(0)
+}
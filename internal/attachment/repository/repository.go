@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Repository interface {
+	CreateAttachment(ctx context.Context, attachment *models.Attachment) error
+	GetAttachment(ctx context.Context, taskID, id int64) (*models.Attachment, error)
+	ListAttachments(ctx context.Context, taskID int64) ([]*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, taskID, id int64) error
+}
+
+const attachmentColumns = "id, task_id, uploader_id, filename, content_type, size, storage_key, created_at"
+
+type AttachmentRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewAttachmentRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &AttachmentRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAttachment(row scanner, attachment *models.Attachment) error {
+	return row.Scan(
+		&attachment.ID,
+		&attachment.TaskID,
+		&attachment.UploaderID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+	)
+}
+
+// CreateAttachment records an uploaded blob against a live task.
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "INSERT INTO attachments (task_id, uploader_id, filename, content_type, size, storage_key, created_at) SELECT id, $2, $3, $4, $5, $6, $7 FROM tasks WHERE id = $1 AND deleted_at IS NULL RETURNING id"
+	attachment.CreatedAt = time.Now()
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		attachment.TaskID,
+		attachment.UploaderID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		attachment.CreatedAt,
+	).Scan(&attachment.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.ErrTaskNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *AttachmentRepository) GetAttachment(ctx context.Context, taskID, id int64) (*models.Attachment, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + attachmentColumns + " FROM attachments WHERE id = $1 AND task_id = $2"
+	attachment := &models.Attachment{}
+	if err := scanAttachment(r.db.QueryRowContext(ctx, query, id, taskID), attachment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (r *AttachmentRepository) ListAttachments(ctx context.Context, taskID int64) ([]*models.Attachment, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + attachmentColumns + " FROM attachments WHERE task_id = $1 ORDER BY id ASC"
+	rows, err := r.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		var attachment models.Attachment
+		if err := scanAttachment(rows, &attachment); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, &attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *AttachmentRepository) DeleteAttachment(ctx context.Context, taskID, id int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM attachments WHERE id = $1 AND task_id = $2"
+	result, err := r.db.ExecContext(ctx, query, id, taskID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrAttachmentNotFound
+	}
+	return nil
+}
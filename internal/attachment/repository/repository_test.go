@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/attachment/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "task_id", "uploader_id", "filename", "content_type", "size", "storage_key", "created_at"}
+
+func TestCreateAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAttachmentRepository(db, time.Second)
+	const insert = "INSERT INTO attachments \\(task_id, uploader_id, filename, content_type, size, storage_key, created_at\\) SELECT id, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7 FROM tasks WHERE id = \\$1 AND deleted_at IS NULL RETURNING id"
+
+	t.Run("must record the attachment", func(t *testing.T) {
+		attachment := &models.Attachment{TaskID: 1, UploaderID: 7, Filename: "crash.log", ContentType: "text/plain; charset=utf-8", Size: 42, StorageKey: "tasks/1/abc"}
+
+		mock.ExpectQuery(insert).
+			WithArgs(int64(1), int64(7), "crash.log", "text/plain; charset=utf-8", int64(42), "tasks/1/abc", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+		assert.NoError(t, repo.CreateAttachment(context.Background(), attachment))
+		assert.Equal(t, int64(3), attachment.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task is gone", func(t *testing.T) {
+		mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := repo.CreateAttachment(context.Background(), &models.Attachment{TaskID: 9})
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListAttachments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAttachmentRepository(db, time.Second)
+
+	t.Run("must list the attachments of a task in upload order", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT id, task_id, uploader_id, filename, content_type, size, storage_key, created_at FROM attachments WHERE task_id = \\$1 ORDER BY id ASC").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, 1, 7, "shot.png", "image/png", 1024, "tasks/1/abc", now).
+				AddRow(4, 1, 8, "crash.log", "text/plain", 42, "tasks/1/def", now))
+
+		attachments, err := repo.ListAttachments(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, attachments, 2)
+		assert.Equal(t, "tasks/1/def", attachments[1].StorageKey)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAttachmentRepository(db, time.Second)
+
+	t.Run("should return nil if the attachment is not on the task", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM attachments WHERE id = \\$1 AND task_id = \\$2").
+			WithArgs(int64(3), int64(2)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		attachment, err := repo.GetAttachment(context.Background(), 2, 3)
+		assert.NoError(t, err)
+		assert.Nil(t, attachment)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAttachmentRepository(db, time.Second)
+
+	t.Run("should return ErrAttachmentNotFound if nothing was deleted", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM attachments WHERE id = \\$1 AND task_id = \\$2").
+			WithArgs(int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteAttachment(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, utils.ErrAttachmentNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	r "todo_list_api/internal/attachment/repository"
+	"todo_list_api/internal/attachment/storage"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Service interface {
+	UploadAttachment(ctx context.Context, attachment *models.Attachment, body io.Reader) error
+	ListAttachments(ctx context.Context, userID, taskID int64) ([]*models.Attachment, error)
+	GetAttachment(ctx context.Context, userID, taskID, id int64) (*models.Attachment, error)
+	OpenAttachment(ctx context.Context, attachment *models.Attachment, offset, length int64) (io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, userID, taskID, id int64) error
+}
+
+// TaskRoles looks up what a user may do with a task's files. Trashed tasks
+// resolve to no role, so their attachments stay hidden until restored.
+type TaskRoles interface {
+	GetTaskRole(ctx context.Context, userID, taskID int64) (string, error)
+}
+
+type AttachmentService struct {
+	repo    r.Repository
+	roles   TaskRoles
+	store   storage.BlobStore
+	maxSize int64
+}
+
+func NewAttachmentService(repo r.Repository, roles TaskRoles, store storage.BlobStore, maxSize int64) Service {
+	return &AttachmentService{repo: repo, roles: roles, store: store, maxSize: maxSize}
+}
+
+// UploadAttachment streams body into blob storage and records it as uploaded
+// by attachment.UploaderID. The content type is sniffed from the content
+// rather than taken from the client, and must be one of
+// models.AttachmentTypes.
+func (s *AttachmentService) UploadAttachment(ctx context.Context, attachment *models.Attachment, body io.Reader) error {
+	if attachment.TaskID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if _, err := s.requireRole(ctx, attachment.UploaderID, attachment.TaskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !models.AttachmentTypes[mediaType] {
+		return utils.ErrAttachmentType
+	}
+
+	key, err := newKey(attachment.TaskID)
+	if err != nil {
+		return err
+	}
+
+	content := &sizeLimiter{r: io.MultiReader(bytes.NewReader(head), body), max: s.maxSize}
+	if err := s.store.Put(ctx, key, contentType, content); err != nil {
+		return err
+	}
+
+	attachment.Filename = cleanFilename(attachment.Filename)
+	attachment.ContentType = contentType
+	attachment.Size = content.size
+	attachment.StorageKey = key
+
+	if err := s.repo.CreateAttachment(ctx, attachment); err != nil {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("could not remove blob %s: %v", key, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, userID, taskID int64) ([]*models.Attachment, error) {
+	if taskID < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if _, err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAttachments(ctx, taskID)
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, userID, taskID, id int64) (*models.Attachment, error) {
+	if taskID < 0 || id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if _, err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.storedAttachment(ctx, taskID, id)
+}
+
+// OpenAttachment streams the content of an attachment returned by
+// GetAttachment, which is where access is checked.
+func (s *AttachmentService) OpenAttachment(ctx context.Context, attachment *models.Attachment, offset, length int64) (io.ReadCloser, error) {
+	body, err := s.store.Get(ctx, attachment.StorageKey, offset, length)
+	if errors.Is(err, storage.ErrNotExist) {
+		return nil, utils.ErrAttachmentNotFound
+	}
+	return body, err
+}
+
+// DeleteAttachment removes an attachment. Uploaders may remove their own
+// attachments and the task owner may remove any of them.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, userID, taskID, id int64) error {
+	if taskID < 0 || id < 0 {
+		return utils.ErrInvalidId
+	}
+
+	role, err := s.requireRole(ctx, userID, taskID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.storedAttachment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+
+	if role != models.RoleOwner && stored.UploaderID != userID {
+		return utils.ErrForbidden
+	}
+
+	if err := s.repo.DeleteAttachment(ctx, taskID, id); err != nil {
+		return err
+	}
+
+	// The record is gone, so a blob left behind is unreachable: log it
+	// rather than fail the request.
+	if err := s.store.Delete(ctx, stored.StorageKey); err != nil {
+		log.Printf("could not remove blob %s: %v", stored.StorageKey, err)
+	}
+
+	return nil
+}
+
+func (s *AttachmentService) storedAttachment(ctx context.Context, taskID, id int64) (*models.Attachment, error) {
+	attachment, err := s.repo.GetAttachment(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment == nil {
+		return nil, utils.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+func (s *AttachmentService) requireRole(ctx context.Context, userID, taskID int64, required string) (string, error) {
+	role, err := s.roles.GetTaskRole(ctx, userID, taskID)
+	if err != nil {
+		return "", err
+	}
+
+	if !utils.HasRole(role, required) {
+		return "", utils.ErrForbidden
+	}
+
+	return role, nil
+}
+
+// sizeLimiter counts the bytes read through it and fails with
+// ErrAttachmentTooLarge once more than max have been read.
+type sizeLimiter struct {
+	r    io.Reader
+	max  int64
+	size int64
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.size += int64(n)
+	if l.size > l.max {
+		return n, utils.ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+func newKey(taskID int64) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(random)), nil
+}
+
+// cleanFilename keeps the base name of a client-supplied filename, whichever
+// path separator the client used.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
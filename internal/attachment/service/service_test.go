@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	m "todo_list_api/internal/attachment/mocks"
+	"todo_list_api/internal/attachment/service"
+	"todo_list_api/internal/attachment/storage"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var png = "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 24)
+
+func TestUploadAttachment(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	mockStore := new(m.MockBlobStore)
+	svc := service.NewAttachmentService(mockRepo, mockRoles, mockStore, 64)
+
+	t.Run("should not let viewers upload", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleViewer, nil).Once()
+
+		err := svc.UploadAttachment(context.Background(), &models.Attachment{TaskID: 1, UploaderID: 7}, strings.NewReader(png))
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject content that is not an allowed type", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+
+		err := svc.UploadAttachment(context.Background(), &models.Attachment{TaskID: 1, UploaderID: 7, Filename: "page.png"}, strings.NewReader("<html><script>"))
+		assert.ErrorIs(t, err, utils.ErrAttachmentType)
+	})
+
+	t.Run("should reject files over the size limit", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+
+		err := svc.UploadAttachment(context.Background(), &models.Attachment{TaskID: 1, UploaderID: 7}, strings.NewReader(strings.Repeat("log line\n", 10)))
+		assert.ErrorIs(t, err, utils.ErrAttachmentTooLarge)
+		mockRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("must store the blob and record the sniffed type and size", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "tasks/1/") }), "image/png", []byte(png)).Return(nil).Once()
+		mockRepo.On("CreateAttachment", mock.Anything, mock.Anything).Return(nil).Once()
+
+		attachment := &models.Attachment{TaskID: 1, UploaderID: 7, Filename: `C:\Users\ana\shot.png`, ContentType: "text/html"}
+		assert.NoError(t, svc.UploadAttachment(context.Background(), attachment, strings.NewReader(png)))
+		assert.Equal(t, "shot.png", attachment.Filename)
+		assert.Equal(t, "image/png", attachment.ContentType)
+		assert.Equal(t, int64(len(png)), attachment.Size)
+		mockStore.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should remove the blob if it cannot be recorded", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockStore.On("Put", mock.Anything, mock.Anything, "image/png", []byte(png)).Return(nil).Once()
+		mockRepo.On("CreateAttachment", mock.Anything, mock.Anything).Return(errors.New("insert failed")).Once()
+		mockStore.On("Delete", mock.Anything, mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "tasks/1/") })).Return(nil).Once()
+
+		err := svc.UploadAttachment(context.Background(), &models.Attachment{TaskID: 1, UploaderID: 7}, strings.NewReader(png))
+		assert.Error(t, err)
+		mockStore.AssertExpectations(t)
+	})
+}
+
+func TestOpenAttachment(t *testing.T) {
+	mockStore := new(m.MockBlobStore)
+	svc := service.NewAttachmentService(new(m.MockRepository), new(m.MockTaskRoles), mockStore, 64)
+
+	t.Run("should return ErrAttachmentNotFound if the blob is missing", func(t *testing.T) {
+		mockStore.On("Get", mock.Anything, "tasks/1/abc", int64(0), int64(10)).Return(nil, storage.ErrNotExist).Once()
+
+		_, err := svc.OpenAttachment(context.Background(), &models.Attachment{StorageKey: "tasks/1/abc"}, 0, 10)
+		assert.ErrorIs(t, err, utils.ErrAttachmentNotFound)
+	})
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	mockStore := new(m.MockBlobStore)
+	svc := service.NewAttachmentService(mockRepo, mockRoles, mockStore, 64)
+
+	stored := &models.Attachment{ID: 3, TaskID: 1, UploaderID: 8, StorageKey: "tasks/1/abc"}
+
+	t.Run("should stop editors from removing other people's attachments", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetAttachment", mock.Anything, int64(1), int64(3)).Return(stored, nil).Once()
+
+		err := svc.DeleteAttachment(context.Background(), 7, 1, 3)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "DeleteAttachment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("must let the task owner remove the record and the blob", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetAttachment", mock.Anything, int64(1), int64(3)).Return(stored, nil).Once()
+		mockRepo.On("DeleteAttachment", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+		mockStore.On("Delete", mock.Anything, "tasks/1/abc").Return(errors.New("unreachable")).Once()
+
+		assert.NoError(t, svc.DeleteAttachment(context.Background(), 7, 1, 3))
+		mockRepo.AssertExpectations(t)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrAttachmentNotFound if the attachment is not on the task", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetAttachment", mock.Anything, int64(1), int64(4)).Return((*models.Attachment)(nil), nil).Once()
+
+		err := svc.DeleteAttachment(context.Background(), 7, 1, 4)
+		assert.ErrorIs(t, err, utils.ErrAttachmentNotFound)
+	})
+}
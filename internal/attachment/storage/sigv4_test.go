@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The cases below come from the AWS Signature Version 4 test suite.
+func TestSign(t *testing.T) {
+	creds := credentials{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+		service:         "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	t.Run("must sign a plain GET", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+
+		creds.sign(req, emptyPayloadSHA, now)
+
+		assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+	})
+
+	t.Run("must sort the query parameters", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+
+		creds.sign(req, emptyPayloadSHA, now)
+
+		assert.Contains(t, req.Header.Get("Authorization"), "Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500")
+	})
+}
+
+func TestURIEncode(t *testing.T) {
+	assert.Equal(t, "tasks/1/a%20b%2Bc~", uriEncode("tasks/1/a b+c~", false))
+	assert.Equal(t, "a%2Fb", uriEncode("a/b", true))
+}
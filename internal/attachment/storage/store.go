@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+var ErrNotExist = errors.New("blob does not exist")
+
+// BlobStore keeps opaque blobs under slash-separated keys such as
+// "tasks/1/3f2a".
+type BlobStore interface {
+	// Put stores body under key, replacing any blob already there.
+	Put(ctx context.Context, key, contentType string, body io.Reader) error
+	// Get streams the blob under key starting at offset. A negative length
+	// reads to the end of the blob.
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+func validKey(key string) bool {
+	return key != "." && fs.ValidPath(key)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
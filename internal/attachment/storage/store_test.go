@@ -0,0 +1,124 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todo_list_api/internal/attachment/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 is a local stand-in for an S3-compatible service. It keeps objects
+// in memory and rejects requests that are unsigned or whose body does not
+// match the signed hash.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/s3/aws4_request") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		object, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(object))
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func stores(t *testing.T) map[string]storage.BlobStore {
+	local, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}})
+	t.Cleanup(server.Close)
+
+	s3 := storage.NewS3Store(storage.S3Config{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "attachments",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	}, server.Client())
+
+	return map[string]storage.BlobStore{"local": local, "s3": s3}
+}
+
+func read(t *testing.T, store storage.BlobStore, key string, offset, length int64) string {
+	body, err := store.Get(context.Background(), key, offset, length)
+	assert.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestBlobStores(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			const key = "tasks/1/abc"
+
+			t.Run("must store and stream back a blob", func(t *testing.T) {
+				assert.NoError(t, store.Put(ctx, key, "text/plain", strings.NewReader("hello, world")))
+				assert.Equal(t, "hello, world", read(t, store, key, 0, -1))
+			})
+
+			t.Run("must serve byte ranges", func(t *testing.T) {
+				assert.Equal(t, "world", read(t, store, key, 7, 5))
+				assert.Equal(t, "world", read(t, store, key, 7, -1))
+				assert.Equal(t, "", read(t, store, key, 3, 0))
+			})
+
+			t.Run("must replace an existing blob", func(t *testing.T) {
+				assert.NoError(t, store.Put(ctx, key, "text/plain", strings.NewReader("bye")))
+				assert.Equal(t, "bye", read(t, store, key, 0, -1))
+			})
+
+			t.Run("should return ErrNotExist once deleted", func(t *testing.T) {
+				assert.NoError(t, store.Delete(ctx, key))
+				assert.NoError(t, store.Delete(ctx, key))
+
+				_, err := store.Get(ctx, key, 0, -1)
+				assert.ErrorIs(t, err, storage.ErrNotExist)
+			})
+
+			t.Run("should reject keys that escape the store", func(t *testing.T) {
+				assert.Error(t, store.Put(ctx, "../secret", "text/plain", strings.NewReader("x")))
+				assert.Error(t, store.Put(ctx, "/etc/passwd", "text/plain", strings.NewReader("x")))
+			})
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"todo_list_api/pkg/utils"
+)
+
+const tokenIssuer = "todo_list_api"
+
+const (
+	// streamScope marks the tokens that may only open event streams.
+	streamScope = "stream"
+	// streamTokenTTL only has to cover the time it takes a client to connect:
+	// an open stream is not cut off when its token expires.
+	streamTokenTTL = time.Minute
+)
+
+type claims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	Scope     string `json:"scope,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type TokenManager struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewTokenManager(secret []byte, ttl time.Duration) *TokenManager {
+	return &TokenManager{secret: secret, ttl: ttl, now: time.Now}
+}
+
+func (m *TokenManager) Issue(userID int64) (string, time.Time, error) {
+	return m.issue(userID, "", m.ttl)
+}
+
+// IssueStreamToken returns a short-lived token that VerifyStreamToken
+// accepts and Verify does not, for clients that have to put it in a URL.
+func (m *TokenManager) IssueStreamToken(userID int64) (string, time.Time, error) {
+	return m.issue(userID, streamScope, streamTokenTTL)
+}
+
+func (m *TokenManager) issue(userID int64, scope string, ttl time.Duration) (string, time.Time, error) {
+	now := m.now()
+	expiresAt := now.Add(ttl)
+
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	payload, err := json.Marshal(claims{
+		Subject:   strconv.FormatInt(userID, 10),
+		Issuer:    tokenIssuer,
+		Scope:     scope,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := base64.RawURLEncoding.EncodeToString(m.sign(signingInput))
+
+	return signingInput + "." + signature, expiresAt, nil
+}
+
+func (m *TokenManager) Verify(token string) (int64, error) {
+	return m.verify(token, "")
+}
+
+func (m *TokenManager) VerifyStreamToken(token string) (int64, error) {
+	return m.verify(token, streamScope)
+}
+
+func (m *TokenManager) verify(token, scope string) (int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, utils.ErrInvalidToken
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return 0, utils.ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "HS256" {
+		return 0, utils.ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, m.sign(parts[0]+"."+parts[1])) {
+		return 0, utils.ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return 0, utils.ErrInvalidToken
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Issuer != tokenIssuer || c.Scope != scope {
+		return 0, utils.ErrInvalidToken
+	}
+
+	if m.now().Unix() >= c.ExpiresAt {
+		return 0, utils.ErrExpiredToken
+	}
+
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, utils.ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+func (m *TokenManager) sign(signingInput string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
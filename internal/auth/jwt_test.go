@@ -0,0 +1,82 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"todo_list_api/internal/auth"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenManager(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+
+	t.Run("should issue a token that verifies back to the user id", func(t *testing.T) {
+		token, expiresAt, err := tokens.Issue(42)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Split(token, "."), 3)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+
+		userID, err := tokens.Verify(token)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), userID)
+	})
+
+	t.Run("should reject a token signed with a different key", func(t *testing.T) {
+		other := auth.NewTokenManager([]byte("other secret"), time.Hour)
+		token, _, err := other.Issue(42)
+		assert.NoError(t, err)
+
+		_, err = tokens.Verify(token)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+
+	t.Run("should reject a tampered token", func(t *testing.T) {
+		token, _, err := tokens.Issue(42)
+		assert.NoError(t, err)
+
+		parts := strings.Split(token, ".")
+		forged, _, err := auth.NewTokenManager([]byte("secret"), time.Hour).Issue(1)
+		assert.NoError(t, err)
+		parts[1] = strings.Split(forged, ".")[1]
+
+		_, err = tokens.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+
+	t.Run("should reject an expired token", func(t *testing.T) {
+		expired := auth.NewTokenManager([]byte("secret"), -time.Minute)
+		token, _, err := expired.Issue(42)
+		assert.NoError(t, err)
+
+		_, err = tokens.Verify(token)
+		assert.ErrorIs(t, err, utils.ErrExpiredToken)
+	})
+
+	t.Run("should reject malformed tokens", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "a.b.c", "a.b"} {
+			_, err := tokens.Verify(token)
+			assert.ErrorIs(t, err, utils.ErrInvalidToken)
+		}
+	})
+
+	t.Run("should keep stream tokens and regular tokens apart", func(t *testing.T) {
+		stream, expiresAt, err := tokens.IssueStreamToken(42)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+		userID, err := tokens.VerifyStreamToken(stream)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), userID)
+
+		_, err = tokens.Verify(stream)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+
+		regular, _, err := tokens.Issue(42)
+		assert.NoError(t, err)
+		_, err = tokens.VerifyStreamToken(regular)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+}
@@ -0,0 +1,130 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"todo_list_api/internal/auth"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestRequireAuth(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := auth.UserIDFromContext(r.Context())
+		assert.True(t, ok)
+		w.Write([]byte(strconv.FormatInt(userID, 10)))
+	})
+	handler := auth.RequireAuth(tokens, next)
+
+	t.Run("should return 401 if the authorization header is missing", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("should return 401 if the token is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("Authorization", "Bearer invalid")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "invalid_token", decodeError(t, rr).Code)
+	})
+
+	t.Run("should call the next handler with the user id in the context", func(t *testing.T) {
+		token, _, err := tokens.Issue(42)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "42", rr.Body.String())
+	})
+}
+
+func TestRequireStreamAuth(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := auth.UserIDFromContext(r.Context())
+		assert.True(t, ok)
+		w.Write([]byte(strconv.FormatInt(userID, 10)))
+	})
+	handler := auth.RequireStreamAuth(tokens, next)
+
+	t.Run("should accept a regular token in the authorization header", func(t *testing.T) {
+		token, _, err := tokens.Issue(42)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "42", rr.Body.String())
+	})
+
+	t.Run("should accept a stream token in the query", func(t *testing.T) {
+		token, _, err := tokens.IssueStreamToken(42)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/tasks/events?access_token="+token, nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "42", rr.Body.String())
+	})
+
+	t.Run("should return 401 for a regular token in the query", func(t *testing.T) {
+		token, _, err := tokens.Issue(42)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/tasks/events?access_token="+token, nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "invalid_token", decodeError(t, rr).Code)
+	})
+
+	t.Run("should return 401 for a stream token in the authorization header", func(t *testing.T) {
+		token, _, err := tokens.IssueStreamToken(42)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBKDF2SHA256(t *testing.T) {
+	t.Run("should match the RFC 7914 test vector", func(t *testing.T) {
+		key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+		expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+		assert.Equal(t, expected, hex.EncodeToString(key))
+	})
+}
+
+func TestHashPassword(t *testing.T) {
+	t.Run("should produce a salted hash that verifies the original password", func(t *testing.T) {
+		hash, err := HashPassword("correct horse")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "pbkdf2-sha256$"))
+		assert.True(t, CheckPassword(hash, "correct horse"))
+		assert.False(t, CheckPassword(hash, "wrong horse"))
+
+		other, err := HashPassword("correct horse")
+		assert.NoError(t, err)
+		assert.NotEqual(t, hash, other)
+	})
+
+	t.Run("should reject malformed hashes", func(t *testing.T) {
+		assert.False(t, CheckPassword("", "password"))
+		assert.False(t, CheckPassword("md5$1$abc$def", "password"))
+		assert.False(t, CheckPassword("pbkdf2-sha256$abc$c2FsdA$a2V5", "password"))
+	})
+
+	t.Run("should reject hashes with an empty or truncated key", func(t *testing.T) {
+		hash, err := HashPassword("correct horse")
+		assert.NoError(t, err)
+		prefix := hash[:strings.LastIndex(hash, "$")+1]
+
+		assert.False(t, CheckPassword(prefix, "correct horse"))
+		assert.False(t, CheckPassword(prefix+"a2V5", "correct horse"))
+	})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	s "todo_list_api/internal/collab/service"
+	"todo_list_api/internal/httputil"
+	taskHandler "todo_list_api/internal/task/handler"
+	"todo_list_api/internal/websocket"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+const (
+	maxMessageSize = 64 << 10
+	// pingInterval keeps idle connections alive through proxies; a client
+	// that sends nothing, not even pongs, for idleTimeout is disconnected.
+	pingInterval = 30 * time.Second
+	idleTimeout  = 2*pingInterval + 10*time.Second
+	writeTimeout = 10 * time.Second
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ProjectChannel upgrades to a WebSocket connected to the room of a project.
+// Messages are JSON-encoded models.CollabMessage values, one per text frame.
+// A client that cannot keep up is disconnected with close code 1013 and
+// should reconnect; one whose user lost access to the project is
+// disconnected with 1008.
+func (h *Handler) ProjectChannel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	projectID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Authorize(r.Context(), userID, projectID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		if errors.Is(err, websocket.ErrHandshake) {
+			w.Header().Set("Upgrade", "websocket")
+			utils.WriteError(w, utils.ErrWebSocketRequired)
+			return
+		}
+		log.Printf("could not upgrade to a websocket: %v", err)
+		return
+	}
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetIdleTimeout(idleTimeout)
+
+	client := h.service.Join(userID, projectID)
+	written := make(chan struct{})
+	go func() {
+		writeLoop(conn, client)
+		close(written)
+	}()
+
+	h.readLoop(r, conn, client)
+	h.service.Leave(client)
+	<-written
+}
+
+func (h *Handler) readLoop(r *http.Request, conn *websocket.Conn, client *s.Client) {
+	for {
+		opcode, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if opcode != websocket.TextMessage {
+			conn.Close(websocket.CloseUnsupportedData, "messages must be text")
+			return
+		}
+
+		var message models.CollabMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			h.service.SendError(client, 0, utils.ErrInvalidMessage)
+			continue
+		}
+
+		if message.Type == models.CollabSave {
+			patch, err := taskHandler.ParseMergePatch(message.Changes)
+			if err != nil {
+				h.service.SendError(client, message.TaskID, err)
+				continue
+			}
+			message.Patch = patch
+		}
+
+		h.service.Handle(r.Context(), client, &message)
+	}
+}
+
+// writeLoop sends the client's messages until the hub closes Send, and then
+// closes the connection.
+func writeLoop(conn *websocket.Conn, client *s.Client) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		var opcode int
+		var data []byte
+		select {
+		case message, ok := <-client.Send:
+			if !ok {
+				code := websocket.CloseNormalClosure
+				switch {
+				case client.Dropped():
+					code = websocket.CloseTryAgainLater
+				case client.Revoked():
+					code = websocket.ClosePolicyViolation
+				}
+				conn.Close(code, "")
+				return
+			}
+
+			var err error
+			opcode = websocket.TextMessage
+			data, err = json.Marshal(message)
+			if err != nil {
+				log.Printf("could not encode a collaboration message: %v", err)
+				continue
+			}
+		case <-ticker.C:
+			opcode = websocket.PingMessage
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := conn.WriteMessage(opcode, data); err != nil {
+			// Closing makes the read loop leave the room, which closes
+			// Send and ends this loop.
+			conn.Close(websocket.CloseGoingAway, "")
+		}
+	}
+}
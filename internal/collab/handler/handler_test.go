@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/collab/handler"
+	m "todo_list_api/internal/collab/mocks"
+	"todo_list_api/internal/collab/service"
+	"todo_list_api/internal/websocket"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func TestProjectChannel(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/{id}/live", func(w http.ResponseWriter, r *http.Request) {
+		handler.ProjectChannel(w, withUser(r))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/projects/3/live"
+
+	t.Run("should return 404 if the user cannot see the project", func(t *testing.T) {
+		mockService.On("Authorize", mock.Anything, userID, int64(3)).Return(utils.ErrProjectNotFound).Once()
+
+		req, _ := http.NewRequest("GET", "/projects/3/live", nil)
+		req.SetPathValue("id", "3")
+		rr := httptest.NewRecorder()
+
+		handler.ProjectChannel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertNotCalled(t, "Join", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 426 for plain http requests", func(t *testing.T) {
+		mockService.On("Authorize", mock.Anything, userID, int64(3)).Return(nil).Once()
+
+		req, _ := http.NewRequest("GET", "/projects/3/live", nil)
+		req.SetPathValue("id", "3")
+		rr := httptest.NewRecorder()
+
+		handler.ProjectChannel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusUpgradeRequired, rr.Code)
+		assert.Equal(t, "websocket_required", decodeError(t, rr).Code)
+		assert.Equal(t, "13", rr.Header().Get("Sec-WebSocket-Version"))
+	})
+
+	t.Run("must relay messages both ways", func(t *testing.T) {
+		client := &service.Client{UserID: userID, ProjectID: 3, Send: make(chan *models.CollabMessage, 1)}
+		handled := make(chan *models.CollabMessage, 1)
+		mockService.On("Authorize", mock.Anything, userID, int64(3)).Return(nil).Once()
+		mockService.On("Join", userID, int64(3)).Return(client).Once()
+		mockService.On("Handle", mock.Anything, client, mock.Anything).Run(func(args mock.Arguments) {
+			handled <- args.Get(2).(*models.CollabMessage)
+		}).Once()
+		left := make(chan struct{})
+		mockService.On("Leave", client).Run(func(mock.Arguments) {
+			close(client.Send)
+			close(left)
+		}).Once()
+
+		conn, err := websocket.Dial(context.Background(), url, nil)
+		assert.NoError(t, err)
+
+		client.Send <- &models.CollabMessage{Type: models.CollabWelcome, Users: []int64{userID}}
+		_, data, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type":"welcome","users":[7]}`, string(data))
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"save","task_id":5,"version":2,"changes":{"title":"Shipped"}}`)))
+		message := <-handled
+		assert.Equal(t, models.CollabSave, message.Type)
+		assert.Equal(t, int64(2), message.Version)
+		assert.Equal(t, "Shipped", *message.Patch.Title)
+
+		conn.Close(websocket.CloseNormalClosure, "")
+		<-left
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should answer invalid messages with an error", func(t *testing.T) {
+		client := &service.Client{UserID: userID, ProjectID: 3, Send: make(chan *models.CollabMessage, 1)}
+		failed := make(chan error, 2)
+		mockService.On("Authorize", mock.Anything, userID, int64(3)).Return(nil).Once()
+		mockService.On("Join", userID, int64(3)).Return(client).Once()
+		mockService.On("SendError", client, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			failed <- args.Error(2)
+		}).Twice()
+		mockService.On("Leave", client).Run(func(mock.Arguments) { close(client.Send) }).Once()
+
+		conn, err := websocket.Dial(context.Background(), url, nil)
+		assert.NoError(t, err)
+		defer conn.Close(websocket.CloseNormalClosure, "")
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":`)))
+		assert.ErrorIs(t, <-failed, utils.ErrInvalidMessage)
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"save","task_id":5,"changes":{"id":9}}`)))
+		assert.ErrorIs(t, <-failed, utils.ErrImmutableField)
+		mockService.AssertNotCalled(t, "Handle", mock.Anything, client, mock.Anything)
+	})
+
+	t.Run("must close the connection once the hub lets go of the client", func(t *testing.T) {
+		client := &service.Client{UserID: userID, ProjectID: 3, Send: make(chan *models.CollabMessage)}
+		mockService.On("Authorize", mock.Anything, userID, int64(3)).Return(nil).Once()
+		mockService.On("Join", userID, int64(3)).Return(client).Once()
+		mockService.On("Leave", client).Once()
+
+		conn, err := websocket.Dial(context.Background(), url, nil)
+		assert.NoError(t, err)
+
+		close(client.Send)
+		_, _, err = conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+	})
+}
@@ -0,0 +1,18 @@
+package collab
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProjectGetter struct {
+	mock.Mock
+}
+
+// GetProject implements ProjectGetter.
+func (m *MockProjectGetter) GetProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
@@ -0,0 +1,40 @@
+package collab
+
+import (
+	"context"
+	"todo_list_api/internal/collab/service"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// Authorize implements collab.Service.
+func (m *MockService) Authorize(ctx context.Context, userID, projectID int64) error {
+	args := m.Called(ctx, userID, projectID)
+	return args.Error(0)
+}
+
+// Handle implements collab.Service.
+func (m *MockService) Handle(ctx context.Context, client *service.Client, message *models.CollabMessage) {
+	m.Called(ctx, client, message)
+}
+
+// Join implements collab.Service.
+func (m *MockService) Join(userID, projectID int64) *service.Client {
+	args := m.Called(userID, projectID)
+	return args.Get(0).(*service.Client)
+}
+
+// Leave implements collab.Service.
+func (m *MockService) Leave(client *service.Client) {
+	m.Called(client)
+}
+
+// SendError implements collab.Service.
+func (m *MockService) SendError(client *service.Client, taskID int64, err error) {
+	m.Called(client, taskID, err)
+}
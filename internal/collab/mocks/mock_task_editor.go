@@ -0,0 +1,24 @@
+package collab
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskEditor struct {
+	mock.Mock
+}
+
+// GetTask implements TaskEditor.
+func (m *MockTaskEditor) GetTask(ctx context.Context, userID, id int64) (*models.Task, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// PatchTask implements TaskEditor.
+func (m *MockTaskEditor) PatchTask(ctx context.Context, userID, id, version int64, patch *models.TaskPatch) (*models.Task, error) {
+	args := m.Called(ctx, userID, id, version, patch)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
@@ -0,0 +1,17 @@
+package collab
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskRoles struct {
+	mock.Mock
+}
+
+// GetTaskRole implements TaskRoles.
+func (m *MockTaskRoles) GetTaskRole(ctx context.Context, userID, taskID int64) (string, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.String(0), args.Error(1)
+}
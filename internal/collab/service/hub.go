@@ -0,0 +1,556 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Service interface {
+	Authorize(ctx context.Context, userID, projectID int64) error
+	Handle(ctx context.Context, client *Client, message *models.CollabMessage)
+	Join(userID, projectID int64) *Client
+	Leave(client *Client)
+	SendError(client *Client, taskID int64, err error)
+}
+
+// TaskEditor reads and writes tasks on behalf of a user; the task service
+// satisfies it.
+type TaskEditor interface {
+	GetTask(ctx context.Context, userID, id int64) (*models.Task, error)
+	PatchTask(ctx context.Context, userID, id, version int64, patch *models.TaskPatch) (*models.Task, error)
+}
+
+// ProjectGetter loads a project for a user it is shared with; the project
+// service satisfies it.
+type ProjectGetter interface {
+	GetProject(ctx context.Context, userID, id int64) (*models.Project, error)
+}
+
+// TaskRoles tells who may take a task's lock: being in the room is enough to
+// watch, but typing into a task takes an editor.
+type TaskRoles interface {
+	GetTaskRole(ctx context.Context, userID, taskID int64) (string, error)
+}
+
+const (
+	// clientBuffer is how many messages a client may fall behind by before
+	// it is dropped.
+	clientBuffer = 32
+	// reauthInterval is how often Run checks that connected users still
+	// have access to their project.
+	reauthInterval = time.Minute
+)
+
+type leaveReason int
+
+const (
+	leftRoom leaveReason = iota
+	fellBehind
+	lostAccess
+)
+
+// Client is a connection to the room of a project. The hub closes Send when
+// the client leaves or is dropped.
+type Client struct {
+	UserID    int64
+	ProjectID int64
+	Send      chan *models.CollabMessage
+	reason    leaveReason
+}
+
+// Dropped reports whether Send was closed because the client fell behind.
+// It may only be called once Send is closed.
+func (c *Client) Dropped() bool {
+	return c.reason == fellBehind
+}
+
+// Revoked reports whether Send was closed because the user lost access to
+// the project. It may only be called once Send is closed.
+func (c *Client) Revoked() bool {
+	return c.reason == lostAccess
+}
+
+// taskLock is held until its client lets go or leaves, or until it has
+// neither locked nor edited the task for the hub's lock TTL.
+type taskLock struct {
+	client  *Client
+	expires time.Time
+}
+
+type projectRoom struct {
+	clients map[*Client]struct{}
+	// presence counts the connections of each user, who may have the
+	// board open more than once.
+	presence map[int64]int
+	// locks holds the connection editing each task.
+	locks map[int64]*taskLock
+}
+
+// Hub keeps the rooms of every project with a connected client. Rooms,
+// presence and locks belong to the goroutine running Run and are only
+// touched through do; task reads and writes happen on the caller's
+// goroutine, so a slow query never holds up other rooms.
+type Hub struct {
+	tasks    TaskEditor
+	projects ProjectGetter
+	roles    TaskRoles
+	lockTTL  time.Duration
+	now      func() time.Time
+	ops      chan func()
+	done     chan struct{}
+	rooms    map[int64]*projectRoom
+}
+
+func NewHub(tasks TaskEditor, projects ProjectGetter, roles TaskRoles, lockTTL time.Duration) *Hub {
+	return &Hub{
+		tasks:    tasks,
+		projects: projects,
+		roles:    roles,
+		lockTTL:  lockTTL,
+		now:      time.Now,
+		ops:      make(chan func()),
+		done:     make(chan struct{}),
+		rooms:    map[int64]*projectRoom{},
+	}
+}
+
+// Run applies operations to the rooms until ctx is done, and then drops
+// every client. Meanwhile it expires idle locks, and reauthorizes the
+// connected clients every reauthInterval.
+func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+	defer func() {
+		for _, room := range h.rooms {
+			for client := range room.clients {
+				h.remove(client, leftRoom)
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(reauthInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.Reauthorize(ctx)
+			}
+		}
+	}()
+
+	sweep := time.NewTicker(h.lockTTL / 2)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case op := <-h.ops:
+			op()
+		case <-sweep.C:
+			h.expireLocks()
+		}
+	}
+}
+
+// do runs op on the hub goroutine and waits for it. It reports false once
+// the hub has stopped.
+func (h *Hub) do(op func()) bool {
+	finished := make(chan struct{})
+	select {
+	case h.ops <- func() { op(); close(finished) }:
+		<-finished
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// Authorize lets in the project owner and its members. Access to a single
+// task of the project is not enough, since the room shows all of them.
+func (h *Hub) Authorize(ctx context.Context, userID, projectID int64) error {
+	_, err := h.projects.GetProject(ctx, userID, projectID)
+	return err
+}
+
+// heldLock names a lock outside the hub goroutine.
+type heldLock struct {
+	client *Client
+	taskID int64
+}
+
+// Reauthorize checks the connected clients against the current sharing of
+// their projects and tasks. Clients whose user lost access to the project
+// are disconnected, and locks whose holder can no longer edit the task are
+// released. Run calls it every reauthInterval.
+func (h *Hub) Reauthorize(ctx context.Context) {
+	var clients []*Client
+	var locks []heldLock
+	h.do(func() {
+		for _, room := range h.rooms {
+			for client := range room.clients {
+				clients = append(clients, client)
+			}
+			for taskID, lock := range room.locks {
+				locks = append(locks, heldLock{client: lock.client, taskID: taskID})
+			}
+		}
+	})
+
+	type access struct{ userID, projectID int64 }
+	revoked := map[access]bool{}
+	checked := map[access]bool{}
+	for _, client := range clients {
+		key := access{client.UserID, client.ProjectID}
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		err := h.Authorize(ctx, client.UserID, client.ProjectID)
+		switch {
+		case errors.Is(err, utils.ErrProjectNotFound), errors.Is(err, utils.ErrForbidden):
+			revoked[key] = true
+		case err != nil:
+			log.Printf("could not reauthorize user %d on project %d: %v", client.UserID, client.ProjectID, err)
+		}
+	}
+
+	var released []heldLock
+	for _, lock := range locks {
+		if revoked[access{lock.client.UserID, lock.client.ProjectID}] {
+			continue
+		}
+		err := h.requireEditor(ctx, lock.client, lock.taskID)
+		switch {
+		case errors.Is(err, utils.ErrTaskNotFound), errors.Is(err, utils.ErrForbidden):
+			released = append(released, lock)
+		case err != nil:
+			log.Printf("could not reauthorize the lock of user %d on task %d: %v", lock.client.UserID, lock.taskID, err)
+		}
+	}
+
+	h.do(func() {
+		for _, client := range clients {
+			if revoked[access{client.UserID, client.ProjectID}] {
+				h.remove(client, lostAccess)
+			}
+		}
+		for _, lock := range released {
+			h.release(lock.client, lock.taskID)
+		}
+	})
+}
+
+// Join adds a client to the room of a project. It is sent a welcome
+// describing the room, and the others are told the user arrived.
+func (h *Hub) Join(userID, projectID int64) *Client {
+	client := &Client{UserID: userID, ProjectID: projectID, Send: make(chan *models.CollabMessage, clientBuffer)}
+	joined := h.do(func() {
+		room := h.rooms[projectID]
+		if room == nil {
+			room = &projectRoom{clients: map[*Client]struct{}{}, presence: map[int64]int{}, locks: map[int64]*taskLock{}}
+			h.rooms[projectID] = room
+		}
+		room.clients[client] = struct{}{}
+		room.presence[userID]++
+
+		welcome := &models.CollabMessage{Type: models.CollabWelcome, Locks: map[int64]int64{}}
+		for user := range room.presence {
+			welcome.Users = append(welcome.Users, user)
+		}
+		slices.Sort(welcome.Users)
+		for taskID, lock := range room.locks {
+			welcome.Locks[taskID] = lock.client.UserID
+		}
+		h.send(client, welcome)
+
+		if room.presence[userID] == 1 {
+			h.broadcast(projectID, &models.CollabMessage{Type: models.CollabJoined, UserID: userID}, client)
+		}
+	})
+	if !joined {
+		close(client.Send)
+	}
+	return client
+}
+
+// Leave removes a client from its room, releasing its locks. Leaving twice,
+// or after being dropped, does nothing.
+func (h *Hub) Leave(client *Client) {
+	h.do(func() { h.remove(client, leftRoom) })
+}
+
+// remove must run on the hub goroutine.
+func (h *Hub) remove(client *Client, reason leaveReason) {
+	room := h.rooms[client.ProjectID]
+	if room == nil {
+		return
+	}
+	if _, ok := room.clients[client]; !ok {
+		return
+	}
+
+	delete(room.clients, client)
+	client.reason = reason
+	close(client.Send)
+
+	for taskID, lock := range room.locks {
+		if lock.client == client {
+			delete(room.locks, taskID)
+			h.broadcast(client.ProjectID, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: taskID, UserID: client.UserID}, nil)
+		}
+	}
+
+	room.presence[client.UserID]--
+	if room.presence[client.UserID] == 0 {
+		delete(room.presence, client.UserID)
+		h.broadcast(client.ProjectID, &models.CollabMessage{Type: models.CollabLeft, UserID: client.UserID}, nil)
+	}
+
+	if len(room.clients) == 0 {
+		delete(h.rooms, client.ProjectID)
+	}
+}
+
+// send must run on the hub goroutine. A client whose buffer is full is
+// dropped rather than holding up the room.
+func (h *Hub) send(client *Client, message *models.CollabMessage) {
+	select {
+	case client.Send <- message:
+	default:
+		h.remove(client, fellBehind)
+	}
+}
+
+// broadcast must run on the hub goroutine.
+func (h *Hub) broadcast(projectID int64, message *models.CollabMessage, except *Client) {
+	room := h.rooms[projectID]
+	if room == nil {
+		return
+	}
+	for client := range room.clients {
+		if client != except {
+			h.send(client, message)
+		}
+	}
+}
+
+// member returns the room of a client that has not left. It must run on the
+// hub goroutine.
+func (h *Hub) member(client *Client) *projectRoom {
+	room := h.rooms[client.ProjectID]
+	if room == nil {
+		return nil
+	}
+	if _, ok := room.clients[client]; !ok {
+		return nil
+	}
+	return room
+}
+
+// release gives up the client's lock on a task, if it holds it. It must run
+// on the hub goroutine.
+func (h *Hub) release(client *Client, taskID int64) {
+	room := h.member(client)
+	if room == nil {
+		return
+	}
+	if lock := room.locks[taskID]; lock == nil || lock.client != client {
+		return
+	}
+
+	delete(room.locks, taskID)
+	h.broadcast(client.ProjectID, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: taskID, UserID: client.UserID}, nil)
+}
+
+// expireLocks releases the locks that were not refreshed within the lock
+// TTL, so a client that went quiet does not keep others out. It must run on
+// the hub goroutine.
+func (h *Hub) expireLocks() {
+	now := h.now()
+	for _, room := range h.rooms {
+		for taskID, lock := range room.locks {
+			if now.After(lock.expires) {
+				h.release(lock.client, taskID)
+			}
+		}
+	}
+}
+
+// SendError tells a client that its message about a task failed.
+func (h *Hub) SendError(client *Client, taskID int64, err error) {
+	status, response := utils.DescribeError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("collaboration message from user %d failed: %v", client.UserID, err)
+	}
+
+	h.do(func() {
+		if h.member(client) != nil {
+			h.send(client, &models.CollabMessage{Type: models.CollabError, TaskID: taskID, Error: &response})
+		}
+	})
+}
+
+// Handle acts on a message from a client.
+func (h *Hub) Handle(ctx context.Context, client *Client, message *models.CollabMessage) {
+	var err error
+	switch message.Type {
+	case models.CollabLock:
+		err = h.lock(ctx, client, message.TaskID)
+	case models.CollabUnlock:
+		h.unlock(client, message.TaskID)
+	case models.CollabEdit:
+		err = h.edit(client, message)
+	case models.CollabSave:
+		err = h.save(ctx, client, message)
+	default:
+		err = utils.ErrInvalidMessage
+	}
+
+	if err != nil {
+		h.SendError(client, message.TaskID, err)
+	}
+}
+
+// checkTask makes sure the user can see the task and that it is part of the
+// client's project, so a room cannot be used to reach other tasks.
+func (h *Hub) checkTask(ctx context.Context, client *Client, taskID int64) error {
+	task, err := h.tasks.GetTask(ctx, client.UserID, taskID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID == nil || *task.ProjectID != client.ProjectID {
+		return utils.ErrTaskNotFound
+	}
+	return nil
+}
+
+func (h *Hub) requireEditor(ctx context.Context, client *Client, taskID int64) error {
+	role, err := h.roles.GetTaskRole(ctx, client.UserID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.HasRole(role, models.RoleEditor) {
+		return utils.ErrForbidden
+	}
+	return nil
+}
+
+// lock gives an editor the task to edit, unless another connection holds it.
+// The whole room, the client included, is told who holds it. Locking a task
+// the client already holds refreshes the lock.
+func (h *Hub) lock(ctx context.Context, client *Client, taskID int64) error {
+	if err := h.checkTask(ctx, client, taskID); err != nil {
+		return err
+	}
+	if err := h.requireEditor(ctx, client, taskID); err != nil {
+		return err
+	}
+
+	var err error
+	h.do(func() {
+		room := h.member(client)
+		if room == nil {
+			return
+		}
+
+		expires := h.now().Add(h.lockTTL)
+		switch lock := room.locks[taskID]; {
+		case lock == nil:
+			room.locks[taskID] = &taskLock{client: client, expires: expires}
+			h.broadcast(client.ProjectID, &models.CollabMessage{Type: models.CollabLocked, TaskID: taskID, UserID: client.UserID}, nil)
+		case lock.client == client:
+			lock.expires = expires
+			h.send(client, &models.CollabMessage{Type: models.CollabLocked, TaskID: taskID, UserID: client.UserID})
+		default:
+			err = utils.ErrTaskLocked
+		}
+	})
+	return err
+}
+
+func (h *Hub) unlock(client *Client, taskID int64) {
+	h.do(func() { h.release(client, taskID) })
+}
+
+// edit relays a field being typed to the rest of the room and refreshes the
+// lock. Only the holder of the task's lock may do so.
+func (h *Hub) edit(client *Client, message *models.CollabMessage) error {
+	if message.Field == "" {
+		return utils.ErrInvalidMessage
+	}
+
+	var err error
+	h.do(func() {
+		room := h.member(client)
+		if room == nil {
+			return
+		}
+		lock := room.locks[message.TaskID]
+		if lock == nil || lock.client != client {
+			err = utils.ErrLockRequired
+			return
+		}
+		lock.expires = h.now().Add(h.lockTTL)
+
+		h.broadcast(client.ProjectID, &models.CollabMessage{
+			Type:   models.CollabEdit,
+			TaskID: message.TaskID,
+			UserID: client.UserID,
+			Field:  message.Field,
+			Value:  message.Value,
+		}, client)
+	})
+	return err
+}
+
+// save writes the changes through the task service and shares the updated
+// task with the room. Saving does not need the lock, but is refused while
+// another connection holds it. A save that moves the task to another project
+// releases the client's lock on it.
+func (h *Hub) save(ctx context.Context, client *Client, message *models.CollabMessage) error {
+	if message.Patch == nil {
+		return utils.ErrInvalidMessage
+	}
+	if err := h.checkTask(ctx, client, message.TaskID); err != nil {
+		return err
+	}
+
+	var err error
+	h.do(func() {
+		room := h.member(client)
+		if room == nil {
+			return
+		}
+		if lock := room.locks[message.TaskID]; lock != nil && lock.client != client {
+			err = utils.ErrTaskLocked
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	task, err := h.tasks.PatchTask(ctx, client.UserID, message.TaskID, message.Version, message.Patch)
+	if err != nil {
+		return err
+	}
+
+	h.do(func() {
+		h.broadcast(client.ProjectID, &models.CollabMessage{Type: models.CollabTaskUpdated, TaskID: task.ID, UserID: client.UserID, Task: task}, nil)
+		if task.ProjectID == nil || *task.ProjectID != client.ProjectID {
+			h.release(client, task.ID)
+		}
+	})
+	return nil
+}
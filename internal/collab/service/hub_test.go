@@ -0,0 +1,336 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	m "todo_list_api/internal/collab/mocks"
+	"todo_list_api/internal/collab/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const projectID = int64(3)
+
+type hubMocks struct {
+	tasks    *m.MockTaskEditor
+	projects *m.MockProjectGetter
+	roles    *m.MockTaskRoles
+}
+
+func startHub(t *testing.T, lockTTL time.Duration) (*service.Hub, hubMocks) {
+	mocks := hubMocks{tasks: new(m.MockTaskEditor), projects: new(m.MockProjectGetter), roles: new(m.MockTaskRoles)}
+	hub := service.NewHub(mocks.tasks, mocks.projects, mocks.roles, lockTTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+
+	return hub, mocks
+}
+
+// receive returns the next message the client was sent, failing the test
+// when there is none.
+func receive(t *testing.T, client *service.Client) *models.CollabMessage {
+	t.Helper()
+	select {
+	case message, ok := <-client.Send:
+		assert.True(t, ok, "the client was dropped")
+		return message
+	default:
+		t.Fatal("no message was sent")
+		return nil
+	}
+}
+
+func projectTask(id int64) *models.Task {
+	project := projectID
+	return &models.Task{ID: id, ProjectID: &project, Title: "Ship"}
+}
+
+func TestAuthorize(t *testing.T) {
+	hub, mocks := startHub(t, time.Minute)
+
+	t.Run("must let in the users the project is shared with", func(t *testing.T) {
+		mocks.projects.On("GetProject", mock.Anything, int64(7), projectID).Return(&models.Project{ID: projectID, OwnerID: 7}, nil).Once()
+
+		assert.NoError(t, hub.Authorize(context.Background(), 7, projectID))
+		mocks.projects.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrProjectNotFound to everyone else", func(t *testing.T) {
+		mocks.projects.On("GetProject", mock.Anything, int64(11), projectID).Return((*models.Project)(nil), utils.ErrProjectNotFound).Once()
+
+		assert.ErrorIs(t, hub.Authorize(context.Background(), 11, projectID), utils.ErrProjectNotFound)
+	})
+}
+
+func TestPresence(t *testing.T) {
+	hub, _ := startHub(t, time.Minute)
+
+	t.Run("must welcome clients and tell the room who comes and goes", func(t *testing.T) {
+		first := hub.Join(7, projectID)
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabWelcome, Users: []int64{7}, Locks: map[int64]int64{}}, receive(t, first))
+
+		second := hub.Join(9, projectID)
+		assert.Equal(t, []int64{7, 9}, receive(t, second).Users)
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabJoined, UserID: 9}, receive(t, first))
+
+		// A second tab of the same user is not news to the room.
+		tab := hub.Join(9, projectID)
+		receive(t, tab)
+		assert.Empty(t, first.Send)
+
+		hub.Leave(tab)
+		assert.Empty(t, first.Send)
+
+		hub.Leave(second)
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabLeft, UserID: 9}, receive(t, first))
+
+		hub.Leave(second)
+		hub.Leave(first)
+	})
+
+	t.Run("should keep rooms apart", func(t *testing.T) {
+		board := hub.Join(7, projectID)
+		other := hub.Join(9, projectID+1)
+		receive(t, board)
+		receive(t, other)
+
+		assert.Empty(t, board.Send)
+		hub.Leave(board)
+		hub.Leave(other)
+	})
+}
+
+func TestLocks(t *testing.T) {
+	hub, mocks := startHub(t, time.Minute)
+	mocks.tasks.On("GetTask", mock.Anything, mock.Anything, int64(5)).Return(projectTask(5), nil)
+	mocks.roles.On("GetTaskRole", mock.Anything, int64(11), int64(5)).Return(models.RoleViewer, nil)
+	mocks.roles.On("GetTaskRole", mock.Anything, mock.Anything, mock.Anything).Return(models.RoleEditor, nil)
+
+	editor, watcher := hub.Join(7, projectID), hub.Join(9, projectID)
+	receive(t, editor)
+	receive(t, editor)
+	receive(t, watcher)
+
+	t.Run("must tell the room who is editing a task", func(t *testing.T) {
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+
+		locked := &models.CollabMessage{Type: models.CollabLocked, TaskID: 5, UserID: 7}
+		assert.Equal(t, locked, receive(t, editor))
+		assert.Equal(t, locked, receive(t, watcher))
+	})
+
+	t.Run("should refuse a lock held by someone else", func(t *testing.T) {
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+
+		message := receive(t, watcher)
+		assert.Equal(t, models.CollabError, message.Type)
+		assert.Equal(t, "task_locked", message.Error.Code)
+		assert.Empty(t, editor.Send)
+	})
+
+	t.Run("must relay edits from the holder to the others", func(t *testing.T) {
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabEdit, TaskID: 5, Field: "title", Value: json.RawMessage(`"Shi"`)})
+
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabEdit, TaskID: 5, UserID: 7, Field: "title", Value: json.RawMessage(`"Shi"`)}, receive(t, watcher))
+		assert.Empty(t, editor.Send)
+	})
+
+	t.Run("should refuse edits without the lock", func(t *testing.T) {
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabEdit, TaskID: 5, Field: "title", Value: json.RawMessage(`"x"`)})
+
+		assert.Equal(t, "lock_required", receive(t, watcher).Error.Code)
+		assert.Empty(t, editor.Send)
+	})
+
+	t.Run("must release the locks of a client that leaves", func(t *testing.T) {
+		hub.Leave(editor)
+
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: 5, UserID: 7}, receive(t, watcher))
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabLeft, UserID: 7}, receive(t, watcher))
+
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+		assert.Equal(t, models.CollabLocked, receive(t, watcher).Type)
+	})
+
+	t.Run("should refuse the lock to viewers", func(t *testing.T) {
+		viewer := hub.Join(11, projectID)
+		receive(t, viewer)
+		receive(t, watcher)
+
+		hub.Handle(context.Background(), viewer, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+
+		assert.Equal(t, "forbidden", receive(t, viewer).Error.Code)
+		hub.Leave(viewer)
+		receive(t, watcher)
+	})
+
+	t.Run("should not reach tasks outside the project", func(t *testing.T) {
+		mocks.tasks.On("GetTask", mock.Anything, int64(9), int64(6)).Return(&models.Task{ID: 6}, nil).Once()
+
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabLock, TaskID: 6})
+		assert.Equal(t, "task_not_found", receive(t, watcher).Error.Code)
+	})
+}
+
+func TestSave(t *testing.T) {
+	hub, mocks := startHub(t, time.Minute)
+	mockTasks := mocks.tasks
+	mockTasks.On("GetTask", mock.Anything, mock.Anything, int64(5)).Return(projectTask(5), nil)
+	mocks.roles.On("GetTaskRole", mock.Anything, mock.Anything, mock.Anything).Return(models.RoleEditor, nil)
+
+	editor, watcher := hub.Join(7, projectID), hub.Join(9, projectID)
+	receive(t, editor)
+	receive(t, editor)
+	receive(t, watcher)
+
+	title := "Shipped"
+	patch := &models.TaskPatch{Title: &title}
+
+	t.Run("must write through the task service and share the result", func(t *testing.T) {
+		updated := projectTask(5)
+		updated.Title, updated.Version = title, 4
+		mockTasks.On("PatchTask", mock.Anything, int64(7), int64(5), int64(3), patch).Return(updated, nil).Once()
+
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabSave, TaskID: 5, Version: 3, Patch: patch})
+
+		message := receive(t, watcher)
+		assert.Equal(t, models.CollabTaskUpdated, message.Type)
+		assert.Equal(t, int64(7), message.UserID)
+		assert.Equal(t, updated, message.Task)
+		assert.Equal(t, updated, receive(t, editor).Task)
+		mockTasks.AssertExpectations(t)
+	})
+
+	t.Run("should send the error of a failed write to its author only", func(t *testing.T) {
+		mockTasks.On("PatchTask", mock.Anything, int64(7), int64(5), int64(3), patch).Return((*models.Task)(nil), utils.ErrPreconditionFailed).Once()
+
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabSave, TaskID: 5, Version: 3, Patch: patch})
+
+		assert.Equal(t, "precondition_failed", receive(t, editor).Error.Code)
+		assert.Empty(t, watcher.Send)
+	})
+
+	t.Run("should refuse to save over someone else's lock", func(t *testing.T) {
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+		receive(t, watcher)
+		receive(t, editor)
+
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabSave, TaskID: 5, Patch: patch})
+
+		assert.Equal(t, "task_locked", receive(t, editor).Error.Code)
+		mockTasks.AssertNumberOfCalls(t, "PatchTask", 2)
+	})
+
+	t.Run("must release the lock of a task saved out of the project", func(t *testing.T) {
+		moved := &models.Task{ID: 5, Title: "Ship", Version: 5}
+		mockTasks.On("PatchTask", mock.Anything, int64(9), int64(5), int64(4), patch).Return(moved, nil).Once()
+
+		hub.Handle(context.Background(), watcher, &models.CollabMessage{Type: models.CollabSave, TaskID: 5, Version: 4, Patch: patch})
+
+		assert.Equal(t, moved, receive(t, editor).Task)
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: 5, UserID: 9}, receive(t, editor))
+	})
+}
+
+func TestLockExpiry(t *testing.T) {
+	hub, mocks := startHub(t, 20*time.Millisecond)
+	mocks.tasks.On("GetTask", mock.Anything, mock.Anything, int64(5)).Return(projectTask(5), nil)
+	mocks.roles.On("GetTaskRole", mock.Anything, mock.Anything, mock.Anything).Return(models.RoleEditor, nil)
+
+	t.Run("should release a lock that is not refreshed", func(t *testing.T) {
+		editor, watcher := hub.Join(7, projectID), hub.Join(9, projectID)
+		receive(t, editor)
+		receive(t, editor)
+		receive(t, watcher)
+
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+		receive(t, editor)
+		receive(t, watcher)
+
+		assert.Eventually(t, func() bool { return len(watcher.Send) > 0 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: 5, UserID: 7}, receive(t, watcher))
+
+		hub.Leave(editor)
+		hub.Leave(watcher)
+	})
+}
+
+func TestReauthorize(t *testing.T) {
+	hub, mocks := startHub(t, time.Minute)
+	mocks.tasks.On("GetTask", mock.Anything, mock.Anything, int64(5)).Return(projectTask(5), nil)
+
+	editor, watcher := hub.Join(7, projectID), hub.Join(9, projectID)
+	receive(t, editor)
+	receive(t, editor)
+	receive(t, watcher)
+
+	t.Run("should release the locks of users who can no longer edit", func(t *testing.T) {
+		mocks.roles.On("GetTaskRole", mock.Anything, int64(7), int64(5)).Return(models.RoleEditor, nil).Once()
+		hub.Handle(context.Background(), editor, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+		receive(t, editor)
+		receive(t, watcher)
+
+		mocks.projects.On("GetProject", mock.Anything, mock.Anything, projectID).Return(&models.Project{ID: projectID}, nil).Twice()
+		mocks.roles.On("GetTaskRole", mock.Anything, int64(7), int64(5)).Return(models.RoleViewer, nil).Once()
+
+		hub.Reauthorize(context.Background())
+
+		unlocked := &models.CollabMessage{Type: models.CollabUnlocked, TaskID: 5, UserID: 7}
+		assert.Equal(t, unlocked, receive(t, editor))
+		assert.Equal(t, unlocked, receive(t, watcher))
+		assert.Empty(t, watcher.Send)
+	})
+
+	t.Run("must disconnect users who lost access to the project", func(t *testing.T) {
+		mocks.projects.On("GetProject", mock.Anything, int64(7), projectID).Return(&models.Project{ID: projectID}, nil).Once()
+		mocks.projects.On("GetProject", mock.Anything, int64(9), projectID).Return((*models.Project)(nil), utils.ErrProjectNotFound).Once()
+
+		hub.Reauthorize(context.Background())
+
+		for range watcher.Send {
+		}
+		assert.True(t, watcher.Revoked())
+		assert.Equal(t, &models.CollabMessage{Type: models.CollabLeft, UserID: 9}, receive(t, editor))
+		mocks.projects.AssertExpectations(t)
+
+		hub.Leave(editor)
+	})
+}
+
+func TestBackpressure(t *testing.T) {
+	hub, mocks := startHub(t, time.Minute)
+	mocks.tasks.On("GetTask", mock.Anything, mock.Anything, int64(5)).Return(projectTask(5), nil)
+	mocks.tasks.On("GetTask", mock.Anything, mock.Anything, int64(8)).Return(projectTask(8), nil)
+	mocks.roles.On("GetTaskRole", mock.Anything, mock.Anything, mock.Anything).Return(models.RoleEditor, nil)
+
+	t.Run("should drop a client that falls behind and release its locks", func(t *testing.T) {
+		slow, typist := hub.Join(7, projectID), hub.Join(9, projectID)
+		hub.Handle(context.Background(), slow, &models.CollabMessage{Type: models.CollabLock, TaskID: 5})
+		hub.Handle(context.Background(), typist, &models.CollabMessage{Type: models.CollabLock, TaskID: 8})
+
+		for range cap(slow.Send) {
+			hub.Handle(context.Background(), typist, &models.CollabMessage{Type: models.CollabEdit, TaskID: 8, Field: "title", Value: json.RawMessage(`"x"`)})
+		}
+
+		for range slow.Send {
+		}
+		assert.True(t, slow.Dropped())
+
+		var received []*models.CollabMessage
+		for len(typist.Send) > 0 {
+			received = append(received, <-typist.Send)
+		}
+		assert.Contains(t, received, &models.CollabMessage{Type: models.CollabUnlocked, TaskID: 5, UserID: 7})
+		assert.Contains(t, received, &models.CollabMessage{Type: models.CollabLeft, UserID: 7})
+
+		hub.Leave(slow)
+		hub.Leave(typist)
+	})
+}
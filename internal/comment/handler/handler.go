@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	s "todo_list_api/internal/comment/service"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var comment models.Comment
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	comment.ID = 0
+	comment.TaskID = taskID
+	comment.AuthorID = userID
+
+	if err := h.service.CreateComment(r.Context(), &comment); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, &comment)
+}
+
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var limit int
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			utils.WriteError(w, utils.ErrInvalidLimit)
+			return
+		}
+	}
+
+	page, err := h.service.ListComments(r.Context(), userID, taskID, query.Get("cursor"), limit)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "commentID")
+	if !ok {
+		return
+	}
+
+	var comment models.Comment
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	comment.ID = ID
+	comment.TaskID = taskID
+
+	if err := h.service.UpdateComment(r.Context(), userID, &comment); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, &comment)
+}
+
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "commentID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteComment(r.Context(), userID, taskID, ID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
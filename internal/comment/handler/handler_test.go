@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/comment/handler"
+	m "todo_list_api/internal/comment/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func TestCreateComment(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 401 if the request is not authenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/tasks/1/comments", bytes.NewBufferString(`{"body":"Hi"}`))
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.CreateComment(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should return 201 and attribute the comment to the caller", func(t *testing.T) {
+		mockService.On("CreateComment", mock.Anything, &models.Comment{TaskID: 1, AuthorID: userID, Body: "Hi"}).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", "/tasks/1/comments", bytes.NewBufferString(`{"body":"Hi","author_id":99}`))
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.CreateComment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestListComments(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the limit is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/1/comments?limit=-1", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.ListComments(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should pass the cursor and limit down", func(t *testing.T) {
+		page := &models.CommentPage{Comments: []*models.Comment{{ID: 3}}, NextCursor: "Mw"}
+		mockService.On("ListComments", mock.Anything, userID, int64(1), "Mg", 1).Return(page, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/1/comments?cursor=Mg&limit=1", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.ListComments(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got models.CommentPage
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Equal(t, "Mw", got.NextCursor)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 403 when removing someone else's comment", func(t *testing.T) {
+		mockService.On("DeleteComment", mock.Anything, userID, int64(1), int64(3)).Return(utils.ErrForbidden).Once()
+
+		req, _ := http.NewRequest("DELETE", "/tasks/1/comments/3", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("commentID", "3")
+		rr := httptest.NewRecorder()
+
+		handler.DeleteComment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, "forbidden", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 if the comment id is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/tasks/1/comments/abc", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("commentID", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.DeleteComment(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
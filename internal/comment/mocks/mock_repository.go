@@ -0,0 +1,42 @@
+package comment
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// CreateComment implements Repository.
+func (m *MockRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+// DeleteComment implements Repository.
+func (m *MockRepository) DeleteComment(ctx context.Context, taskID, id int64) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+// GetComment implements Repository.
+func (m *MockRepository) GetComment(ctx context.Context, taskID, id int64) (*models.Comment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// ListComments implements Repository.
+func (m *MockRepository) ListComments(ctx context.Context, taskID int64, cursor string, limit int) (*models.CommentPage, error) {
+	args := m.Called(ctx, taskID, cursor, limit)
+	return args.Get(0).(*models.CommentPage), args.Error(1)
+}
+
+// UpdateComment implements Repository.
+func (m *MockRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
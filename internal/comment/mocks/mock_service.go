@@ -0,0 +1,36 @@
+package comment
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// CreateComment implements comment.Service.
+func (m *MockService) CreateComment(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+// DeleteComment implements comment.Service.
+func (m *MockService) DeleteComment(ctx context.Context, userID, taskID, id int64) error {
+	args := m.Called(ctx, userID, taskID, id)
+	return args.Error(0)
+}
+
+// ListComments implements comment.Service.
+func (m *MockService) ListComments(ctx context.Context, userID, taskID int64, cursor string, limit int) (*models.CommentPage, error) {
+	args := m.Called(ctx, userID, taskID, cursor, limit)
+	return args.Get(0).(*models.CommentPage), args.Error(1)
+}
+
+// UpdateComment implements comment.Service.
+func (m *MockService) UpdateComment(ctx context.Context, userID int64, comment *models.Comment) error {
+	args := m.Called(ctx, userID, comment)
+	return args.Error(0)
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Repository interface {
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetComment(ctx context.Context, taskID, id int64) (*models.Comment, error)
+	ListComments(ctx context.Context, taskID int64, cursor string, limit int) (*models.CommentPage, error)
+	UpdateComment(ctx context.Context, comment *models.Comment) error
+	DeleteComment(ctx context.Context, taskID, id int64) error
+}
+
+const commentColumns = "c.id, c.task_id, c.author_id, u.email, c.body, c.created_at, c.updated_at"
+
+type CommentRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewCommentRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &CommentRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanComment(row scanner, comment *models.Comment) error {
+	return row.Scan(
+		&comment.ID,
+		&comment.TaskID,
+		&comment.AuthorID,
+		&comment.AuthorEmail,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+}
+
+// CreateComment adds a comment to a live task and bumps the task's version,
+// as the comment count is part of the task representation.
+func (r *CommentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	return r.changeComments(ctx, comment.TaskID, func(tx *sql.Tx) (int64, error) {
+		const query = "INSERT INTO comments (task_id, author_id, body, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, (SELECT email FROM users WHERE users.id = comments.author_id)"
+		comment.CreatedAt = time.Now()
+		comment.UpdatedAt = comment.CreatedAt
+		if err := tx.QueryRowContext(
+			ctx,
+			query,
+			comment.TaskID,
+			comment.AuthorID,
+			comment.Body,
+			comment.CreatedAt,
+			comment.UpdatedAt,
+		).Scan(&comment.ID, &comment.AuthorEmail); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
+}
+
+func (r *CommentRepository) GetComment(ctx context.Context, taskID, id int64) (*models.Comment, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + commentColumns + " FROM comments c JOIN users u ON u.id = c.author_id WHERE c.id = $1 AND c.task_id = $2"
+	comment := &models.Comment{}
+	if err := scanComment(r.db.QueryRowContext(ctx, query, id, taskID), comment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListComments pages through the thread of a task, oldest first. The cursor
+// is the opaque id of the last comment of the previous page.
+func (r *CommentRepository) ListComments(ctx context.Context, taskID int64, cursor string, limit int) (*models.CommentPage, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var after int64
+	if cursor != "" {
+		var err error
+		if after, err = utils.DecodeIDCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	const query = "SELECT " + commentColumns + " FROM comments c JOIN users u ON u.id = c.author_id WHERE c.task_id = $1 AND c.id > $2 ORDER BY c.id ASC LIMIT $3"
+	rows, err := r.db.QueryContext(ctx, query, taskID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		var comment models.Comment
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.CommentPage{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		page.NextCursor = utils.EncodeIDCursor(page.Comments[len(page.Comments)-1].ID)
+	}
+
+	return page, nil
+}
+
+// UpdateComment replaces the body of a comment. Editing does not change the
+// comment count, so the task is left untouched.
+func (r *CommentRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "UPDATE comments SET body = $3, updated_at = $4 FROM users u WHERE comments.id = $1 AND comments.task_id = $2 AND u.id = comments.author_id RETURNING comments.author_id, u.email, comments.created_at"
+	comment.UpdatedAt = time.Now()
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		comment.ID,
+		comment.TaskID,
+		comment.Body,
+		comment.UpdatedAt,
+	).Scan(&comment.AuthorID, &comment.AuthorEmail, &comment.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.ErrCommentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteComment removes a comment from a live task and bumps the task's
+// version.
+func (r *CommentRepository) DeleteComment(ctx context.Context, taskID, id int64) error {
+	return r.changeComments(ctx, taskID, func(tx *sql.Tx) (int64, error) {
+		const query = "DELETE FROM comments WHERE id = $1 AND task_id = $2"
+		result, err := tx.ExecContext(ctx, query, id, taskID)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// changeComments runs change against a live task and bumps the task's
+// version when change reports that a comment was added or removed. Only the
+// version moves: the task itself was not edited.
+func (r *CommentRepository) changeComments(ctx context.Context, taskID int64, change func(tx *sql.Tx) (int64, error)) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const lock = "SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	var id int64
+	if err := tx.QueryRowContext(ctx, lock, taskID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.ErrTaskNotFound
+		}
+		return err
+	}
+
+	affected, err := change(tx)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrCommentNotFound
+	}
+
+	const bump = "UPDATE tasks SET version = version + 1 WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, bump, taskID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
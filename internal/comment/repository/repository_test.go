@@ -0,0 +1,144 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/comment/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "task_id", "author_id", "email", "body", "created_at", "updated_at"}
+
+const lock = "SELECT id FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE"
+
+func TestCreateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db, time.Second)
+
+	t.Run("must insert the comment and bump the task version", func(t *testing.T) {
+		comment := &models.Comment{TaskID: 1, AuthorID: 7, Body: "Looks **good**"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery("INSERT INTO comments \\(task_id, author_id, body, created_at, updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING id, \\(SELECT email FROM users WHERE users.id = comments.author_id\\)").
+			WithArgs(int64(1), int64(7), "Looks **good**", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(3, "ana@example.com"))
+		mock.ExpectExec("UPDATE tasks SET version = version \\+ 1 WHERE id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.CreateComment(context.Background(), comment))
+		assert.Equal(t, int64(3), comment.ID)
+		assert.Equal(t, "ana@example.com", comment.AuthorEmail)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task is gone", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.CreateComment(context.Background(), &models.Comment{TaskID: 9, AuthorID: 7, Body: "Hi"})
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db, time.Second)
+	const query = "SELECT c.id, c.task_id, c.author_id, u.email, c.body, c.created_at, c.updated_at FROM comments c JOIN users u ON u.id = c.author_id WHERE c.task_id = \\$1 AND c.id > \\$2 ORDER BY c.id ASC LIMIT \\$3"
+
+	t.Run("must page oldest first and continue after the cursor", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(0), 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 1, 7, "ana@example.com", "First", now, now).
+				AddRow(2, 1, 8, "bo@example.com", "Second", now, now).
+				AddRow(4, 1, 7, "ana@example.com", "Third", now, now))
+
+		page, err := repo.ListComments(context.Background(), 1, "", 2)
+		assert.NoError(t, err)
+		assert.Len(t, page.Comments, 2)
+		assert.Equal(t, "bo@example.com", page.Comments[1].AuthorEmail)
+		assert.NotEmpty(t, page.NextCursor)
+
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(2), 3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 1, 7, "ana@example.com", "Third", now, now))
+
+		page, err = repo.ListComments(context.Background(), 1, page.NextCursor, 2)
+		assert.NoError(t, err)
+		assert.Len(t, page.Comments, 1)
+		assert.Empty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should reject a malformed cursor", func(t *testing.T) {
+		_, err := repo.ListComments(context.Background(), 1, "not a cursor", 2)
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db, time.Second)
+
+	t.Run("should return ErrCommentNotFound if the comment is not on the task", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE comments SET body = \\$3, updated_at = \\$4 FROM users u WHERE comments.id = \\$1 AND comments.task_id = \\$2").
+			WithArgs(int64(3), int64(1), "Edited", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"author_id", "email", "created_at"}))
+
+		err := repo.UpdateComment(context.Background(), &models.Comment{ID: 3, TaskID: 1, Body: "Edited"})
+		assert.ErrorIs(t, err, utils.ErrCommentNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db, time.Second)
+
+	t.Run("should not bump the task if nothing was deleted", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM comments WHERE id = \\$1 AND task_id = \\$2").
+			WithArgs(int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.DeleteComment(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, utils.ErrCommentNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the delete fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM comments").WillReturnError(errors.New("delete failed"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.DeleteComment(context.Background(), 1, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
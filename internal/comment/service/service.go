@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	r "todo_list_api/internal/comment/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+	"unicode/utf8"
+)
+
+type Service interface {
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	ListComments(ctx context.Context, userID, taskID int64, cursor string, limit int) (*models.CommentPage, error)
+	UpdateComment(ctx context.Context, userID int64, comment *models.Comment) error
+	DeleteComment(ctx context.Context, userID, taskID, id int64) error
+}
+
+// TaskRoles gates a thread on the commenter's access to its task: viewers
+// read it, editors post to it, and owners moderate it.
+type TaskRoles interface {
+	GetTaskRole(ctx context.Context, userID, taskID int64) (string, error)
+}
+
+type CommentService struct {
+	repo  r.Repository
+	roles TaskRoles
+}
+
+func NewCommentService(repo r.Repository, roles TaskRoles) Service {
+	return &CommentService{repo: repo, roles: roles}
+}
+
+// CreateComment posts a comment as its author, who must be able to edit the
+// task.
+func (s *CommentService) CreateComment(ctx context.Context, comment *models.Comment) error {
+	if comment.TaskID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := normalizeBody(comment); err != nil {
+		return err
+	}
+
+	if _, err := s.requireRole(ctx, comment.AuthorID, comment.TaskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	return s.repo.CreateComment(ctx, comment)
+}
+
+func (s *CommentService) ListComments(ctx context.Context, userID, taskID int64, cursor string, limit int) (*models.CommentPage, error) {
+	if taskID < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if limit < 0 || limit > models.MaxPageSize {
+		return nil, utils.ErrInvalidLimit
+	}
+	if limit == 0 {
+		limit = models.DefaultPageSize
+	}
+
+	if _, err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListComments(ctx, taskID, cursor, limit)
+}
+
+// UpdateComment edits the body of a comment. Only its author may do so, and
+// only while they can still edit the task.
+func (s *CommentService) UpdateComment(ctx context.Context, userID int64, comment *models.Comment) error {
+	if comment.TaskID < 0 || comment.ID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := normalizeBody(comment); err != nil {
+		return err
+	}
+
+	if _, err := s.requireRole(ctx, userID, comment.TaskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	stored, err := s.storedComment(ctx, comment.TaskID, comment.ID)
+	if err != nil {
+		return err
+	}
+
+	if stored.AuthorID != userID {
+		return utils.ErrForbidden
+	}
+
+	return s.repo.UpdateComment(ctx, comment)
+}
+
+// DeleteComment removes a comment. Authors may remove their own comments and
+// the task owner may remove any of them.
+func (s *CommentService) DeleteComment(ctx context.Context, userID, taskID, id int64) error {
+	if taskID < 0 || id < 0 {
+		return utils.ErrInvalidId
+	}
+
+	role, err := s.requireRole(ctx, userID, taskID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if role != models.RoleOwner {
+		stored, err := s.storedComment(ctx, taskID, id)
+		if err != nil {
+			return err
+		}
+
+		if stored.AuthorID != userID {
+			return utils.ErrForbidden
+		}
+	}
+
+	return s.repo.DeleteComment(ctx, taskID, id)
+}
+
+func (s *CommentService) storedComment(ctx context.Context, taskID, id int64) (*models.Comment, error) {
+	comment, err := s.repo.GetComment(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment == nil {
+		return nil, utils.ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+func (s *CommentService) requireRole(ctx context.Context, userID, taskID int64, required string) (string, error) {
+	role, err := s.roles.GetTaskRole(ctx, userID, taskID)
+	if err != nil {
+		return "", err
+	}
+
+	if !utils.HasRole(role, required) {
+		return "", utils.ErrForbidden
+	}
+
+	return role, nil
+}
+
+// normalizeBody trims the Markdown body of a comment and checks its length.
+func normalizeBody(comment *models.Comment) error {
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" {
+		return utils.ErrEmptyComment
+	}
+
+	if utf8.RuneCountInString(comment.Body) > models.MaxCommentLength {
+		return utils.ErrCommentTooLong
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	m "todo_list_api/internal/comment/mocks"
+	"todo_list_api/internal/comment/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateComment(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewCommentService(mockRepo, mockRoles)
+
+	t.Run("should reject a blank or oversized body", func(t *testing.T) {
+		err := svc.CreateComment(context.Background(), &models.Comment{TaskID: 1, AuthorID: 7, Body: "  "})
+		assert.ErrorIs(t, err, utils.ErrEmptyComment)
+
+		err = svc.CreateComment(context.Background(), &models.Comment{TaskID: 1, AuthorID: 7, Body: strings.Repeat("a", models.MaxCommentLength+1)})
+		assert.ErrorIs(t, err, utils.ErrCommentTooLong)
+	})
+
+	t.Run("should not let viewers comment", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleViewer, nil).Once()
+
+		err := svc.CreateComment(context.Background(), &models.Comment{TaskID: 1, AuthorID: 7, Body: "Hi"})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("must trim the body before saving", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("CreateComment", mock.Anything, &models.Comment{TaskID: 1, AuthorID: 7, Body: "- [ ] check"}).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateComment(context.Background(), &models.Comment{TaskID: 1, AuthorID: 7, Body: "\n- [ ] check\n"}))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestListComments(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewCommentService(mockRepo, mockRoles)
+
+	t.Run("should reject a limit above the maximum page size", func(t *testing.T) {
+		_, err := svc.ListComments(context.Background(), 7, 1, "", models.MaxPageSize+1)
+		assert.ErrorIs(t, err, utils.ErrInvalidLimit)
+	})
+
+	t.Run("must let viewers read with the default page size", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("ListComments", mock.Anything, int64(1), "", models.DefaultPageSize).Return(&models.CommentPage{Comments: []*models.Comment{}}, nil).Once()
+
+		_, err := svc.ListComments(context.Background(), 7, 1, "", 0)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewCommentService(mockRepo, mockRoles)
+
+	t.Run("should only let the author edit a comment", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetComment", mock.Anything, int64(1), int64(3)).Return(&models.Comment{ID: 3, TaskID: 1, AuthorID: 8}, nil).Once()
+
+		err := svc.UpdateComment(context.Background(), 7, &models.Comment{ID: 3, TaskID: 1, Body: "Edited"})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return ErrCommentNotFound if the comment is not on the task", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetComment", mock.Anything, int64(1), int64(3)).Return((*models.Comment)(nil), nil).Once()
+
+		err := svc.UpdateComment(context.Background(), 7, &models.Comment{ID: 3, TaskID: 1, Body: "Edited"})
+		assert.ErrorIs(t, err, utils.ErrCommentNotFound)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewCommentService(mockRepo, mockRoles)
+
+	t.Run("must let the task owner remove any comment", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("DeleteComment", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		assert.NoError(t, svc.DeleteComment(context.Background(), 7, 1, 3))
+		mockRepo.AssertNotCalled(t, "GetComment", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should stop editors from removing other people's comments", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetComment", mock.Anything, int64(1), int64(3)).Return(&models.Comment{ID: 3, TaskID: 1, AuthorID: 8}, nil).Once()
+
+		err := svc.DeleteComment(context.Background(), 7, 1, 3)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+	})
+}
@@ -1,24 +1,31 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"time"
 
 	_ "github.com/lib/pq" // Driver para PostgreSQL
 )
 
-func Connect() (*sql.DB, error) {
+// DSN builds the connection string from the DB_* variables. Besides
+// Connect, it is used to open dedicated connections, such as the one that
+// LISTENs for task events.
+func DSN() string {
 	dbHost := os.Getenv("DB_HOST")
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
 	dbName := os.Getenv("DB_NAME")
 	dbPort := os.Getenv("DB_PORT")
 
-	dsn := fmt.Sprintf("host=%s user=%s password='%s' dbname=%s port=%s sslmode=disable",
+	return fmt.Sprintf("host=%s user=%s password='%s' dbname=%s port=%s sslmode=disable",
 		dbHost, dbUser, dbPassword, dbName, dbPort)
+}
 
-	db, err := sql.Open("postgres", dsn)
+func Connect() (*sql.DB, error) {
+	db, err := sql.Open("postgres", DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database %w", err)
 	}
@@ -30,3 +37,28 @@ func Connect() (*sql.DB, error) {
 	fmt.Println("Connected to PostgreSQL!")
 	return db, nil
 }
+
+const defaultQueryTimeout = 5 * time.Second
+
+func QueryTimeout() (time.Duration, error) {
+	value := os.Getenv("DB_QUERY_TIMEOUT")
+	if value == "" {
+		return defaultQueryTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
+	}
+
+	return timeout, nil
+}
+
+// WithTimeout bounds a single repository call by the query timeout. A
+// timeout of zero or less leaves the caller's deadline alone.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,54 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	t.Run("should default to five seconds", func(t *testing.T) {
+		t.Setenv("DB_QUERY_TIMEOUT", "")
+
+		timeout, err := db.QueryTimeout()
+		assert.NoError(t, err)
+		assert.Equal(t, 5*time.Second, timeout)
+	})
+
+	t.Run("should read the timeout from the environment", func(t *testing.T) {
+		t.Setenv("DB_QUERY_TIMEOUT", "250ms")
+
+		timeout, err := db.QueryTimeout()
+		assert.NoError(t, err)
+		assert.Equal(t, 250*time.Millisecond, timeout)
+	})
+
+	t.Run("should return an error if the value is not a duration", func(t *testing.T) {
+		t.Setenv("DB_QUERY_TIMEOUT", "soon")
+
+		_, err := db.QueryTimeout()
+		assert.Error(t, err)
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Run("must set a deadline when the timeout is positive", func(t *testing.T) {
+		ctx, cancel := db.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("should not set a deadline when the timeout is zero", func(t *testing.T) {
+		ctx, cancel := db.WithTimeout(context.Background(), 0)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	})
+}
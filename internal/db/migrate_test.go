@@ -0,0 +1,180 @@
+package db_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"todo_list_api/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("should load the embedded migrations ordered by version", func(t *testing.T) {
+		migrations, err := db.LoadMigrations()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+		assert.Equal(t, int64(1), migrations[0].Version)
+		assert.Equal(t, "create_tasks_table", migrations[0].Name)
+		assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS tasks")
+		assert.Contains(t, migrations[0].Down, "DROP TABLE IF EXISTS tasks")
+
+		for i := 1; i < len(migrations); i++ {
+			assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+		}
+	})
+}
+
+func TestMigrateUp(t *testing.T) {
+	migrations, err := db.LoadMigrations()
+	assert.NoError(t, err)
+
+	t.Run("should apply every pending migration and record it in the ledger", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+
+		for _, migration := range migrations {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT EXISTS").
+				WithArgs(migration.Version).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+				WithArgs(migration.Version, migration.Name).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		err = db.MigrateUp(conn)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should skip migrations already recorded in the ledger", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		rows := sqlmock.NewRows([]string{"version", "applied_at"})
+		for _, migration := range migrations {
+			rows.AddRow(migration.Version, time.Now())
+		}
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(rows)
+
+		err = db.MigrateUp(conn)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back and return an error if a migration fails", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(regexp.QuoteMeta(migrations[0].Up)).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+
+		err = db.MigrateUp(conn)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigrateDown(t *testing.T) {
+	migrations, err := db.LoadMigrations()
+	assert.NoError(t, err)
+	last, previous := migrations[len(migrations)-1], migrations[len(migrations)-2]
+
+	applied := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(previous.Version, time.Now()).
+			AddRow(last.Version, time.Now())
+	}
+
+	t.Run("should revert the latest migrations and report how many", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(applied())
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(last.Version).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(regexp.QuoteMeta(last.Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).
+			WithArgs(last.Version).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reverted, err := db.MigrateDown(conn, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, reverted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should skip a migration another run reverted first", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(applied())
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(last.Version).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectCommit()
+
+		reverted, err := db.MigrateDown(conn, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, reverted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatus(t *testing.T) {
+	migrations, err := db.LoadMigrations()
+	assert.NoError(t, err)
+
+	t.Run("should report applied and pending migrations", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		appliedAt := time.Now()
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(migrations[0].Version, appliedAt))
+
+		statuses, err := db.Status(conn)
+		assert.NoError(t, err)
+		assert.Len(t, statuses, len(migrations))
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+		for _, s := range statuses[1:] {
+			assert.Nil(t, s.AppliedAt)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
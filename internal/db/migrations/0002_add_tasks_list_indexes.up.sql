@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at_id ON tasks (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at_id ON tasks (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_title_id ON tasks (title, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_owner_id;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_tasks_priority_rank;
+
+DROP INDEX IF EXISTS idx_tasks_due_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority_rank;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
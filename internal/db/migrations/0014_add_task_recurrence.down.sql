@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_recurrence;
+DROP INDEX IF EXISTS idx_tasks_series_id_due_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS series_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS recurrence;
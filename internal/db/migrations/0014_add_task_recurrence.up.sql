@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id BIGINT REFERENCES tasks (id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_series_id_due_at ON tasks (series_id, due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_recurrence ON tasks (id) WHERE recurrence <> '';
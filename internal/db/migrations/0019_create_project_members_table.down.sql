@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS task_access;
+
+DROP TABLE IF EXISTS project_members;
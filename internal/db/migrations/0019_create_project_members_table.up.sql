@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id BIGINT NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members (user_id);
+
+-- task_access lists every role a user holds on a task: as its owner, as a
+-- member of the task, and through the project it is filed under.
+CREATE OR REPLACE VIEW task_access (task_id, user_id, role) AS
+    SELECT id, owner_id, 'owner'::TEXT FROM tasks
+    UNION ALL
+    SELECT task_id, user_id, role FROM task_members
+    UNION ALL
+    SELECT t.id, p.owner_id, 'owner'::TEXT FROM tasks t JOIN projects p ON p.id = t.project_id
+    UNION ALL
+    SELECT t.id, m.user_id, m.role FROM tasks t JOIN project_members m ON m.project_id = t.project_id;
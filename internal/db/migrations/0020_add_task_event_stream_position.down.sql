@@ -0,0 +1,3 @@
+ALTER TABLE task_events DROP COLUMN IF EXISTS stream_position;
+
+DROP SEQUENCE IF EXISTS task_event_stream_positions;
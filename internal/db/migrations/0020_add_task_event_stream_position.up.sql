@@ -0,0 +1,11 @@
+-- stream_position orders task events on the live stream. It is assigned when
+-- an event is relayed, under a lock held until commit, so unlike the id it
+-- follows commit order.
+CREATE SEQUENCE IF NOT EXISTS task_event_stream_positions;
+
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS stream_position BIGINT UNIQUE;
+
+-- Existing events keep their id as their position, so the Last-Event-ID of
+-- clients streaming across the upgrade stays valid.
+UPDATE task_events SET stream_position = id WHERE stream_position IS NULL;
+SELECT setval('task_event_stream_positions', COALESCE((SELECT MAX(stream_position) FROM task_events), 0) + 1, false);
@@ -0,0 +1,49 @@
+// Package httputil holds the request and response plumbing shared by the
+// HTTP handlers.
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo_list_api/internal/auth"
+	"todo_list_api/pkg/utils"
+)
+
+// RequireUser returns the authenticated user, writing ErrUnauthorized when
+// the request carries none.
+func RequireUser(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, utils.ErrUnauthorized)
+	}
+	return userID, ok
+}
+
+// PathID parses the named path wildcard as an id, writing the error when it
+// is missing or not a number.
+func PathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	value := r.PathValue(name)
+	if value == "" {
+		utils.WriteError(w, utils.ErrEmptyID)
+		return 0, false
+	}
+
+	ID, err := strconv.Atoi(value)
+	if err != nil {
+		utils.WriteError(w, utils.ErrInvalidId)
+		return 0, false
+	}
+
+	return int64(ID), true
+}
+
+// WriteJSON writes value as the JSON body of a response with the given
+// status.
+func WriteJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
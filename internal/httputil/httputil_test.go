@@ -0,0 +1,76 @@
+package httputil_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestRequireUser(t *testing.T) {
+	t.Run("should return 401 if the request has no user", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/tasks", nil)
+		rr := httptest.NewRecorder()
+
+		_, ok := httputil.RequireUser(rr, req)
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("must return the user from the context", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/tasks", nil)
+		req = req.WithContext(auth.WithUserID(req.Context(), 7))
+		rr := httptest.NewRecorder()
+
+		userID, ok := httputil.RequireUser(rr, req)
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), userID)
+	})
+}
+
+func TestPathID(t *testing.T) {
+	t.Run("should reject an id that is not a number", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/tasks/abc", nil)
+		req.SetPathValue("id", "abc")
+		rr := httptest.NewRecorder()
+
+		_, ok := httputil.PathID(rr, req, "id")
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "invalid_id", decodeError(t, rr).Code)
+	})
+
+	t.Run("must parse the named wildcard", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/tasks/3/labels/5", nil)
+		req.SetPathValue("id", "3")
+		req.SetPathValue("labelID", "5")
+		rr := httptest.NewRecorder()
+
+		id, ok := httputil.PathID(rr, req, "labelID")
+		assert.True(t, ok)
+		assert.Equal(t, int64(5), id)
+	})
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Run("must write the status and the encoded value", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		httputil.WriteJSON(rr, http.StatusCreated, map[string]int{"id": 1})
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"id":1}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo_list_api/internal/httputil"
+	s "todo_list_api/internal/label/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var label models.Label
+	if err := json.NewDecoder(r.Body).Decode(&label); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	label.ID = 0
+	label.OwnerID = userID
+
+	if err := h.service.CreateLabel(r.Context(), &label); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&label); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+func (h *Handler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	labels, err := h.service.ListLabels(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(labels); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+func (h *Handler) GetLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	label, err := h.service.GetLabel(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(label); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+func (h *Handler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var label models.Label
+	if err := json.NewDecoder(r.Body).Decode(&label); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	label.ID = ID
+	label.OwnerID = userID
+
+	if err := h.service.UpdateLabel(r.Context(), &label); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(&label); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+func (h *Handler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLabel(r.Context(), userID, ID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) AttachLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	labelID, ok := httputil.PathID(w, r, "labelID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.AttachLabel(r.Context(), userID, taskID, labelID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DetachLabel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	taskID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	labelID, ok := httputil.PathID(w, r, "labelID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DetachLabel(r.Context(), userID, taskID, labelID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
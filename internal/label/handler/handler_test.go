@@ -0,0 +1,158 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/label/handler"
+	m "todo_list_api/internal/label/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func TestCreateLabel(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 401 if the request is not authenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/labels", bytes.NewBufferString(`{"name":"bug"}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateLabel(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/labels", bytes.NewBufferString("invalid json"))
+		rr := httptest.NewRecorder()
+
+		handler.CreateLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 409 if the name is taken", func(t *testing.T) {
+		mockService.On("CreateLabel", mock.Anything, &models.Label{OwnerID: userID, Name: "bug"}).Return(utils.ErrLabelTaken).Once()
+
+		req, _ := http.NewRequest("POST", "/labels", bytes.NewBufferString(`{"name":"bug"}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, "label_taken", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 201 and stamp the caller as owner", func(t *testing.T) {
+		mockService.On("CreateLabel", mock.Anything, &models.Label{OwnerID: userID, Name: "bug", Color: "#ff0000"}).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", "/labels", bytes.NewBufferString(`{"name":"bug","color":"#ff0000","owner_id":99}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetLabel(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the id is not a number", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/labels/abc", nil)
+		req.SetPathValue("id", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.GetLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 404 if the label is not visible", func(t *testing.T) {
+		mockService.On("GetLabel", mock.Anything, userID, int64(1)).Return((*models.Label)(nil), utils.ErrLabelNotFound).Once()
+
+		req, _ := http.NewRequest("GET", "/labels/1", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.GetLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "label_not_found", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestAttachLabel(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequest("PUT", "/tasks/1/labels/3", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("labelID", "3")
+		return withUser(req)
+	}
+
+	t.Run("should return 403 if the caller cannot edit the task", func(t *testing.T) {
+		mockService.On("AttachLabel", mock.Anything, userID, int64(1), int64(3)).Return(utils.ErrForbidden).Once()
+
+		rr := httptest.NewRecorder()
+		handler.AttachLabel(rr, newRequest())
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 204 once the label is attached", func(t *testing.T) {
+		mockService.On("AttachLabel", mock.Anything, userID, int64(1), int64(3)).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.AttachLabel(rr, newRequest())
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDetachLabel(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 404 if the label is not attached", func(t *testing.T) {
+		mockService.On("DetachLabel", mock.Anything, userID, int64(1), int64(3)).Return(utils.ErrLabelNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", "/tasks/1/labels/3", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("labelID", "3")
+		rr := httptest.NewRecorder()
+
+		handler.DetachLabel(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,54 @@
+package label
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// AttachLabel implements Repository.
+func (m *MockRepository) AttachLabel(ctx context.Context, taskID, labelID int64) error {
+	args := m.Called(ctx, taskID, labelID)
+	return args.Error(0)
+}
+
+// CreateLabel implements Repository.
+func (m *MockRepository) CreateLabel(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+
+// DeleteLabel implements Repository.
+func (m *MockRepository) DeleteLabel(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
+	return args.Error(0)
+}
+
+// DetachLabel implements Repository.
+func (m *MockRepository) DetachLabel(ctx context.Context, taskID, labelID int64) error {
+	args := m.Called(ctx, taskID, labelID)
+	return args.Error(0)
+}
+
+// GetLabel implements Repository.
+func (m *MockRepository) GetLabel(ctx context.Context, id int64) (*models.Label, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Label), args.Error(1)
+}
+
+// ListLabels implements Repository.
+func (m *MockRepository) ListLabels(ctx context.Context, ownerID int64) ([]*models.Label, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]*models.Label), args.Error(1)
+}
+
+// UpdateLabel implements Repository.
+func (m *MockRepository) UpdateLabel(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
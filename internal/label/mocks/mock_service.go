@@ -0,0 +1,54 @@
+package label
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// AttachLabel implements label.Service.
+func (m *MockService) AttachLabel(ctx context.Context, userID, taskID, labelID int64) error {
+	args := m.Called(ctx, userID, taskID, labelID)
+	return args.Error(0)
+}
+
+// CreateLabel implements label.Service.
+func (m *MockService) CreateLabel(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+
+// DeleteLabel implements label.Service.
+func (m *MockService) DeleteLabel(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+// DetachLabel implements label.Service.
+func (m *MockService) DetachLabel(ctx context.Context, userID, taskID, labelID int64) error {
+	args := m.Called(ctx, userID, taskID, labelID)
+	return args.Error(0)
+}
+
+// GetLabel implements label.Service.
+func (m *MockService) GetLabel(ctx context.Context, userID, id int64) (*models.Label, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Label), args.Error(1)
+}
+
+// ListLabels implements label.Service.
+func (m *MockService) ListLabels(ctx context.Context, userID int64) ([]*models.Label, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Label), args.Error(1)
+}
+
+// UpdateLabel implements label.Service.
+func (m *MockService) UpdateLabel(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
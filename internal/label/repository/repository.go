@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateLabel(ctx context.Context, label *models.Label) error
+	GetLabel(ctx context.Context, id int64) (*models.Label, error)
+	ListLabels(ctx context.Context, ownerID int64) ([]*models.Label, error)
+	UpdateLabel(ctx context.Context, label *models.Label) error
+	DeleteLabel(ctx context.Context, ownerID, id int64) error
+	AttachLabel(ctx context.Context, taskID, labelID int64) error
+	DetachLabel(ctx context.Context, taskID, labelID int64) error
+}
+
+type LabelRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewLabelRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &LabelRepository{db: db, timeout: timeout}
+}
+
+func (r *LabelRepository) CreateLabel(ctx context.Context, label *models.Label) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "INSERT INTO labels (owner_id, name, color, created_at) VALUES ($1, $2, $3, $4) RETURNING id"
+	label.CreatedAt = time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		label.OwnerID,
+		label.Name,
+		label.Color,
+		label.CreatedAt,
+	).Scan(&label.ID)
+
+	return translateUniqueViolation(err)
+}
+
+func (r *LabelRepository) GetLabel(ctx context.Context, id int64) (*models.Label, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT id, owner_id, name, color, created_at FROM labels WHERE id = $1"
+	label := &models.Label{}
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&label.ID,
+		&label.OwnerID,
+		&label.Name,
+		&label.Color,
+		&label.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return label, nil
+}
+
+func (r *LabelRepository) ListLabels(ctx context.Context, ownerID int64) ([]*models.Label, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT id, owner_id, name, color, created_at FROM labels WHERE owner_id = $1 ORDER BY name ASC"
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []*models.Label{}
+	for rows.Next() {
+		label := &models.Label{}
+		if err := rows.Scan(
+			&label.ID,
+			&label.OwnerID,
+			&label.Name,
+			&label.Color,
+			&label.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, rows.Err()
+}
+
+// UpdateLabel renames or recolors an owned label. The tasks carrying it
+// embed the label, so their versions are bumped in the same statement.
+func (r *LabelRepository) UpdateLabel(ctx context.Context, label *models.Label) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `WITH updated AS (
+	UPDATE labels SET name = $3, color = $4 WHERE id = $1 AND owner_id = $2 RETURNING id, created_at
+), bumped AS (
+	UPDATE tasks SET updated_at = $5, version = version + 1
+	WHERE id IN (SELECT tl.task_id FROM task_labels tl JOIN updated u ON tl.label_id = u.id)
+)
+SELECT created_at FROM updated`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		label.ID,
+		label.OwnerID,
+		label.Name,
+		label.Color,
+		time.Now(),
+	).Scan(&label.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrLabelNotFound
+	}
+
+	return translateUniqueViolation(err)
+}
+
+// DeleteLabel removes an owned label, and with it every link to a task. The
+// tasks that carried it are bumped in the same statement; the subquery still
+// sees their links, as it reads the snapshot taken before the delete.
+func (r *LabelRepository) DeleteLabel(ctx context.Context, ownerID, id int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `WITH deleted AS (
+	DELETE FROM labels WHERE id = $1 AND owner_id = $2 RETURNING id
+), bumped AS (
+	UPDATE tasks SET updated_at = $3, version = version + 1
+	WHERE id IN (SELECT tl.task_id FROM task_labels tl JOIN deleted d ON tl.label_id = d.id)
+)
+SELECT COUNT(*) FROM deleted`
+	var deleted int64
+	if err := r.db.QueryRowContext(ctx, query, id, ownerID, time.Now()).Scan(&deleted); err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return utils.ErrLabelNotFound
+	}
+
+	return nil
+}
+
+// AttachLabel links a label to a task. The task's version is bumped in the
+// same transaction so cached representations embedding its labels go stale.
+// Attaching a label the task already carries changes nothing and leaves the
+// version alone.
+func (r *LabelRepository) AttachLabel(ctx context.Context, taskID, labelID int64) error {
+	const query = "INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2) ON CONFLICT (task_id, label_id) DO NOTHING"
+	return r.changeTaskLabels(ctx, taskID, func(tx *sql.Tx) (int64, error) {
+		result, err := tx.ExecContext(ctx, query, taskID, labelID)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// DetachLabel unlinks a label from a task, bumping the task's version.
+func (r *LabelRepository) DetachLabel(ctx context.Context, taskID, labelID int64) error {
+	const query = "DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2"
+	return r.changeTaskLabels(ctx, taskID, func(tx *sql.Tx) (int64, error) {
+		result, err := tx.ExecContext(ctx, query, taskID, labelID)
+		if err != nil {
+			return 0, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err == nil && affected == 0 {
+			return 0, utils.ErrLabelNotFound
+		}
+		return affected, err
+	})
+}
+
+// changeTaskLabels runs change against a live task and bumps the task's
+// version only when change reports that a row was affected.
+func (r *LabelRepository) changeTaskLabels(ctx context.Context, taskID int64, change func(tx *sql.Tx) (int64, error)) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const lock = "SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	var id int64
+	if err := tx.QueryRowContext(ctx, lock, taskID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.ErrTaskNotFound
+		}
+		return err
+	}
+
+	affected, err := change(tx)
+	if err != nil {
+		return translateForeignKeyViolation(err)
+	}
+	if affected == 0 {
+		return nil
+	}
+
+	const bump = "UPDATE tasks SET updated_at = $2, version = version + 1 WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, bump, taskID, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func translateUniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return utils.ErrLabelTaken
+	}
+	return err
+}
+
+func translateForeignKeyViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return utils.ErrLabelNotFound
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/label/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	const query = "INSERT INTO labels \\(owner_id, name, color, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id"
+
+	t.Run("must insert the label and return its id", func(t *testing.T) {
+		label := &models.Label{OwnerID: 7, Name: "bug", Color: "#ff0000"}
+
+		mock.ExpectQuery(query).
+			WithArgs(int64(7), "bug", "#ff0000", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+		err := repo.CreateLabel(context.Background(), label)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), label.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrLabelTaken if the owner already has the name", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(7), "bug", "#ff0000", sqlmock.AnyArg()).
+			WillReturnError(&pq.Error{Code: "23505"})
+
+		err := repo.CreateLabel(context.Background(), &models.Label{OwnerID: 7, Name: "bug", Color: "#ff0000"})
+		assert.ErrorIs(t, err, utils.ErrLabelTaken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListLabels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	t.Run("must return the owner's labels ordered by name", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT id, owner_id, name, color, created_at FROM labels WHERE owner_id = \\$1 ORDER BY name ASC").
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "name", "color", "created_at"}).
+				AddRow(2, 7, "backend", "#00ff00", now).
+				AddRow(1, 7, "bug", "#ff0000", now))
+
+		labels, err := repo.ListLabels(context.Background(), 7)
+		assert.NoError(t, err)
+		assert.Len(t, labels, 2)
+		assert.Equal(t, "backend", labels[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateLabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	const query = "UPDATE labels SET name = \\$3, color = \\$4 WHERE id = \\$1 AND owner_id = \\$2 RETURNING id, created_at.+UPDATE tasks SET updated_at = \\$5, version = version \\+ 1.+JOIN updated u ON tl.label_id = u.id.+SELECT created_at FROM updated"
+
+	t.Run("must scope the update to the owner and bump the tasks carrying the label", func(t *testing.T) {
+		createdAt := time.Now().Add(-time.Hour)
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7), "bug", "#ff0000", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+		label := &models.Label{ID: 1, OwnerID: 7, Name: "bug", Color: "#ff0000"}
+		assert.NoError(t, repo.UpdateLabel(context.Background(), label))
+		assert.Equal(t, createdAt, label.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrLabelNotFound if no owned label matched", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(8), "bug", "#ff0000", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}))
+
+		err := repo.UpdateLabel(context.Background(), &models.Label{ID: 1, OwnerID: 8, Name: "bug", Color: "#ff0000"})
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteLabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	const query = "DELETE FROM labels WHERE id = \\$1 AND owner_id = \\$2 RETURNING id.+UPDATE tasks SET updated_at = \\$3, version = version \\+ 1.+JOIN deleted d ON tl.label_id = d.id.+SELECT COUNT\\(\\*\\) FROM deleted"
+
+	t.Run("must delete the label and bump the tasks carrying it in one statement", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		assert.NoError(t, repo.DeleteLabel(context.Background(), 7, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrLabelNotFound if nothing was deleted", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		err := repo.DeleteLabel(context.Background(), 7, 1)
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAttachLabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	const lock = "SELECT id FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE"
+	const insert = "INSERT INTO task_labels \\(task_id, label_id\\) VALUES \\(\\$1, \\$2\\) ON CONFLICT \\(task_id, label_id\\) DO NOTHING"
+	const bump = "UPDATE tasks SET updated_at = \\$2, version = version \\+ 1 WHERE id = \\$1"
+
+	t.Run("must link the label and bump the task version", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(insert).WithArgs(int64(1), int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(bump).WithArgs(int64(1), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.AttachLabel(context.Background(), 1, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should leave the task version alone if the label was already attached", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(insert).WithArgs(int64(1), int64(3)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.NoError(t, repo.AttachLabel(context.Background(), 1, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task is gone", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.AttachLabel(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the insert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(insert).WithArgs(int64(1), int64(3)).WillReturnError(errors.New("insert failed"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.AttachLabel(context.Background(), 1, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDetachLabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLabelRepository(db, time.Second)
+
+	t.Run("should return ErrLabelNotFound without bumping the task if the label was not attached", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM task_labels WHERE task_id = \\$1 AND label_id = \\$2").
+			WithArgs(int64(1), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.DetachLabel(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
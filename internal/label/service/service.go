@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"strings"
+	r "todo_list_api/internal/label/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Service interface {
+	CreateLabel(ctx context.Context, label *models.Label) error
+	ListLabels(ctx context.Context, userID int64) ([]*models.Label, error)
+	GetLabel(ctx context.Context, userID, id int64) (*models.Label, error)
+	UpdateLabel(ctx context.Context, label *models.Label) error
+	DeleteLabel(ctx context.Context, userID, id int64) error
+	AttachLabel(ctx context.Context, userID, taskID, labelID int64) error
+	DetachLabel(ctx context.Context, userID, taskID, labelID int64) error
+}
+
+// TaskRoles tells the label service whether a user may edit the task a label
+// is attached to or detached from. It is backed by the task repository.
+type TaskRoles interface {
+	GetTaskRole(ctx context.Context, userID, taskID int64) (string, error)
+}
+
+type LabelService struct {
+	repo  r.Repository
+	roles TaskRoles
+}
+
+func NewLabelService(repo r.Repository, roles TaskRoles) Service {
+	return &LabelService{repo: repo, roles: roles}
+}
+
+func (s *LabelService) CreateLabel(ctx context.Context, label *models.Label) error {
+	if err := normalizeLabel(label); err != nil {
+		return err
+	}
+
+	return s.repo.CreateLabel(ctx, label)
+}
+
+func (s *LabelService) ListLabels(ctx context.Context, userID int64) ([]*models.Label, error) {
+	return s.repo.ListLabels(ctx, userID)
+}
+
+func (s *LabelService) GetLabel(ctx context.Context, userID, id int64) (*models.Label, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	label, err := s.repo.GetLabel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Labels are private to their owner, so other users see them as missing.
+	if label == nil || label.OwnerID != userID {
+		return nil, utils.ErrLabelNotFound
+	}
+
+	return label, nil
+}
+
+func (s *LabelService) UpdateLabel(ctx context.Context, label *models.Label) error {
+	if label.ID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := normalizeLabel(label); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateLabel(ctx, label)
+}
+
+func (s *LabelService) DeleteLabel(ctx context.Context, userID, id int64) error {
+	if id < 0 {
+		return utils.ErrInvalidId
+	}
+
+	return s.repo.DeleteLabel(ctx, userID, id)
+}
+
+func (s *LabelService) AttachLabel(ctx context.Context, userID, taskID, labelID int64) error {
+	if taskID < 0 || labelID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := s.requireEditor(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	if _, err := s.GetLabel(ctx, userID, labelID); err != nil {
+		return err
+	}
+
+	return s.repo.AttachLabel(ctx, taskID, labelID)
+}
+
+func (s *LabelService) DetachLabel(ctx context.Context, userID, taskID, labelID int64) error {
+	if taskID < 0 || labelID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := s.requireEditor(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	return s.repo.DetachLabel(ctx, taskID, labelID)
+}
+
+func (s *LabelService) requireEditor(ctx context.Context, userID, taskID int64) error {
+	role, err := s.roles.GetTaskRole(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.HasRole(role, models.RoleEditor) {
+		return utils.ErrForbidden
+	}
+
+	return nil
+}
+
+func normalizeLabel(label *models.Label) error {
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" {
+		return utils.ErrEmptyLabelName
+	}
+
+	if label.Color == "" {
+		label.Color = models.DefaultLabelColor
+	}
+
+	return utils.ValidateColor(label.Color)
+}
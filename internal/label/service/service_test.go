@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	m "todo_list_api/internal/label/mocks"
+	"todo_list_api/internal/label/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateLabel(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewLabelService(mockRepo, new(m.MockTaskRoles))
+
+	t.Run("should reject a blank name", func(t *testing.T) {
+		err := svc.CreateLabel(context.Background(), &models.Label{OwnerID: 7, Name: "  "})
+		assert.ErrorIs(t, err, utils.ErrEmptyLabelName)
+	})
+
+	t.Run("should reject a malformed color", func(t *testing.T) {
+		err := svc.CreateLabel(context.Background(), &models.Label{OwnerID: 7, Name: "bug", Color: "red"})
+		assert.ErrorIs(t, err, utils.ErrInvalidColor)
+	})
+
+	t.Run("must trim the name and default the color", func(t *testing.T) {
+		expected := &models.Label{OwnerID: 7, Name: "bug", Color: models.DefaultLabelColor}
+		mockRepo.On("CreateLabel", mock.Anything, expected).Return(nil).Once()
+
+		err := svc.CreateLabel(context.Background(), &models.Label{OwnerID: 7, Name: " bug "})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetLabel(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewLabelService(mockRepo, new(m.MockTaskRoles))
+
+	t.Run("should hide labels owned by someone else", func(t *testing.T) {
+		mockRepo.On("GetLabel", mock.Anything, int64(1)).Return(&models.Label{ID: 1, OwnerID: 8}, nil).Once()
+
+		_, err := svc.GetLabel(context.Background(), 7, 1)
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrLabelNotFound if the label does not exist", func(t *testing.T) {
+		mockRepo.On("GetLabel", mock.Anything, int64(2)).Return((*models.Label)(nil), nil).Once()
+
+		_, err := svc.GetLabel(context.Background(), 7, 2)
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAttachLabel(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewLabelService(mockRepo, mockRoles)
+
+	t.Run("should forbid viewers", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleViewer, nil).Once()
+
+		err := svc.AttachLabel(context.Background(), 7, 1, 3)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRoles.AssertExpectations(t)
+	})
+
+	t.Run("should refuse labels the user does not own", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetLabel", mock.Anything, int64(3)).Return(&models.Label{ID: 3, OwnerID: 8}, nil).Once()
+
+		err := svc.AttachLabel(context.Background(), 7, 1, 3)
+		assert.ErrorIs(t, err, utils.ErrLabelNotFound)
+		mockRepo.AssertNotCalled(t, "AttachLabel", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("must attach an owned label for an editor", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetLabel", mock.Anything, int64(3)).Return(&models.Label{ID: 3, OwnerID: 7}, nil).Once()
+		mockRepo.On("AttachLabel", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		assert.NoError(t, svc.AttachLabel(context.Background(), 7, 1, 3))
+		mockRepo.AssertExpectations(t)
+		mockRoles.AssertExpectations(t)
+	})
+}
+
+func TestDetachLabel(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockRoles := new(m.MockTaskRoles)
+	svc := service.NewLabelService(mockRepo, mockRoles)
+
+	t.Run("must detach the label for an editor", func(t *testing.T) {
+		mockRoles.On("GetTaskRole", mock.Anything, int64(7), int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("DetachLabel", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		assert.NoError(t, svc.DetachLabel(context.Background(), 7, 1, 3))
+		mockRepo.AssertExpectations(t)
+		mockRoles.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"todo_list_api/pkg/utils"
+)
+
+// AllowOrigins guards the endpoints that browsers open across origins
+// without a preflight, such as EventSource and WebSocket. A request from a
+// page on another origin is refused unless that origin is listed; requests
+// from the server's own origin, or with no Origin header at all, pass. An
+// allowed request gets the CORS headers EventSource needs to read the
+// response.
+func AllowOrigins(origins []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !sameOrigin(origin, r.Host) && !slices.Contains(origins, origin) {
+			utils.WriteError(w, utils.ErrOriginNotAllowed)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func sameOrigin(origin, host string) bool {
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host != "" && parsed.Host == host
+}
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo_list_api/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowOrigins(t *testing.T) {
+	handler := middleware.AllowOrigins([]string{"https://app.example.com"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(origin string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "http://api.example.com/tasks/events", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("should let requests without an origin through", func(t *testing.T) {
+		rr := serve("")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("should let requests from the server's own origin through", func(t *testing.T) {
+		rr := serve("http://api.example.com")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should allow listed origins", func(t *testing.T) {
+		rr := serve("https://app.example.com")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("should return 403 for other origins", func(t *testing.T) {
+		rr := serve("https://evil.example.com")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo_list_api/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = middleware.RequestIDFromContext(r.Context())
+	}))
+
+	t.Run("should generate a request id when none is given", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Len(t, rr.Header().Get("X-Request-ID"), 32)
+		assert.Equal(t, rr.Header().Get("X-Request-ID"), seen)
+	})
+
+	t.Run("should reuse a valid incoming request id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("X-Request-ID", "upstream-id")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, "upstream-id", rr.Header().Get("X-Request-ID"))
+		assert.Equal(t, "upstream-id", seen)
+	})
+
+	t.Run("should replace an invalid incoming request id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("X-Request-ID", strings.Repeat("a", 200))
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Len(t, rr.Header().Get("X-Request-ID"), 32)
+	})
+}
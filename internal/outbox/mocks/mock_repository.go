@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"time"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// ClaimEvents implements Repository.
+func (m *MockRepository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*models.OutboxEvent), args.Error(1)
+}
+
+// MarkPublished implements Repository.
+func (m *MockRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	args := m.Called(ctx, id, publishedAt)
+	return args.Error(0)
+}
+
+// PrunePublished implements Repository.
+func (m *MockRepository) PrunePublished(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// RecordFailure implements Repository.
+func (m *MockRepository) RecordFailure(ctx context.Context, event *models.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+)
+
+// FromTaskEvents ends a statement that writes task events: it copies the rows
+// returned by a preceding CTE named "events" into the outbox, so an event and
+// its outbox entry are committed together. The events CTE must return id,
+// task_id, actor_id, action, changes and created_at.
+const FromTaskEvents = `INSERT INTO outbox (idempotency_key, topic, task_id, payload, created_at)
+SELECT 'task-event-' || e.id, 'task.' || e.action, e.task_id,
+	jsonb_build_object('id', e.id, 'task_id', e.task_id, 'actor_id', e.actor_id, 'action', e.action, 'changes', e.changes, 'created_at', e.created_at),
+	e.created_at
+FROM events e`
+
+type Repository interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	RecordFailure(ctx context.Context, event *models.OutboxEvent) error
+	PrunePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+const outboxColumns = "id, idempotency_key, topic, task_id, payload, attempts, next_attempt_at, last_error, created_at, published_at"
+
+type OutboxRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewOutboxRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &OutboxRepository{db: db, timeout: timeout}
+}
+
+// ClaimEvents picks up to limit unpublished events that are due, oldest
+// first, and pushes their next attempt back by lease so that other replicas
+// skip them while they are in flight. An event whose outcome is never
+// recorded, because the process died, becomes due again once the lease runs
+// out.
+func (r *OutboxRepository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `UPDATE outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox WHERE published_at IS NULL AND next_attempt_at <= $1
+			ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING ` + outboxColumns
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.OutboxEvent{}
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.IdempotencyKey,
+			&event.Topic,
+			&event.TaskID,
+			&payload,
+			&event.Attempts,
+			&event.NextAttemptAt,
+			&event.LastError,
+			&event.CreatedAt,
+			&event.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	slices.SortFunc(events, func(a, b *models.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "UPDATE outbox SET published_at = $2, last_error = '' WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id, publishedAt)
+	return err
+}
+
+func (r *OutboxRepository) RecordFailure(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "UPDATE outbox SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, event.ID, event.Attempts, event.NextAttemptAt, event.LastError)
+	return err
+}
+
+// PrunePublished deletes events published before the given time and returns
+// how many were deleted.
+func (r *OutboxRepository) PrunePublished(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM outbox WHERE published_at < $1"
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
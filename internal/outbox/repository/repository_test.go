@@ -0,0 +1,99 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/outbox/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var outboxColumns = []string{"id", "idempotency_key", "topic", "task_id", "payload", "attempts", "next_attempt_at", "last_error", "created_at", "published_at"}
+
+func TestClaimEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db, time.Second)
+
+	t.Run("must lease due events in the order they were written", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("UPDATE outbox SET next_attempt_at = \\$2 WHERE id IN \\( ?SELECT id FROM outbox WHERE published_at IS NULL AND next_attempt_at <= \\$1 ORDER BY id LIMIT \\$3 FOR UPDATE SKIP LOCKED\\) RETURNING").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 10).
+			WillReturnRows(sqlmock.NewRows(outboxColumns).
+				AddRow(12, "task-event-40", models.WebhookTaskUpdated, 5, []byte(`{"id":40}`), 0, now, "", now, nil).
+				AddRow(11, "task-event-39", models.WebhookTaskCreated, 5, []byte(`{"id":39}`), 2, now, "timeout", now, nil))
+
+		events, err := repo.ClaimEvents(context.Background(), 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, int64(11), events[0].ID)
+		assert.Equal(t, "task-event-39", events[0].IdempotencyKey)
+		assert.Equal(t, 2, events[0].Attempts)
+		assert.JSONEq(t, `{"id":39}`, string(events[0].Payload))
+		assert.Equal(t, int64(12), events[1].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkPublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db, time.Second)
+
+	t.Run("must stamp the event and clear its last error", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectExec("UPDATE outbox SET published_at = \\$2, last_error = '' WHERE id = \\$1").
+			WithArgs(int64(11), now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkPublished(context.Background(), 11, now)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db, time.Second)
+
+	t.Run("must save the attempts, the next attempt and the error", func(t *testing.T) {
+		next := time.Now().Add(time.Minute)
+		mock.ExpectExec("UPDATE outbox SET attempts = \\$2, next_attempt_at = \\$3, last_error = \\$4 WHERE id = \\$1").
+			WithArgs(int64(11), 3, next, "connection reset").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RecordFailure(context.Background(), &models.OutboxEvent{ID: 11, Attempts: 3, NextAttemptAt: next, LastError: "connection reset"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPrunePublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db, time.Second)
+
+	t.Run("must only delete published events", func(t *testing.T) {
+		before := time.Now().Add(-time.Hour)
+		mock.ExpectExec("DELETE FROM outbox WHERE published_at < \\$1").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		deleted, err := repo.PrunePublished(context.Background(), before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
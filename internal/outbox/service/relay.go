@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	r "todo_list_api/internal/outbox/repository"
+	"todo_list_api/pkg/models"
+)
+
+// EventPublisher hands an outbox event to whatever consumes it. Publish may
+// be called more than once for the same event, so it should be idempotent on
+// event.IdempotencyKey.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// Publishers fans an event out to several publishers. If any of them fails
+// the event is retried for all of them, which is safe since they are all
+// idempotent.
+type Publishers []EventPublisher
+
+// Publish implements EventPublisher.
+func (p Publishers) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+const (
+	relayBatch = 100
+	// relayLease must outlast a publish, or a slow event could be claimed
+	// again while still in flight.
+	relayLease = time.Minute
+	// publishedRetention is how long published events are kept around for
+	// inspection before they are pruned.
+	publishedRetention = 7 * 24 * time.Hour
+)
+
+// Relay drains the outbox to a publisher. Every event is published at least
+// once: an event that fails is retried after backoff, doubling on every
+// attempt, and is never dropped.
+type Relay struct {
+	repo      r.Repository
+	publisher EventPublisher
+	backoff   time.Duration
+	now       func() time.Time
+}
+
+func NewRelay(repo r.Repository, publisher EventPublisher, backoff time.Duration) *Relay {
+	return &Relay{repo: repo, publisher: publisher, backoff: backoff, now: time.Now}
+}
+
+// Drain publishes every event that is due and returns how many were
+// published.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		events, err := r.repo.ClaimEvents(ctx, relayBatch, relayLease)
+		if err != nil {
+			return published, err
+		}
+
+		for _, event := range events {
+			if err := r.publisher.Publish(ctx, event); err != nil {
+				event.Attempts++
+				event.NextAttemptAt = r.now().Add(r.backoff << min(event.Attempts-1, 10))
+				event.LastError = err.Error()
+				if err := r.repo.RecordFailure(ctx, event); err != nil {
+					return published, err
+				}
+				continue
+			}
+
+			if err := r.repo.MarkPublished(ctx, event.ID, r.now()); err != nil {
+				return published, err
+			}
+			published++
+		}
+
+		if len(events) < relayBatch {
+			return published, nil
+		}
+	}
+}
+
+// Run drains the outbox once immediately and then on every interval until
+// ctx is done, pruning old published events as it goes.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(ctx); err != nil {
+			log.Printf("could not relay outbox events: %v", err)
+		}
+
+		if _, err := r.repo.PrunePublished(ctx, r.now().Add(-publishedRetention)); err != nil {
+			log.Printf("could not prune the outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
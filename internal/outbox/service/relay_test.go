@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	m "todo_list_api/internal/outbox/mocks"
+	"todo_list_api/internal/outbox/service"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDrain(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockPublisher := new(m.MockPublisher)
+	relay := service.NewRelay(mockRepo, mockPublisher, time.Minute)
+
+	event := func(id int64, attempts int) *models.OutboxEvent {
+		return &models.OutboxEvent{ID: id, IdempotencyKey: "task-event-1", Topic: models.WebhookTaskCreated, TaskID: 5, Payload: []byte(`{}`), Attempts: attempts}
+	}
+
+	t.Run("must mark published events", func(t *testing.T) {
+		published := event(1, 0)
+		mockRepo.On("ClaimEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*models.OutboxEvent{published}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, published).Return(nil).Once()
+		mockRepo.On("MarkPublished", mock.Anything, int64(1), mock.Anything).Return(nil).Once()
+
+		count, err := relay.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("should keep a failed event and back off exponentially", func(t *testing.T) {
+		failed := event(2, 2)
+		mockRepo.On("ClaimEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*models.OutboxEvent{failed}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, failed).Return(errors.New("connection reset")).Once()
+		mockRepo.On("RecordFailure", mock.Anything, failed).Return(nil).Once()
+
+		before := time.Now()
+		count, err := relay.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		assert.Equal(t, 3, failed.Attempts)
+		assert.Equal(t, "connection reset", failed.LastError)
+		assert.WithinRange(t, failed.NextAttemptAt, before.Add(4*time.Minute), time.Now().Add(4*time.Minute))
+		mockRepo.AssertNotCalled(t, "MarkPublished", mock.Anything, int64(2), mock.Anything)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("must keep claiming while batches are full", func(t *testing.T) {
+		full := make([]*models.OutboxEvent, 100)
+		for i := range full {
+			full[i] = event(int64(i+10), 0)
+		}
+		mockRepo.On("ClaimEvents", mock.Anything, 100, mock.Anything).Return(full, nil).Once()
+		mockRepo.On("ClaimEvents", mock.Anything, 100, mock.Anything).Return([]*models.OutboxEvent{event(200, 0)}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, mock.Anything).Return(nil).Times(101)
+		mockRepo.On("MarkPublished", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(101)
+
+		count, err := relay.Drain(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 101, count)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("should stop when the outbox cannot be read", func(t *testing.T) {
+		mockRepo.On("ClaimEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*models.OutboxEvent(nil), errors.New("connection refused")).Once()
+
+		_, err := relay.Drain(context.Background())
+		assert.EqualError(t, err, "connection refused")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPublishers(t *testing.T) {
+	first, second := new(m.MockPublisher), new(m.MockPublisher)
+	publishers := service.Publishers{first, second}
+	event := &models.OutboxEvent{ID: 1, IdempotencyKey: "task-event-1"}
+
+	t.Run("should publish to the others when one fails", func(t *testing.T) {
+		first.On("Publish", mock.Anything, event).Return(errors.New("connection reset")).Once()
+		second.On("Publish", mock.Anything, event).Return(nil).Once()
+
+		err := publishers.Publish(context.Background(), event)
+		assert.EqualError(t, err, "connection reset")
+		first.AssertExpectations(t)
+		second.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo_list_api/internal/httputil"
+	s "todo_list_api/internal/project/service"
+	taskHandler "todo_list_api/internal/task/handler"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var project models.Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	project.ID = 0
+	project.OwnerID = userID
+	project.ArchivedAt = nil
+
+	if err := h.service.CreateProject(r.Context(), &project); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, &project)
+}
+
+func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	projects, err := h.service.ListProjects(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, projects)
+}
+
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	project, err := h.service.GetProject(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, project)
+}
+
+func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var project models.Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	project.ID = ID
+	project.OwnerID = userID
+
+	if err := h.service.UpdateProject(r.Context(), &project); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, &project)
+}
+
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteProject(r.Context(), userID, ID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ArchiveProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	project, err := h.service.ArchiveProject(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, project)
+}
+
+func (h *Handler) UnarchiveProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	project, err := h.service.UnarchiveProject(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, project)
+}
+
+func (h *Handler) ListProjectTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	filter, err := taskHandler.ParseTaskFilter(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+	filter.ProjectID = &ID
+
+	page, err := h.service.ListProjectTasks(r.Context(), userID, ID, filter)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/project/handler"
+	m "todo_list_api/internal/project/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func TestCreateProject(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 401 if the request is not authenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(`{"name":"Launch"}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProject(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should return 422 if the name is blank", func(t *testing.T) {
+		mockService.On("CreateProject", mock.Anything, &models.Project{OwnerID: userID}).Return(utils.ErrEmptyProjectName).Once()
+
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(`{}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "empty_project_name", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 201 and ignore client supplied ownership", func(t *testing.T) {
+		mockService.On("CreateProject", mock.Anything, &models.Project{OwnerID: userID, Name: "Launch"}).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", "/projects", bytes.NewBufferString(`{"name":"Launch","owner_id":99}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestArchiveProject(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 404 if the project is not visible", func(t *testing.T) {
+		mockService.On("ArchiveProject", mock.Anything, userID, int64(4)).Return((*models.Project)(nil), utils.ErrProjectNotFound).Once()
+
+		req, _ := http.NewRequest("POST", "/projects/4/archive", nil)
+		req.SetPathValue("id", "4")
+		rr := httptest.NewRecorder()
+
+		handler.ArchiveProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "project_not_found", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestListProjectTasks(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the limit is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/projects/4/tasks?limit=abc", nil)
+		req.SetPathValue("id", "4")
+		rr := httptest.NewRecorder()
+
+		handler.ListProjectTasks(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should pass the query down and return the page", func(t *testing.T) {
+		projectID := int64(4)
+		overdue := true
+		filter := models.TaskFilter{Status: "Pending", Labels: []string{"work"}, LabelMatch: "all", ProjectID: &projectID, Overdue: &overdue, Sort: "title", Limit: 5}
+		mockService.On("ListProjectTasks", mock.Anything, userID, int64(4), filter).Return(&models.TaskPage{Tasks: []*models.Task{{ID: 1}}}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/projects/4/tasks?status=Pending&label=work&label_match=all&overdue=true&sort=title&limit=5", nil)
+		req.SetPathValue("id", "4")
+		rr := httptest.NewRecorder()
+
+		handler.ListProjectTasks(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var page models.TaskPage
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+		assert.Len(t, page.Tasks, 1)
+		mockService.AssertExpectations(t)
+	})
+}
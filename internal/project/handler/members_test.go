@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo_list_api/internal/project/handler"
+	m "todo_list_api/internal/project/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareProject(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 403 if the user does not own the project", func(t *testing.T) {
+		mockService.On("ShareProject", mock.Anything, userID, &models.ProjectMember{ProjectID: 4, UserID: 8, Role: models.RoleViewer}).Return(utils.ErrForbidden).Once()
+
+		req, _ := http.NewRequest("POST", "/projects/4/members", bytes.NewBufferString(`{"user_id":8,"role":"viewer"}`))
+		req.SetPathValue("id", "4")
+		rr := httptest.NewRecorder()
+
+		handler.ShareProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, "forbidden", decodeError(t, rr).Code)
+	})
+
+	t.Run("should return 201 with the project taken from the path", func(t *testing.T) {
+		mockService.On("ShareProject", mock.Anything, userID, &models.ProjectMember{ProjectID: 4, UserID: 8, Role: models.RoleEditor}).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", "/projects/4/members", bytes.NewBufferString(`{"project_id":9,"user_id":8,"role":"editor"}`))
+		req.SetPathValue("id", "4")
+		rr := httptest.NewRecorder()
+
+		handler.ShareProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestUnshareProject(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the member id is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/projects/4/members/abc", nil)
+		req.SetPathValue("id", "4")
+		req.SetPathValue("userID", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.UnshareProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 204 once the member is removed", func(t *testing.T) {
+		mockService.On("UnshareProject", mock.Anything, userID, int64(4), int64(8)).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", "/projects/4/members/8", nil)
+		req.SetPathValue("id", "4")
+		req.SetPathValue("userID", "8")
+		rr := httptest.NewRecorder()
+
+		handler.UnshareProject(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,78 @@
+package project
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// AddProjectMember implements Repository.
+func (m *MockRepository) AddProjectMember(ctx context.Context, member *models.ProjectMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+// ArchiveProject implements Repository.
+func (m *MockRepository) ArchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, actorID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// CreateProject implements Repository.
+func (m *MockRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+// DeleteProject implements Repository.
+func (m *MockRepository) DeleteProject(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
+	return args.Error(0)
+}
+
+// GetProject implements Repository.
+func (m *MockRepository) GetProject(ctx context.Context, id int64) (*models.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// GetProjectMemberRole implements Repository.
+func (m *MockRepository) GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error) {
+	args := m.Called(ctx, projectID, userID)
+	return args.String(0), args.Error(1)
+}
+
+// ListProjectMembers implements Repository.
+func (m *MockRepository) ListProjectMembers(ctx context.Context, projectID int64) ([]*models.ProjectMember, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]*models.ProjectMember), args.Error(1)
+}
+
+// ListProjects implements Repository.
+func (m *MockRepository) ListProjects(ctx context.Context, userID int64) ([]*models.Project, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Project), args.Error(1)
+}
+
+// RemoveProjectMember implements Repository.
+func (m *MockRepository) RemoveProjectMember(ctx context.Context, projectID, userID int64) error {
+	args := m.Called(ctx, projectID, userID)
+	return args.Error(0)
+}
+
+// UnarchiveProject implements Repository.
+func (m *MockRepository) UnarchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, actorID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// UpdateProject implements Repository.
+func (m *MockRepository) UpdateProject(ctx context.Context, project *models.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
@@ -0,0 +1,78 @@
+package project
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// ArchiveProject implements project.Service.
+func (m *MockService) ArchiveProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// CreateProject implements project.Service.
+func (m *MockService) CreateProject(ctx context.Context, project *models.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+// DeleteProject implements project.Service.
+func (m *MockService) DeleteProject(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+// GetProject implements project.Service.
+func (m *MockService) GetProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// ListProjectMembers implements project.Service.
+func (m *MockService) ListProjectMembers(ctx context.Context, userID, id int64) ([]*models.ProjectMember, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).([]*models.ProjectMember), args.Error(1)
+}
+
+// ListProjectTasks implements project.Service.
+func (m *MockService) ListProjectTasks(ctx context.Context, userID, id int64, filter models.TaskFilter) (*models.TaskPage, error) {
+	args := m.Called(ctx, userID, id, filter)
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
+// ListProjects implements project.Service.
+func (m *MockService) ListProjects(ctx context.Context, userID int64) ([]*models.Project, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Project), args.Error(1)
+}
+
+// ShareProject implements project.Service.
+func (m *MockService) ShareProject(ctx context.Context, userID int64, member *models.ProjectMember) error {
+	args := m.Called(ctx, userID, member)
+	return args.Error(0)
+}
+
+// UnarchiveProject implements project.Service.
+func (m *MockService) UnarchiveProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// UnshareProject implements project.Service.
+func (m *MockService) UnshareProject(ctx context.Context, userID, id, memberID int64) error {
+	args := m.Called(ctx, userID, id, memberID)
+	return args.Error(0)
+}
+
+// UpdateProject implements project.Service.
+func (m *MockService) UpdateProject(ctx context.Context, project *models.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
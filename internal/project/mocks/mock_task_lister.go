@@ -0,0 +1,18 @@
+package project
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskLister struct {
+	mock.Mock
+}
+
+// ListTasks implements TaskLister.
+func (m *MockTaskLister) ListTasks(ctx context.Context, userID int64, filter models.TaskFilter) (*models.TaskPage, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
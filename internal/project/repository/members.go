@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// GetProjectMemberRole returns the role a project is shared with a user
+// with, or an empty role when it is not shared with them.
+func (r *ProjectRepository) GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return MemberRole(ctx, r.db, projectID, userID)
+}
+
+// MemberRole is the query behind GetProjectMemberRole. It is shared with the
+// task repository, which checks the project a task is filed under.
+func MemberRole(ctx context.Context, conn *sql.DB, projectID, userID int64) (string, error) {
+	const query = "SELECT role FROM project_members WHERE project_id = $1 AND user_id = $2"
+	var role string
+	if err := conn.QueryRowContext(ctx, query, projectID, userID).Scan(&role); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *ProjectRepository) AddProjectMember(ctx context.Context, member *models.ProjectMember) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `INSERT INTO project_members (project_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING created_at`
+	member.CreatedAt = time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		member.ProjectID,
+		member.UserID,
+		member.Role,
+		member.CreatedAt,
+	).Scan(&member.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return utils.ErrUserNotFound
+	}
+
+	return err
+}
+
+func (r *ProjectRepository) RemoveProjectMember(ctx context.Context, projectID, userID int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM project_members WHERE project_id = $1 AND user_id = $2"
+	result, err := r.db.ExecContext(ctx, query, projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) ListProjectMembers(ctx context.Context, projectID int64) ([]*models.ProjectMember, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT project_id, user_id, role, created_at FROM project_members WHERE project_id = $1 ORDER BY created_at, user_id"
+	rows, err := r.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.ProjectMember{}
+	for rows.Next() {
+		var member models.ProjectMember
+		if err := rows.Scan(
+			&member.ProjectID,
+			&member.UserID,
+			&member.Role,
+			&member.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	return members, rows.Err()
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/project/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProjectMemberRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	const query = "SELECT role FROM project_members WHERE project_id = \\$1 AND user_id = \\$2"
+
+	t.Run("must return the role the project is shared with", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(4), int64(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleEditor))
+
+		role, err := repo.GetProjectMemberRole(context.Background(), 4, 8)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleEditor, role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return an empty role if the project is not shared with the user", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(4), int64(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+		role, err := repo.GetProjectMemberRole(context.Background(), 4, 8)
+		assert.NoError(t, err)
+		assert.Empty(t, role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAddProjectMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	const query = "INSERT INTO project_members \\(project_id, user_id, role, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT \\(project_id, user_id\\) DO UPDATE SET role = EXCLUDED.role RETURNING created_at"
+
+	t.Run("must insert or update the membership", func(t *testing.T) {
+		member := &models.ProjectMember{ProjectID: 4, UserID: 8, Role: models.RoleViewer}
+		createdAt := time.Now().Add(-time.Hour)
+
+		mock.ExpectQuery(query).
+			WithArgs(int64(4), int64(8), models.RoleViewer, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+		assert.NoError(t, repo.AddProjectMember(context.Background(), member))
+		assert.Equal(t, createdAt, member.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrUserNotFound if the user does not exist", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(4), int64(99), models.RoleViewer, sqlmock.AnyArg()).
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		err := repo.AddProjectMember(context.Background(), &models.ProjectMember{ProjectID: 4, UserID: 99, Role: models.RoleViewer})
+		assert.ErrorIs(t, err, utils.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	outbox "todo_list_api/internal/outbox/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Repository interface {
+	AddProjectMember(ctx context.Context, member *models.ProjectMember) error
+	ArchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error)
+	CreateProject(ctx context.Context, project *models.Project) error
+	DeleteProject(ctx context.Context, ownerID, id int64) error
+	GetProject(ctx context.Context, id int64) (*models.Project, error)
+	GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error)
+	ListProjectMembers(ctx context.Context, projectID int64) ([]*models.ProjectMember, error)
+	ListProjects(ctx context.Context, userID int64) ([]*models.Project, error)
+	RemoveProjectMember(ctx context.Context, projectID, userID int64) error
+	UnarchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error)
+	UpdateProject(ctx context.Context, project *models.Project) error
+}
+
+const projectColumns = "id, owner_id, name, description, archived_at, created_at, updated_at"
+
+type ProjectRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewProjectRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &ProjectRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProject(row scanner, project *models.Project) error {
+	return row.Scan(
+		&project.ID,
+		&project.OwnerID,
+		&project.Name,
+		&project.Description,
+		&project.ArchivedAt,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+}
+
+func (r *ProjectRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "INSERT INTO projects (owner_id, name, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		project.OwnerID,
+		project.Name,
+		project.Description,
+		project.CreatedAt,
+		project.UpdatedAt,
+	).Scan(&project.ID)
+}
+
+func (r *ProjectRepository) GetProject(ctx context.Context, id int64) (*models.Project, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + projectColumns + " FROM projects WHERE id = $1"
+	project := &models.Project{}
+	if err := scanProject(r.db.QueryRowContext(ctx, query, id), project); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// ListProjects returns the projects a user owns or that are shared with them.
+func (r *ProjectRepository) ListProjects(ctx context.Context, userID int64) ([]*models.Project, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + projectColumns + " FROM projects WHERE owner_id = $1 OR id IN (SELECT project_id FROM project_members WHERE user_id = $1) ORDER BY name ASC, id ASC"
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*models.Project{}
+	for rows.Next() {
+		project := &models.Project{}
+		if err := scanProject(rows, project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (r *ProjectRepository) UpdateProject(ctx context.Context, project *models.Project) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "UPDATE projects SET name = $3, description = $4, updated_at = $5 WHERE id = $1 AND owner_id = $2 RETURNING archived_at, created_at"
+	project.UpdatedAt = time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		project.ID,
+		project.OwnerID,
+		project.Name,
+		project.Description,
+		project.UpdatedAt,
+	).Scan(&project.ArchivedAt, &project.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrProjectNotFound
+	}
+
+	return err
+}
+
+// DeleteProject removes a project; its tasks survive and simply leave it.
+// The tasks archived together with an archived project are reactivated
+// first, as nothing could unarchive them once the project is gone.
+func (r *ProjectRepository) DeleteProject(ctx context.Context, ownerID, id int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	project, err := lockProject(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if project.OwnerID != ownerID {
+		return utils.ErrProjectNotFound
+	}
+
+	if project.ArchivedAt != nil {
+		if err := unarchiveTasks(ctx, tx, ownerID, project, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	const query = "DELETE FROM projects WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ArchiveProject archives a project together with every active task in it,
+// recording an archived event for each task. Archiving an archived project
+// is a no-op.
+func (r *ProjectRepository) ArchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	project, err := lockProject(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt != nil {
+		return project, nil
+	}
+
+	now := time.Now()
+	changes := map[string]models.FieldChange{"archived_at": {After: now}}
+
+	const cascade = `WITH archived AS (
+	UPDATE tasks SET archived_at = $2, updated_at = $2, version = version + 1
+	WHERE project_id = $1 AND archived_at IS NULL AND deleted_at IS NULL
+	RETURNING id
+), events AS (
+	INSERT INTO task_events (task_id, actor_id, action, changes, created_at)
+	SELECT id, $3, $4, $5, $2 FROM archived
+	RETURNING id, task_id, actor_id, action, changes, created_at
+)
+` + outbox.FromTaskEvents
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, cascade, id, now, actorID, models.EventArchived, string(payload)); err != nil {
+		return nil, err
+	}
+
+	const query = "UPDATE projects SET archived_at = $2, updated_at = $2 WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, query, id, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	project.ArchivedAt = &now
+	project.UpdatedAt = now
+	return project, nil
+}
+
+// UnarchiveProject reactivates a project and the tasks its archival swept
+// up.
+func (r *ProjectRepository) UnarchiveProject(ctx context.Context, actorID, id int64) (*models.Project, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	project, err := lockProject(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt == nil {
+		return project, nil
+	}
+
+	now := time.Now()
+	if err := unarchiveTasks(ctx, tx, actorID, project, now); err != nil {
+		return nil, err
+	}
+
+	const query = "UPDATE projects SET archived_at = NULL, updated_at = $2 WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, query, id, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	project.ArchivedAt = nil
+	project.UpdatedAt = now
+	return project, nil
+}
+
+// unarchiveTasks reactivates the tasks swept up by the archival of project,
+// recording an unarchived event for each task. Tasks are matched on the
+// project's archival timestamp, so tasks that were archived on their own
+// beforehand stay archived.
+func unarchiveTasks(ctx context.Context, tx *sql.Tx, actorID int64, project *models.Project, now time.Time) error {
+	changes := map[string]models.FieldChange{"archived_at": {Before: *project.ArchivedAt}}
+
+	// Trashed tasks are included so restoring them later does not bring
+	// them back archived.
+	const cascade = `WITH unarchived AS (
+	UPDATE tasks SET archived_at = NULL, updated_at = $2, version = version + 1
+	WHERE project_id = $1 AND archived_at = $6
+	RETURNING id
+), events AS (
+	INSERT INTO task_events (task_id, actor_id, action, changes, created_at)
+	SELECT id, $3, $4, $5, $2 FROM unarchived
+	RETURNING id, task_id, actor_id, action, changes, created_at
+)
+` + outbox.FromTaskEvents
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, cascade, project.ID, now, actorID, models.EventUnarchived, string(payload), *project.ArchivedAt)
+	return err
+}
+
+func lockProject(ctx context.Context, tx *sql.Tx, id int64) (*models.Project, error) {
+	const query = "SELECT " + projectColumns + " FROM projects WHERE id = $1 FOR UPDATE"
+	project := &models.Project{}
+	if err := scanProject(tx.QueryRowContext(ctx, query, id), project); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
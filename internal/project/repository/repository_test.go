@@ -0,0 +1,241 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/project/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []string{"id", "owner_id", "name", "description", "archived_at", "created_at", "updated_at"}
+
+func TestCreateProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	t.Run("must insert the project and return its id", func(t *testing.T) {
+		project := &models.Project{OwnerID: 7, Name: "Launch", Description: "Q3"}
+
+		mock.ExpectQuery("INSERT INTO projects \\(owner_id, name, description, created_at, updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING id").
+			WithArgs(int64(7), "Launch", "Q3", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+		assert.NoError(t, repo.CreateProject(context.Background(), project))
+		assert.Equal(t, int64(4), project.ID)
+		assert.Equal(t, project.CreatedAt, project.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	t.Run("should return nil if the project does not exist", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, owner_id, name, description, archived_at, created_at, updated_at FROM projects WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		project, err := repo.GetProject(context.Background(), 4)
+		assert.NoError(t, err)
+		assert.Nil(t, project)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	t.Run("should return ErrProjectNotFound if no owned project matched", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE projects SET name = \\$3, description = \\$4, updated_at = \\$5 WHERE id = \\$1 AND owner_id = \\$2 RETURNING archived_at, created_at").
+			WithArgs(int64(4), int64(8), "Launch", "", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"archived_at", "created_at"}))
+
+		err := repo.UpdateProject(context.Background(), &models.Project{ID: 4, OwnerID: 8, Name: "Launch"})
+		assert.ErrorIs(t, err, utils.ErrProjectNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	const lock = "SELECT .+ FROM projects WHERE id = \\$1 FOR UPDATE"
+	createdAt := time.Now().Add(-time.Hour)
+
+	t.Run("must delete an active project without touching its tasks", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", nil, createdAt, createdAt))
+		mock.ExpectExec("DELETE FROM projects WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.DeleteProject(context.Background(), 7, 4))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must reactivate the tasks archived with the project before deleting it", func(t *testing.T) {
+		archivedAt := time.Now().Add(-time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", archivedAt, createdAt, archivedAt))
+		mock.ExpectExec("WITH unarchived AS \\(.+UPDATE tasks SET archived_at = NULL, updated_at = \\$2, version = version \\+ 1 WHERE project_id = \\$1 AND archived_at = \\$6 RETURNING id.+\\)").
+			WithArgs(int64(4), sqlmock.AnyArg(), int64(7), models.EventUnarchived, sqlmock.AnyArg(), archivedAt).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM projects WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.DeleteProject(context.Background(), 7, 4))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrProjectNotFound if the project does not exist", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		err := repo.DeleteProject(context.Background(), 7, 4)
+		assert.ErrorIs(t, err, utils.ErrProjectNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrProjectNotFound if the user does not own the project", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 8, "Launch", "", nil, createdAt, createdAt))
+		mock.ExpectRollback()
+
+		err := repo.DeleteProject(context.Background(), 7, 4)
+		assert.ErrorIs(t, err, utils.ErrProjectNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestArchiveProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	const lock = "SELECT id, owner_id, name, description, archived_at, created_at, updated_at FROM projects WHERE id = \\$1 FOR UPDATE"
+	createdAt := time.Now().Add(-time.Hour)
+
+	t.Run("must archive the active tasks with an event each, then the project", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", nil, createdAt, createdAt))
+		mock.ExpectExec("WITH archived AS \\(.+UPDATE tasks SET archived_at = \\$2, updated_at = \\$2, version = version \\+ 1 WHERE project_id = \\$1 AND archived_at IS NULL AND deleted_at IS NULL RETURNING id.+\\), events AS \\(.+INSERT INTO task_events \\(task_id, actor_id, action, changes, created_at\\) SELECT id, \\$3, \\$4, \\$5, \\$2 FROM archived.+\\) INSERT INTO outbox .+ FROM events e").
+			WithArgs(int64(4), sqlmock.AnyArg(), int64(7), models.EventArchived, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("UPDATE projects SET archived_at = \\$2, updated_at = \\$2 WHERE id = \\$1").
+			WithArgs(int64(4), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		project, err := repo.ArchiveProject(context.Background(), 7, 4)
+		assert.NoError(t, err)
+		assert.NotNil(t, project.ArchivedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should leave an archived project untouched", func(t *testing.T) {
+		archivedAt := time.Now().Add(-time.Minute)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", archivedAt, createdAt, archivedAt))
+		mock.ExpectRollback()
+
+		project, err := repo.ArchiveProject(context.Background(), 7, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, archivedAt, *project.ArchivedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the cascade fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", nil, createdAt, createdAt))
+		mock.ExpectExec("WITH archived AS").WillReturnError(errors.New("cascade failed"))
+		mock.ExpectRollback()
+
+		_, err := repo.ArchiveProject(context.Background(), 7, 4)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnarchiveProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewProjectRepository(db, time.Second)
+
+	t.Run("must only reactivate the tasks archived together with the project", func(t *testing.T) {
+		createdAt := time.Now().Add(-time.Hour)
+		archivedAt := time.Now().Add(-time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT .+ FROM projects WHERE id = \\$1 FOR UPDATE").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, 7, "Launch", "", archivedAt, createdAt, archivedAt))
+		mock.ExpectExec("WITH unarchived AS \\(.+UPDATE tasks SET archived_at = NULL, updated_at = \\$2, version = version \\+ 1 WHERE project_id = \\$1 AND archived_at = \\$6 RETURNING id.+\\)").
+			WithArgs(int64(4), sqlmock.AnyArg(), int64(7), models.EventUnarchived, sqlmock.AnyArg(), archivedAt).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("UPDATE projects SET archived_at = NULL, updated_at = \\$2 WHERE id = \\$1").
+			WithArgs(int64(4), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		project, err := repo.UnarchiveProject(context.Background(), 7, 4)
+		assert.NoError(t, err)
+		assert.Nil(t, project.ArchivedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrProjectNotFound if the project does not exist", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT .+ FROM projects WHERE id = \\$1 FOR UPDATE").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		_, err := repo.UnarchiveProject(context.Background(), 7, 4)
+		assert.ErrorIs(t, err, utils.ErrProjectNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
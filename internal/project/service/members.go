@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// ShareProject gives a user a role on a project and on every task filed
+// under it. Only the owner may share a project, and only with viewers and
+// editors.
+func (s *ProjectService) ShareProject(ctx context.Context, userID int64, member *models.ProjectMember) error {
+	if member.ProjectID < 0 || member.UserID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	if member.Role == "" {
+		return utils.ErrEmptyRole
+	}
+
+	if member.Role != models.RoleViewer && member.Role != models.RoleEditor {
+		return utils.ErrInvalidRole
+	}
+
+	project, err := s.requireRole(ctx, userID, member.ProjectID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerID == member.UserID {
+		return utils.ErrCannotShareOwner
+	}
+
+	return s.repo.AddProjectMember(ctx, member)
+}
+
+func (s *ProjectService) UnshareProject(ctx context.Context, userID, id, memberID int64) error {
+	if id < 0 || memberID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	// Members may always leave a project; removing anyone else requires
+	// ownership.
+	if userID != memberID {
+		if _, err := s.requireRole(ctx, userID, id, models.RoleOwner); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveProjectMember(ctx, id, memberID)
+}
+
+func (s *ProjectService) ListProjectMembers(ctx context.Context, userID, id int64) ([]*models.ProjectMember, error) {
+	if _, err := s.requireRole(ctx, userID, id, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListProjectMembers(ctx, id)
+}
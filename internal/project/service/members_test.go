@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	m "todo_list_api/internal/project/mocks"
+	"todo_list_api/internal/project/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareProject(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewProjectService(mockRepo, new(m.MockTaskLister))
+	userID := int64(7)
+	projectID := int64(4)
+
+	t.Run("should only share projects with viewers and editors", func(t *testing.T) {
+		err := svc.ShareProject(context.Background(), userID, &models.ProjectMember{ProjectID: projectID, UserID: 8, Role: models.RoleOwner})
+		assert.ErrorIs(t, err, utils.ErrInvalidRole)
+	})
+
+	t.Run("should return ErrForbidden if an editor shares the project", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 9}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, projectID, userID).Return(models.RoleEditor, nil).Once()
+
+		err := svc.ShareProject(context.Background(), userID, &models.ProjectMember{ProjectID: projectID, UserID: 8, Role: models.RoleViewer})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "AddProjectMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if sharing with the project owner", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: userID}, nil).Once()
+
+		err := svc.ShareProject(context.Background(), userID, &models.ProjectMember{ProjectID: projectID, UserID: userID, Role: models.RoleViewer})
+		assert.ErrorIs(t, err, utils.ErrCannotShareOwner)
+	})
+
+	t.Run("must add the member if the user owns the project", func(t *testing.T) {
+		member := &models.ProjectMember{ProjectID: projectID, UserID: 8, Role: models.RoleEditor}
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: userID}, nil).Once()
+		mockRepo.On("AddProjectMember", mock.Anything, member).Return(nil).Once()
+
+		assert.NoError(t, svc.ShareProject(context.Background(), userID, member))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUnshareProject(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewProjectService(mockRepo, new(m.MockTaskLister))
+
+	t.Run("should let a member leave the project without ownership", func(t *testing.T) {
+		mockRepo.On("RemoveProjectMember", mock.Anything, int64(4), int64(8)).Return(nil).Once()
+
+		assert.NoError(t, svc.UnshareProject(context.Background(), 8, 4, 8))
+		mockRepo.AssertNotCalled(t, "GetProject", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return ErrForbidden if a member removes someone else", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, int64(4)).Return(&models.Project{ID: 4, OwnerID: 7}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, int64(4), int64(8)).Return(models.RoleViewer, nil).Once()
+
+		err := svc.UnshareProject(context.Background(), 8, 4, 9)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+	})
+}
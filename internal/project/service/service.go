@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"strings"
+	r "todo_list_api/internal/project/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Service interface {
+	ArchiveProject(ctx context.Context, userID, id int64) (*models.Project, error)
+	CreateProject(ctx context.Context, project *models.Project) error
+	DeleteProject(ctx context.Context, userID, id int64) error
+	GetProject(ctx context.Context, userID, id int64) (*models.Project, error)
+	ListProjectMembers(ctx context.Context, userID, id int64) ([]*models.ProjectMember, error)
+	ListProjectTasks(ctx context.Context, userID, id int64, filter models.TaskFilter) (*models.TaskPage, error)
+	ListProjects(ctx context.Context, userID int64) ([]*models.Project, error)
+	ShareProject(ctx context.Context, userID int64, member *models.ProjectMember) error
+	UnarchiveProject(ctx context.Context, userID, id int64) (*models.Project, error)
+	UnshareProject(ctx context.Context, userID, id, memberID int64) error
+	UpdateProject(ctx context.Context, project *models.Project) error
+}
+
+// TaskLister lists the tasks visible to a user; the task service satisfies it.
+type TaskLister interface {
+	ListTasks(ctx context.Context, userID int64, filter models.TaskFilter) (*models.TaskPage, error)
+}
+
+type ProjectService struct {
+	repo  r.Repository
+	tasks TaskLister
+}
+
+func NewProjectService(repo r.Repository, tasks TaskLister) Service {
+	return &ProjectService{repo: repo, tasks: tasks}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, project *models.Project) error {
+	if err := normalizeProject(project); err != nil {
+		return err
+	}
+
+	return s.repo.CreateProject(ctx, project)
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	return s.requireRole(ctx, userID, id, models.RoleViewer)
+}
+
+// requireRole loads a project on which the user holds at least the required
+// role. Users the project is not shared with see it as missing, so its id
+// does not leak.
+func (s *ProjectService) requireRole(ctx context.Context, userID, id int64, required string) (*models.Project, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if project == nil {
+		return nil, utils.ErrProjectNotFound
+	}
+
+	role := models.RoleOwner
+	if project.OwnerID != userID {
+		role, err = s.repo.GetProjectMemberRole(ctx, id, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if role == "" {
+		return nil, utils.ErrProjectNotFound
+	}
+
+	if !utils.HasRole(role, required) {
+		return nil, utils.ErrForbidden
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, userID int64) ([]*models.Project, error) {
+	projects, err := s.repo.ListProjects(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if projects == nil {
+		return []*models.Project{}, nil
+	}
+
+	return projects, nil
+}
+
+func (s *ProjectService) UpdateProject(ctx context.Context, project *models.Project) error {
+	if project.ID < 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := normalizeProject(project); err != nil {
+		return err
+	}
+
+	if _, err := s.requireRole(ctx, project.OwnerID, project.ID, models.RoleOwner); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateProject(ctx, project)
+}
+
+func (s *ProjectService) DeleteProject(ctx context.Context, userID, id int64) error {
+	if _, err := s.requireRole(ctx, userID, id, models.RoleOwner); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteProject(ctx, userID, id)
+}
+
+func (s *ProjectService) ArchiveProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	if _, err := s.requireRole(ctx, userID, id, models.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ArchiveProject(ctx, userID, id)
+}
+
+func (s *ProjectService) UnarchiveProject(ctx context.Context, userID, id int64) (*models.Project, error) {
+	if _, err := s.requireRole(ctx, userID, id, models.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UnarchiveProject(ctx, userID, id)
+}
+
+// ListProjectTasks pages through the tasks filed under a project. The tasks
+// of an archived project were archived with it, so those are listed instead
+// of the active ones.
+func (s *ProjectService) ListProjectTasks(ctx context.Context, userID, id int64, filter models.TaskFilter) (*models.TaskPage, error) {
+	project, err := s.GetProject(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.ProjectID = &project.ID
+	filter.Archived = project.ArchivedAt != nil
+	return s.tasks.ListTasks(ctx, userID, filter)
+}
+
+func normalizeProject(project *models.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return utils.ErrEmptyProjectName
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	m "todo_list_api/internal/project/mocks"
+	"todo_list_api/internal/project/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateProject(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewProjectService(mockRepo, new(m.MockTaskLister))
+
+	t.Run("should reject a blank name", func(t *testing.T) {
+		err := svc.CreateProject(context.Background(), &models.Project{OwnerID: 7, Name: " "})
+		assert.ErrorIs(t, err, utils.ErrEmptyProjectName)
+	})
+
+	t.Run("must trim the name before saving", func(t *testing.T) {
+		mockRepo.On("CreateProject", mock.Anything, &models.Project{OwnerID: 7, Name: "Launch"}).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateProject(context.Background(), &models.Project{OwnerID: 7, Name: " Launch "}))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestArchiveProject(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewProjectService(mockRepo, new(m.MockTaskLister))
+
+	t.Run("should hide projects not shared with the user", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, int64(4)).Return(&models.Project{ID: 4, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, int64(4), int64(7)).Return("", nil).Once()
+
+		_, err := svc.ArchiveProject(context.Background(), 7, 4)
+		assert.ErrorIs(t, err, utils.ErrProjectNotFound)
+		mockRepo.AssertNotCalled(t, "ArchiveProject", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return ErrForbidden to members of the project", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, int64(4)).Return(&models.Project{ID: 4, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, int64(4), int64(7)).Return(models.RoleEditor, nil).Once()
+
+		_, err := svc.ArchiveProject(context.Background(), 7, 4)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "ArchiveProject", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("must archive an owned project", func(t *testing.T) {
+		archivedAt := time.Now()
+		mockRepo.On("GetProject", mock.Anything, int64(4)).Return(&models.Project{ID: 4, OwnerID: 7}, nil).Once()
+		mockRepo.On("ArchiveProject", mock.Anything, int64(7), int64(4)).Return(&models.Project{ID: 4, OwnerID: 7, ArchivedAt: &archivedAt}, nil).Once()
+
+		project, err := svc.ArchiveProject(context.Background(), 7, 4)
+		assert.NoError(t, err)
+		assert.NotNil(t, project.ArchivedAt)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestListProjectTasks(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockTasks := new(m.MockTaskLister)
+	svc := service.NewProjectService(mockRepo, mockTasks)
+	projectID := int64(4)
+
+	t.Run("must scope the listing to the project's active tasks", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 7}, nil).Once()
+		mockTasks.On("ListTasks", mock.Anything, int64(7), models.TaskFilter{ProjectID: &projectID, Limit: 5}).Return(&models.TaskPage{Tasks: []*models.Task{}}, nil).Once()
+
+		_, err := svc.ListProjectTasks(context.Background(), 7, projectID, models.TaskFilter{Limit: 5})
+		assert.NoError(t, err)
+		mockTasks.AssertExpectations(t)
+	})
+
+	t.Run("should list the archived tasks of an archived project", func(t *testing.T) {
+		archivedAt := time.Now()
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 7, ArchivedAt: &archivedAt}, nil).Once()
+		mockTasks.On("ListTasks", mock.Anything, int64(7), models.TaskFilter{ProjectID: &projectID, Archived: true}).Return(&models.TaskPage{Tasks: []*models.Task{}}, nil).Once()
+
+		_, err := svc.ListProjectTasks(context.Background(), 7, projectID, models.TaskFilter{})
+		assert.NoError(t, err)
+		mockTasks.AssertExpectations(t)
+	})
+}
+
+func TestListSharedProjectTasks(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockTasks := new(m.MockTaskLister)
+	svc := service.NewProjectService(mockRepo, mockTasks)
+	projectID := int64(4)
+
+	t.Run("must list the tasks of a project shared with a viewer", func(t *testing.T) {
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, projectID, int64(7)).Return(models.RoleViewer, nil).Once()
+		mockTasks.On("ListTasks", mock.Anything, int64(7), models.TaskFilter{ProjectID: &projectID}).Return(&models.TaskPage{Tasks: []*models.Task{}}, nil).Once()
+
+		_, err := svc.ListProjectTasks(context.Background(), 7, projectID, models.TaskFilter{})
+		assert.NoError(t, err)
+		mockTasks.AssertExpectations(t)
+	})
+}
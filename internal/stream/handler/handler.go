@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"todo_list_api/internal/httputil"
+	s "todo_list_api/internal/stream/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+const (
+	// replayPage is how many missed events are loaded at a time when a
+	// client resumes.
+	replayPage = 100
+	// heartbeat is how often a comment is sent on an idle stream, so that
+	// proxies do not time it out.
+	heartbeat = 15 * time.Second
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// StreamTaskEvents pushes the history events of the tasks the caller can see
+// as Server-Sent Events, named after their action. An event's id is its
+// stream position, which follows commit order: a client that reconnects with
+// Last-Event-ID is first sent the events it missed. The stream ends when the client falls behind, and it is
+// expected to reconnect.
+func (h *Handler) StreamTaskEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	lastID, ok := lastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	// Subscribe before replaying, so that no event falls between the two.
+	subscription := h.service.Subscribe(userID)
+	defer h.service.Unsubscribe(subscription)
+
+	var page []*models.TaskEvent
+	if lastID > 0 {
+		var err error
+		page, err = h.service.Replay(r.Context(), userID, lastID, replayPage)
+		if err != nil {
+			utils.WriteError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Events committed while replaying are also received live; they are only
+	// sent once.
+	replayed := map[int64]bool{}
+	for len(page) > 0 {
+		for _, event := range page {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			replayed[event.ID] = true
+			lastID = event.Position
+		}
+		if len(page) < replayPage {
+			break
+		}
+
+		var err error
+		page, err = h.service.Replay(r.Context(), userID, lastID, replayPage)
+		if err != nil {
+			log.Printf("could not replay task events: %v", err)
+			return
+		}
+	}
+
+	controller := http.NewResponseController(w)
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if replayed[event.ID] {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event *models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Position, event.Action, data)
+	return err
+}
+
+func lastEventID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		return 0, true
+	}
+
+	ID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ID < 0 {
+		utils.WriteError(w, utils.ErrInvalidEventID)
+		return 0, false
+	}
+
+	return ID, true
+}
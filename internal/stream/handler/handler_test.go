@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/stream/handler"
+	m "todo_list_api/internal/stream/mocks"
+	"todo_list_api/internal/stream/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+// closedSubscription has already received events and been dropped, so the
+// stream ends once they are written.
+func closedSubscription(events ...*models.TaskEvent) *service.Subscription {
+	subscription := &service.Subscription{UserID: userID, Events: make(chan *models.TaskEvent, len(events))}
+	for _, event := range events {
+		subscription.Events <- event
+	}
+	close(subscription.Events)
+	return subscription
+}
+
+func TestStreamTaskEvents(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 401 without a user", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should return 400 if Last-Event-ID is not an event id", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "invalid_event_id", decodeError(t, rr).Code)
+	})
+
+	t.Run("must stream live events under their stream position", func(t *testing.T) {
+		subscription := closedSubscription(&models.TaskEvent{ID: 42, TaskID: 5, Action: models.EventCreated, Position: 40})
+		mockService.On("Subscribe", userID).Return(subscription).Once()
+		mockService.On("Unsubscribe", subscription).Return().Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		assert.Regexp(t, `^id: 40\nevent: created\ndata: \{"id":42,"task_id":5,.+\}\n\n$`, rr.Body.String())
+		mockService.AssertNotCalled(t, "Replay", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("must replay missed events before live ones, sending each once", func(t *testing.T) {
+		missed := make([]*models.TaskEvent, 100)
+		for i := range missed {
+			missed[i] = &models.TaskEvent{ID: int64(i + 11), TaskID: 5, Action: models.EventUpdated, Position: int64(i + 11)}
+		}
+		last := &models.TaskEvent{ID: 111, TaskID: 5, Action: models.EventDeleted, Position: 111}
+
+		subscription := closedSubscription(last, &models.TaskEvent{ID: 112, TaskID: 5, Action: models.EventRestored, Position: 112})
+		mockService.On("Subscribe", userID).Return(subscription).Once()
+		mockService.On("Unsubscribe", subscription).Return().Once()
+		mockService.On("Replay", mock.Anything, userID, int64(10), 100).Return(missed, nil).Once()
+		mockService.On("Replay", mock.Anything, userID, int64(110), 100).Return([]*models.TaskEvent{last}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Last-Event-ID", "10")
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, withUser(req))
+
+		body := rr.Body.String()
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, body, "id: 11\nevent: updated\n")
+		assert.Equal(t, 1, strings.Count(body, "id: 111\n"))
+		assert.Contains(t, body, "id: 112\nevent: restored\n")
+		assert.Less(t, strings.Index(body, "id: 110\n"), strings.Index(body, "id: 111\n"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("must resume after the stream position, not the id, of the last replayed event", func(t *testing.T) {
+		// Event 9 was written before event 10 but committed after it.
+		late := &models.TaskEvent{ID: 9, TaskID: 5, Action: models.EventUpdated, Position: 11}
+		subscription := closedSubscription()
+		mockService.On("Subscribe", userID).Return(subscription).Once()
+		mockService.On("Unsubscribe", subscription).Return().Once()
+		mockService.On("Replay", mock.Anything, userID, int64(10), 100).Return([]*models.TaskEvent{late}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Last-Event-ID", "10")
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, withUser(req))
+
+		assert.Regexp(t, `^id: 11\nevent: updated\ndata: \{"id":9,`, rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return the error if the replay fails before streaming", func(t *testing.T) {
+		subscription := closedSubscription()
+		mockService.On("Subscribe", userID).Return(subscription).Once()
+		mockService.On("Unsubscribe", subscription).Return().Once()
+		mockService.On("Replay", mock.Anything, userID, int64(10), 100).Return([]*models.TaskEvent(nil), utils.ErrInvalidEventID).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/events", nil)
+		req.Header.Set("Last-Event-ID", "10")
+		rr := httptest.NewRecorder()
+
+		handler.StreamTaskEvents(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,32 @@
+package stream
+
+import (
+	"context"
+	"todo_list_api/internal/stream/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// GetEvent implements Repository.
+func (m *MockRepository) GetEvent(ctx context.Context, id int64) (*repository.SharedEvent, error) {
+	args := m.Called(ctx, id)
+	shared, _ := args.Get(0).(*repository.SharedEvent)
+	return shared, args.Error(1)
+}
+
+// ListEventsSince implements Repository.
+func (m *MockRepository) ListEventsSince(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error) {
+	args := m.Called(ctx, userID, after, limit)
+	return args.Get(0).([]*models.TaskEvent), args.Error(1)
+}
+
+// Notify implements Repository.
+func (m *MockRepository) Notify(ctx context.Context, eventID int64) error {
+	args := m.Called(ctx, eventID)
+	return args.Error(0)
+}
@@ -0,0 +1,30 @@
+package stream
+
+import (
+	"context"
+	"todo_list_api/internal/stream/service"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// Replay implements stream.Service.
+func (m *MockService) Replay(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error) {
+	args := m.Called(ctx, userID, after, limit)
+	return args.Get(0).([]*models.TaskEvent), args.Error(1)
+}
+
+// Subscribe implements stream.Service.
+func (m *MockService) Subscribe(userID int64) *service.Subscription {
+	args := m.Called(userID)
+	return args.Get(0).(*service.Subscription)
+}
+
+// Unsubscribe implements stream.Service.
+func (m *MockService) Unsubscribe(subscription *service.Subscription) {
+	m.Called(subscription)
+}
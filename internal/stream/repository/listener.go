@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Resync is sent in place of an event id after the listening connection was
+// reestablished: any event notified while it was down has been missed.
+const Resync int64 = 0
+
+// listenerPing is how long the listener may sit idle before the connection
+// is checked, so a silently dropped one is noticed and reopened.
+const listenerPing = 90 * time.Second
+
+// Listen opens a dedicated connection that LISTENs on Channel and returns the
+// ids of the events notified by every replica, until ctx is done. The
+// connection is reopened whenever it drops.
+func Listen(ctx context.Context, dsn string) (<-chan int64, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("task event listener: %v", err)
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	ids := make(chan int64, 64)
+	go func() {
+		defer close(ids)
+		defer listener.Close()
+
+		for {
+			id := Resync
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(listenerPing):
+				go listener.Ping()
+				continue
+			case notification := <-listener.Notify:
+				if notification != nil {
+					parsed, err := strconv.ParseInt(notification.Extra, 10, 64)
+					if err != nil {
+						log.Printf("ignoring task event notification %q", notification.Extra)
+						continue
+					}
+					id = parsed
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ids <- id:
+			}
+		}
+	}()
+
+	return ids, nil
+}
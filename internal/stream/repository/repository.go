@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the PostgreSQL notification channel task event ids are sent
+// on.
+const Channel = "task_events"
+
+// positionLockKey is the advisory lock that serializes the assignment of
+// stream positions, so that they are committed in the order they are taken.
+const positionLockKey = 72707372
+
+type Repository interface {
+	Notify(ctx context.Context, eventID int64) error
+	GetEvent(ctx context.Context, id int64) (*SharedEvent, error)
+	ListEventsSince(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error)
+}
+
+// SharedEvent is a task event along with the users who can see its task:
+// the owner, the members, and those of its project.
+type SharedEvent struct {
+	Event   *models.TaskEvent
+	UserIDs []int64
+}
+
+const eventColumns = "e.id, e.task_id, e.actor_id, e.action, e.changes, e.created_at, COALESCE(e.stream_position, 0)"
+
+type StreamRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewStreamRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &StreamRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row scanner, event *models.TaskEvent, extra ...any) error {
+	var changes []byte
+	dest := append([]any{&event.ID, &event.TaskID, &event.ActorID, &event.Action, &changes, &event.CreatedAt, &event.Position}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	return json.Unmarshal(changes, &event.Changes)
+}
+
+// Notify gives an event its stream position and tells every replica
+// listening on Channel about it. Ids are taken when events are written and
+// may commit out of order, which a client resuming after the last id it saw
+// would skip over; positions are taken under a lock held until the
+// notification is committed, so they only ever grow. An event keeps its
+// position when it is notified again. Only the id is sent, since
+// notifications are limited to 8000 bytes.
+func (r *StreamRepository) Notify(ctx context.Context, eventID int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", positionLockKey); err != nil {
+		return err
+	}
+
+	const position = "UPDATE task_events SET stream_position = COALESCE(stream_position, nextval('task_event_stream_positions')) WHERE id = $1"
+	result, err := tx.ExecContext(ctx, position, eventID)
+	if err != nil {
+		return err
+	}
+
+	// The event is gone once its task is purged from the trash.
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", Channel, strconv.FormatInt(eventID, 10)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetEvent returns a nil event when it no longer exists, which happens once
+// its task is purged from the trash.
+func (r *StreamRepository) GetEvent(ctx context.Context, id int64) (*SharedEvent, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + eventColumns + `,
+		ARRAY(SELECT DISTINCT a.user_id FROM task_access a WHERE a.task_id = e.task_id)
+		FROM task_events e WHERE e.id = $1`
+	shared := &SharedEvent{Event: &models.TaskEvent{}}
+	if err := scanEvent(r.db.QueryRowContext(ctx, query, id), shared.Event, pq.Array(&shared.UserIDs)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return shared, nil
+}
+
+// ListEventsSince returns up to limit events after the stream position
+// after, in stream order, on the tasks userID can currently see. Events not
+// relayed yet have no position and are left to the live stream.
+func (r *StreamRepository) ListEventsSince(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + eventColumns + ` FROM task_events e JOIN tasks t ON t.id = e.task_id
+		WHERE e.stream_position > $2 AND t.id IN (SELECT task_id FROM task_access WHERE user_id = $1)
+		ORDER BY e.stream_position LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.TaskEvent{}
+	for rows.Next() {
+		var event models.TaskEvent
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
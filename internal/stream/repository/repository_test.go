@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/stream/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var eventColumns = []string{"id", "task_id", "actor_id", "action", "changes", "created_at", "stream_position"}
+
+func TestNotify(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewStreamRepository(db, time.Second)
+	const position = "UPDATE task_events SET stream_position = COALESCE\\(stream_position, nextval\\('task_event_stream_positions'\\)\\) WHERE id = \\$1"
+
+	t.Run("must take a stream position under the lock and notify the event id in the same transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+			WithArgs(72707372).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(position).
+			WithArgs(int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SELECT pg_notify\\(\\$1, \\$2\\)").
+			WithArgs(repository.Channel, "42").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.Notify(context.Background(), 42)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should skip the notification once the event is purged", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+			WithArgs(72707372).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(position).
+			WithArgs(int64(43)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.Notify(context.Background(), 43)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewStreamRepository(db, time.Second)
+	const query = "SELECT e.id, .+, ARRAY\\(SELECT DISTINCT a.user_id FROM task_access a WHERE a.task_id = e.task_id\\) FROM task_events e WHERE e.id = \\$1"
+
+	t.Run("must return the event with the users who can see its task", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(42)).
+			WillReturnRows(sqlmock.NewRows(append(eventColumns, "user_ids")).
+				AddRow(42, 5, 7, models.EventUpdated, []byte(`{"title":{"before":"Draft","after":"Ship"}}`), time.Now(), 40, "{7,9}"))
+
+		shared, err := repo.GetEvent(context.Background(), 42)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), shared.Event.TaskID)
+		assert.Equal(t, "Ship", shared.Event.Changes["title"].After)
+		assert.Equal(t, []int64{7, 9}, shared.UserIDs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return nil once the task is purged", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(43)).
+			WillReturnRows(sqlmock.NewRows(append(eventColumns, "user_ids")))
+
+		shared, err := repo.GetEvent(context.Background(), 43)
+		assert.NoError(t, err)
+		assert.Nil(t, shared)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListEventsSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewStreamRepository(db, time.Second)
+
+	t.Run("must list the events after the stream position on tasks the user can see", func(t *testing.T) {
+		mock.ExpectQuery("SELECT e.id, .+ FROM task_events e JOIN tasks t ON t.id = e.task_id WHERE e.stream_position > \\$2 AND t.id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$1\\) ORDER BY e.stream_position LIMIT \\$3").
+			WithArgs(int64(7), int64(40), 100).
+			WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(42, 5, 7, models.EventCreated, []byte(`{}`), time.Now(), 41).
+				AddRow(39, 5, nil, models.EventArchived, []byte(`{}`), time.Now(), 42))
+
+		events, err := repo.ListEventsSince(context.Background(), 7, 40, 100)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, int64(42), events[0].ID)
+		assert.Equal(t, int64(42), events[1].Position)
+		assert.Nil(t, events[1].ActorID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
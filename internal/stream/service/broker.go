@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	r "todo_list_api/internal/stream/repository"
+	"todo_list_api/pkg/models"
+)
+
+type Service interface {
+	Replay(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error)
+	Subscribe(userID int64) *Subscription
+	Unsubscribe(subscription *Subscription)
+}
+
+// subscriptionBuffer is how many events a subscriber may fall behind by
+// before it is dropped.
+const subscriptionBuffer = 64
+
+// Subscription receives the live events of the tasks its user can see.
+// Events is closed when the subscriber falls behind or when events may have
+// been missed; the client is expected to reconnect and resume from the last
+// event it received.
+type Subscription struct {
+	UserID int64
+	Events chan *models.TaskEvent
+}
+
+// Broker fans the task events notified on this replica out to its
+// subscribers. Each event is loaded once, however many subscribers are
+// waiting for it.
+type Broker struct {
+	repo          r.Repository
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewBroker(repo r.Repository) *Broker {
+	return &Broker{repo: repo, subscriptions: map[*Subscription]struct{}{}}
+}
+
+// Replay returns up to limit events after the stream position after on the
+// tasks userID can see, in stream order.
+func (b *Broker) Replay(ctx context.Context, userID, after int64, limit int) ([]*models.TaskEvent, error) {
+	return b.repo.ListEventsSince(ctx, userID, after, limit)
+}
+
+func (b *Broker) Subscribe(userID int64) *Subscription {
+	subscription := &Subscription{UserID: userID, Events: make(chan *models.TaskEvent, subscriptionBuffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe closes subscription, unless the broker already dropped it.
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(subscription)
+}
+
+// drop must be called with mu held.
+func (b *Broker) drop(subscription *Subscription) {
+	if _, ok := b.subscriptions[subscription]; ok {
+		delete(b.subscriptions, subscription)
+		close(subscription.Events)
+	}
+}
+
+func (b *Broker) dropAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscriptions {
+		b.drop(subscription)
+	}
+}
+
+// Broadcast hands the event with the given id to the subscribers who can
+// see its task. A subscriber whose buffer is full is dropped rather than
+// holding up the others.
+func (b *Broker) Broadcast(ctx context.Context, id int64) error {
+	shared, err := b.repo.GetEvent(ctx, id)
+	if err != nil || shared == nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscriptions {
+		if !slices.Contains(shared.UserIDs, subscription.UserID) {
+			continue
+		}
+
+		select {
+		case subscription.Events <- shared.Event:
+		default:
+			b.drop(subscription)
+		}
+	}
+	return nil
+}
+
+// Run broadcasts the ids received on ids until it is closed or ctx is done.
+// Whenever events may have been missed, because the listener reconnected or
+// an event could not be loaded, every subscriber is dropped so that it
+// resumes from the last event it received.
+func (b *Broker) Run(ctx context.Context, ids <-chan int64) {
+	defer b.dropAll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id, ok := <-ids:
+			if !ok {
+				return
+			}
+
+			if id == r.Resync {
+				b.dropAll()
+				continue
+			}
+
+			if err := b.Broadcast(ctx, id); err != nil {
+				log.Printf("could not broadcast task event %d: %v", id, err)
+				b.dropAll()
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	m "todo_list_api/internal/stream/mocks"
+	"todo_list_api/internal/stream/repository"
+	"todo_list_api/internal/stream/service"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBroadcast(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	broker := service.NewBroker(mockRepo)
+
+	event := &models.TaskEvent{ID: 42, TaskID: 5, Action: models.EventUpdated}
+
+	t.Run("must only send the event to users who can see the task", func(t *testing.T) {
+		owner, member, stranger := broker.Subscribe(7), broker.Subscribe(9), broker.Subscribe(11)
+		defer broker.Unsubscribe(owner)
+		defer broker.Unsubscribe(member)
+		defer broker.Unsubscribe(stranger)
+
+		mockRepo.On("GetEvent", mock.Anything, int64(42)).Return(&repository.SharedEvent{Event: event, UserIDs: []int64{7, 9}}, nil).Once()
+
+		err := broker.Broadcast(context.Background(), 42)
+		assert.NoError(t, err)
+		assert.Equal(t, event, <-owner.Events)
+		assert.Equal(t, event, <-member.Events)
+		assert.Empty(t, stranger.Events)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should drop a subscriber that falls behind", func(t *testing.T) {
+		slow := broker.Subscribe(7)
+		defer broker.Unsubscribe(slow)
+
+		mockRepo.On("GetEvent", mock.Anything, int64(42)).Return(&repository.SharedEvent{Event: event, UserIDs: []int64{7}}, nil).Times(cap(slow.Events) + 1)
+
+		for range cap(slow.Events) + 1 {
+			assert.NoError(t, broker.Broadcast(context.Background(), 42))
+		}
+
+		received := 0
+		for range slow.Events {
+			received++
+		}
+		assert.Equal(t, cap(slow.Events), received)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should skip events whose task was purged", func(t *testing.T) {
+		subscription := broker.Subscribe(7)
+		defer broker.Unsubscribe(subscription)
+
+		mockRepo.On("GetEvent", mock.Anything, int64(43)).Return(nil, nil).Once()
+
+		err := broker.Broadcast(context.Background(), 43)
+		assert.NoError(t, err)
+		assert.Empty(t, subscription.Events)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRun(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	broker := service.NewBroker(mockRepo)
+
+	t.Run("should drop every subscriber when events may have been missed", func(t *testing.T) {
+		for _, cause := range []string{"resync", "load error"} {
+			subscription := broker.Subscribe(7)
+			ids := make(chan int64, 1)
+			if cause == "resync" {
+				ids <- repository.Resync
+			} else {
+				mockRepo.On("GetEvent", mock.Anything, int64(42)).Return(nil, errors.New("connection reset")).Once()
+				ids <- 42
+			}
+
+			done := make(chan struct{})
+			go func() {
+				broker.Run(context.Background(), ids)
+				close(done)
+			}()
+
+			_, open := <-subscription.Events
+			assert.False(t, open, cause)
+			close(ids)
+			<-done
+		}
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPublisher(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	publisher := service.NewPublisher(mockRepo)
+
+	t.Run("must notify the id of the task event", func(t *testing.T) {
+		mockRepo.On("Notify", mock.Anything, int64(11)).Return(nil).Once()
+
+		err := publisher.Publish(context.Background(), &models.OutboxEvent{ID: 3, IdempotencyKey: "task-event-11", Payload: []byte(`{"id":11,"task_id":5}`)})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
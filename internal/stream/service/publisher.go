@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	r "todo_list_api/internal/stream/repository"
+	"todo_list_api/pkg/models"
+)
+
+// Publisher notifies the replicas' brokers of the task events relayed from
+// the outbox. An event notified twice reaches subscribers twice, with the
+// same id.
+type Publisher struct {
+	repo r.Repository
+}
+
+func NewPublisher(repo r.Repository) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// Publish implements the outbox EventPublisher.
+func (p *Publisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	var taskEvent models.TaskEvent
+	if err := json.Unmarshal(event.Payload, &taskEvent); err != nil {
+		return err
+	}
+	return p.repo.Notify(ctx, taskEvent.ID)
+}
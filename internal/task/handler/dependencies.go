@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+func (h *Handler) AddTaskDependency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var dependency models.TaskDependency
+	if err := json.NewDecoder(r.Body).Decode(&dependency); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	dependency.TaskID = ID
+
+	if err := h.service.AddTaskDependency(r.Context(), userID, &dependency); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, &dependency)
+}
+
+func (h *Handler) RemoveTaskDependency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	blockerID, ok := httputil.PathID(w, r, "blockerID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveTaskDependency(r.Context(), userID, ID, blockerID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	blockers, err := h.service.ListTaskDependencies(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, blockers)
+}
@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddTaskDependency(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	newRequest := func(body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/tasks/1/dependencies", bytes.NewBufferString(body))
+		req.SetPathValue("id", "1")
+		return withUser(req)
+	}
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.AddTaskDependency(rr, newRequest("invalid json"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 422 if the dependency would form a cycle", func(t *testing.T) {
+		mockService.On("AddTaskDependency", mock.Anything, userID, &models.TaskDependency{TaskID: 1, BlockedByID: 2}).Return(utils.ErrDependencyCycle).Once()
+
+		rr := httptest.NewRecorder()
+		handler.AddTaskDependency(rr, newRequest(`{"blocked_by_id":2}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "dependency_cycle", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 201 and the dependency", func(t *testing.T) {
+		mockService.On("AddTaskDependency", mock.Anything, userID, &models.TaskDependency{TaskID: 1, BlockedByID: 2}).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.AddTaskDependency(rr, newRequest(`{"blocked_by_id":2}`))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var body models.TaskDependency
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.Equal(t, int64(1), body.TaskID)
+		assert.Equal(t, int64(2), body.BlockedByID)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRemoveTaskDependency(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 404 if the dependency does not exist", func(t *testing.T) {
+		mockService.On("RemoveTaskDependency", mock.Anything, userID, int64(1), int64(2)).Return(utils.ErrDependencyNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", "/tasks/1/dependencies/2", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("blockerID", "2")
+		rr := httptest.NewRecorder()
+		handler.RemoveTaskDependency(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "dependency_not_found", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 204 once the dependency is removed", func(t *testing.T) {
+		mockService.On("RemoveTaskDependency", mock.Anything, userID, int64(1), int64(2)).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", "/tasks/1/dependencies/2", nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("blockerID", "2")
+		rr := httptest.NewRecorder()
+		handler.RemoveTaskDependency(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCompleteBlockedTaskResponse(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 409 with the open blockers", func(t *testing.T) {
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), mock.Anything).Return((*models.Task)(nil), &utils.BlockedError{Blockers: []int64{2}}).Once()
+
+		req, _ := http.NewRequest("PATCH", "/tasks/1", bytes.NewBufferString(`{"status":"Completed"}`))
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, withUser(req))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		response := decodeError(t, rr)
+		assert.Equal(t, "task_blocked", response.Code)
+		assert.Equal(t, map[string]any{"blockers": []any{float64(2)}}, response.Details)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// overdueSuffix marks the ETag of an overdue task. The overdue flag flips
+// when the due date passes without any write, so the version alone cannot
+// tell the two representations apart.
+const overdueSuffix = "-overdue"
+
+// taskETag is a strong validator derived from the task version, which the
+// repository bumps on every write to the task and to its subtasks, and from
+// the time-dependent overdue flag.
+func taskETag(task *models.Task) string {
+	if task.Overdue {
+		return fmt.Sprintf(`"%d%s"`, task.Version, overdueSuffix)
+	}
+	return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// pageETag is a weak validator over the ids, versions and overdue flags of
+// a page, so it changes whenever any listed task is written, added, removed
+// or falls overdue.
+func pageETag(page *models.TaskPage) string {
+	hash := sha256.New()
+	for _, task := range page.Tasks {
+		fmt.Fprintf(hash, "%d:%d:%t;", task.ID, task.Version, task.Overdue)
+	}
+	hash.Write([]byte(page.NextCursor))
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// ifMatchVersion returns the task version required by the If-Match header,
+// or 0 when the header is absent or "*". The overdue suffix is dropped, as a
+// task falling overdue is not a write the client could have lost. A tag that
+// can never match a task version fails the precondition outright.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, utils.ErrPreconditionFailed
+	}
+
+	tag := strings.TrimSuffix(header[1:len(header)-1], overdueSuffix)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, utils.ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+// notModified reports whether the If-None-Match header matches etag, using
+// the weak comparison RFC 9110 prescribes for GET.
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskETags(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+	task := &models.Task{ID: 1, Title: "Task", Status: "Pending", Version: 3}
+
+	newRequest := func(method, body string) *http.Request {
+		req, _ := http.NewRequest(method, "/tasks/1", bytes.NewBufferString(body))
+		req.SetPathValue("id", "1")
+		return withUser(req)
+	}
+
+	t.Run("should emit the task version as a strong ETag", func(t *testing.T) {
+		mockService.On("GetTask", mock.Anything, userID, int64(1)).Return(task, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.GetTask(rr, newRequest("GET", ""))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 304 if If-None-Match matches the current ETag", func(t *testing.T) {
+		mockService.On("GetTask", mock.Anything, userID, int64(1)).Return(task, nil).Once()
+
+		req := newRequest("GET", "")
+		req.Header.Set("If-None-Match", `"2", W/"3"`)
+		rr := httptest.NewRecorder()
+		handler.GetTask(rr, req)
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should change the ETag once the task falls overdue", func(t *testing.T) {
+		overdue := *task
+		overdue.Overdue = true
+		mockService.On("GetTask", mock.Anything, userID, int64(1)).Return(&overdue, nil).Once()
+
+		req := newRequest("GET", "")
+		req.Header.Set("If-None-Match", `"3"`)
+		rr := httptest.NewRecorder()
+		handler.GetTask(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3-overdue"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 304 for an unchanged task list", func(t *testing.T) {
+		page := &models.TaskPage{Tasks: []*models.Task{task}}
+		mockService.On("ListTasks", mock.Anything, userID, mock.Anything).Return(page, nil).Twice()
+
+		req, _ := http.NewRequest("GET", "/tasks", nil)
+		rr := httptest.NewRecorder()
+		handler.ListTasks(rr, withUser(req))
+		etag := rr.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		req, _ = http.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		handler.ListTasks(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should pass the If-Match version to a delete", func(t *testing.T) {
+		mockService.On("DeleteTask", mock.Anything, userID, int64(1), int64(3)).Return(utils.ErrPreconditionFailed).Once()
+
+		req := newRequest("DELETE", "")
+		req.Header.Set("If-Match", `"3"`)
+		rr := httptest.NewRecorder()
+		handler.DeleteTask(rr, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		assert.Equal(t, "precondition_failed", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should read the version out of an overdue If-Match", func(t *testing.T) {
+		mockService.On("DeleteTask", mock.Anything, userID, int64(1), int64(3)).Return(nil).Once()
+
+		req := newRequest("DELETE", "")
+		req.Header.Set("If-Match", `"3-overdue"`)
+		rr := httptest.NewRecorder()
+		handler.DeleteTask(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should send the If-Match version with a full update and return the new ETag", func(t *testing.T) {
+		mockService.On("UpdateTask", mock.Anything, userID, mock.MatchedBy(func(task *models.Task) bool {
+			return task.Version == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(2).(*models.Task).Version = 4
+		}).Return(nil).Once()
+
+		body, _ := json.Marshal(models.Task{Title: "Task", Status: "Pending", Version: 1})
+		req := newRequest("PUT", string(body))
+		req.Header.Set("If-Match", `"3"`)
+		rr := httptest.NewRecorder()
+		handler.UpdateTask(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should fail the precondition for a weak or malformed If-Match", func(t *testing.T) {
+		for _, header := range []string{`W/"3"`, `3`, `"abc"`, `"3-late"`} {
+			req := newRequest("PATCH", `{"status":"Completed"}`)
+			req.Header.Set("If-Match", header)
+			rr := httptest.NewRecorder()
+			handler.PatchTask(rr, req)
+
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code, header)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
+	"todo_list_api/internal/httputil"
 	s "todo_list_api/internal/task/service"
 	"todo_list_api/pkg/models"
 	"todo_list_api/pkg/utils"
@@ -18,14 +20,19 @@ func NewHandler(service s.Service) *Handler {
 }
 
 func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
 	var task models.Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		http.Error(w, utils.ErrInvalidPayload.Error(), http.StatusBadRequest)
+		utils.WriteError(w, utils.ErrInvalidPayload)
 		return
 	}
 
-	if err := h.service.CreateTask(&task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.CreateTask(r.Context(), userID, &task); err != nil {
+		utils.WriteError(w, err)
 		return
 	}
 
@@ -33,28 +40,28 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		Message: "Tarefa criada com sucesso",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		http.Error(w, utils.ErrFailedEncode.Error(), http.StatusInternalServerError)
-	}
+	httputil.WriteJSON(w, http.StatusCreated, &response)
 }
 
 func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	if idStr == "" {
-		http.Error(w, utils.ErrEmptyID.Error(), http.StatusBadRequest)
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
 		return
 	}
 
-	ID, err := strconv.Atoi(idStr)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, utils.ErrInvalidId.Error(), http.StatusBadRequest)
+		utils.WriteError(w, err)
 		return
 	}
 
-	if err := h.service.DeleteTask(int64(ID)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.DeleteTask(r.Context(), userID, ID, version); err != nil {
+		utils.WriteError(w, err)
 		return
 	}
 
@@ -62,90 +69,199 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		Message: "Tarefa excluida com sucesso",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusNoContent)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		http.Error(w, utils.ErrFailedEncode.Error(), http.StatusInternalServerError)
-	}
+	httputil.WriteJSON(w, http.StatusNoContent, &response)
 }
 
 func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	if idStr == "" {
-		http.Error(w, utils.ErrEmptyID.Error(), http.StatusBadRequest)
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
 		return
 	}
 
-	ID, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, utils.ErrInvalidId.Error(), http.StatusBadRequest)
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
 		return
 	}
 
-	task, err := h.service.GetTask(int64(ID))
+	task, err := h.service.GetTask(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
 	if task == nil {
-		http.Error(w, utils.ErrTaskNotFound.Error(), http.StatusNotFound)
+		utils.WriteError(w, utils.ErrTaskNotFound)
 		return
 	}
+
+	etag := taskETag(task)
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, task)
+}
+
+func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := ParseTaskFilter(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	page, err := h.service.ListTasks(r.Context(), userID, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, utils.ErrFailedEncode.Error(), http.StatusInternalServerError)
+	etag := pageETag(page)
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
+
+	httputil.WriteJSON(w, http.StatusOK, page)
 }
 
-func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.ListTasks()
+func (h *Handler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			utils.WriteError(w, utils.ErrInvalidLimit)
+			return
+		}
+	}
+
+	results, err := h.service.SearchTasks(r.Context(), userID, query.Get("q"), limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	httputil.WriteJSON(w, http.StatusOK, results)
+}
+
+// ParseTaskFilter reads the filter, sort and pagination parameters of a task
+// listing from the query string. Other handlers listing tasks, such as the
+// project's, use it too so that every listing accepts the same parameters.
+func ParseTaskFilter(r *http.Request) (models.TaskFilter, error) {
+	query := r.URL.Query()
+	filter := models.TaskFilter{
+		Status:     query.Get("status"),
+		Priority:   query.Get("priority"),
+		Labels:     query["label"],
+		LabelMatch: query.Get("label_match"),
+		Sort:       query.Get("sort"),
+		Order:      query.Get("order"),
+		Cursor:     query.Get("cursor"),
+	}
 
-	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		http.Error(w, utils.ErrFailedEncode.Error(), http.StatusInternalServerError)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return filter, utils.ErrInvalidLimit
+		}
+		filter.Limit = limit
 	}
+
+	if projectStr := query.Get("project_id"); projectStr != "" {
+		projectID, err := strconv.ParseInt(projectStr, 10, 64)
+		if err != nil || projectID <= 0 {
+			return filter, utils.ErrInvalidFilter
+		}
+		filter.ProjectID = &projectID
+	}
+
+	if archivedStr := query.Get("archived"); archivedStr != "" {
+		archived, err := strconv.ParseBool(archivedStr)
+		if err != nil {
+			return filter, utils.ErrInvalidFilter
+		}
+		filter.Archived = archived
+	}
+
+	if overdueStr := query.Get("overdue"); overdueStr != "" {
+		overdue, err := strconv.ParseBool(overdueStr)
+		if err != nil {
+			return filter, utils.ErrInvalidFilter
+		}
+		filter.Overdue = &overdue
+	}
+
+	dates := map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+		"due_after":      &filter.DueAfter,
+		"due_before":     &filter.DueBefore,
+	}
+	for param, target := range dates {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, utils.ErrInvalidDate
+		}
+		*target = &date
+	}
+
+	return filter, nil
 }
 
 func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	if idStr == "" {
-		http.Error(w, utils.ErrEmptyID.Error(), http.StatusBadRequest)
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
 		return
 	}
 
-	ID, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, utils.ErrInvalidId.Error(), http.StatusBadRequest)
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
 		return
 	}
 
 	var task models.Task
-	task.ID = int64(ID)
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		http.Error(w, utils.ErrInvalidPayload.Error(), http.StatusBadRequest)
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	task.ID = ID
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, err)
 		return
 	}
+	task.Version = version
 
-	if err := h.service.UpdateTask(&task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.UpdateTask(r.Context(), userID, &task); err != nil {
+		utils.WriteError(w, err)
 		return
 	}
+	w.Header().Set("ETag", taskETag(&task))
 
 	response := models.Response{
 		Message: "Tarefa atualizada com sucesso",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		http.Error(w, utils.ErrFailedEncode.Error(), http.StatusInternalServerError)
-	}
+	httputil.WriteJSON(w, http.StatusOK, &response)
 }
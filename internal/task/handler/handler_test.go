@@ -2,42 +2,70 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
+	"todo_list_api/internal/auth"
 	"todo_list_api/internal/task/handler"
 	m "todo_list_api/internal/task/mocks"
 	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
 func TestCreateTask(t *testing.T) {
 	mockService := new(m.MockService)
 	handler := handler.NewHandler(mockService)
 
+	t.Run("should return 401 if the request is not authenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer([]byte("{}")))
+
+		rr := httptest.NewRecorder()
+		handler.CreateTask(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "unauthorized", decodeError(t, rr).Code)
+	})
+
 	t.Run("should make the request and return a bad request error when creating the task", func(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBuffer([]byte("invalid json")))
+		req = withUser(req)
 
 		rr := httptest.NewRecorder()
 		handler.CreateTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "invalid request payload\n", rr.Body.String())
+		assert.Equal(t, "invalid_payload", decodeError(t, rr).Code)
 	})
 
 	t.Run("should make the request and return a internal server error when creating the task", func(t *testing.T) {
 		task := &models.Task{}
 
-		mockService.On("CreateTask", task).Return(errors.New("status internal server error"))
+		mockService.On("CreateTask", mock.Anything, userID, task).Return(errors.New("status internal server error"))
 
 		body, _ := json.Marshal(task)
 		req, err := http.NewRequest("POST", "/tasks", bytes.NewBuffer(body))
+		req = withUser(req)
 		assert.NoError(t, err)
 
 		rr := httptest.NewRecorder()
@@ -47,6 +75,29 @@ func TestCreateTask(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("should return 422 if the title is empty", func(t *testing.T) {
+		task := &models.Task{Status: "Pending"}
+
+		mockService.On("CreateTask", mock.Anything, userID, task).Return(utils.ErrEmptyTitle).Once()
+
+		body, _ := json.Marshal(task)
+		req, err := http.NewRequest("POST", "/tasks", bytes.NewBuffer(body))
+		req = withUser(req)
+		assert.NoError(t, err)
+		req.Header.Set("X-Request-ID", "req-123")
+
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-ID", "req-123")
+		handler.CreateTask(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		response := decodeError(t, rr)
+		assert.Equal(t, "empty_title", response.Code)
+		assert.Equal(t, "title cannot be empty", response.Message)
+		assert.Equal(t, "req-123", response.RequestID)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("must make the request and if the task was created return a created status", func(t *testing.T) {
 		task := &models.Task{
 			Title:       "New Task",
@@ -54,10 +105,11 @@ func TestCreateTask(t *testing.T) {
 			Status:      "Pending",
 		}
 
-		mockService.On("CreateTask", task).Return(nil)
+		mockService.On("CreateTask", mock.Anything, userID, task).Return(nil)
 
 		body, _ := json.Marshal(task)
 		req, err := http.NewRequest("POST", "/tasks", bytes.NewBuffer(body))
+		req = withUser(req)
 		assert.NoError(t, err)
 
 		rr := httptest.NewRecorder()
@@ -74,16 +126,18 @@ func TestDeleteTask(t *testing.T) {
 
 	t.Run("should validate the ID and if the ID is empty return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/tasks/", nil)
+		req = withUser(req)
 
 		rr := httptest.NewRecorder()
 		handler.DeleteTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "ID cannot be empty\n", rr.Body.String())
+		assert.Equal(t, "empty_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should validate the ID and if the ID is invalid return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/tasks/invalid", nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", "invalid")
@@ -91,33 +145,68 @@ func TestDeleteTask(t *testing.T) {
 		handler.DeleteTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "the id is invalid\n", rr.Body.String())
+		assert.Equal(t, "invalid_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should return 500 if service returns error", func(t *testing.T) {
 		taskID := 1
 		req, _ := http.NewRequest("DELETE", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", strconv.Itoa(taskID))
 
-		mockService.On("DeleteTask", int64(taskID)).Return(errors.New("service error")).Once()
+		mockService.On("DeleteTask", mock.Anything, userID, int64(taskID), int64(0)).Return(errors.New("service error")).Once()
 
 		handler.DeleteTask(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-		assert.Equal(t, "service error\n", rr.Body.String())
+		assert.Equal(t, "internal_error", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 if the user is not the owner", func(t *testing.T) {
+		taskID := 1
+		req, _ := http.NewRequest("DELETE", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		req.SetPathValue("id", strconv.Itoa(taskID))
+
+		mockService.On("DeleteTask", mock.Anything, userID, int64(taskID), int64(0)).Return(utils.ErrForbidden).Once()
+
+		handler.DeleteTask(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 404 if the task does not exist", func(t *testing.T) {
+		taskID := 999
+		req, _ := http.NewRequest("DELETE", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		req.SetPathValue("id", strconv.Itoa(taskID))
+
+		mockService.On("DeleteTask", mock.Anything, userID, int64(taskID), int64(0)).Return(utils.ErrTaskNotFound).Once()
+
+		handler.DeleteTask(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "task_not_found", decodeError(t, rr).Code)
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("should return 204 if task is deleted successfully", func(t *testing.T) {
 		taskID := 1
 		req, _ := http.NewRequest("DELETE", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", strconv.Itoa(taskID))
 
-		mockService.On("DeleteTask", int64(taskID)).Return(nil).Once()
+		mockService.On("DeleteTask", mock.Anything, userID, int64(taskID), int64(0)).Return(nil).Once()
 
 		handler.DeleteTask(rr, req)
 
@@ -132,16 +221,18 @@ func TestGetTask(t *testing.T) {
 
 	t.Run("should validate the ID and if the ID is empty return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/tasks/", nil)
+		req = withUser(req)
 
 		rr := httptest.NewRecorder()
 		handler.GetTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "ID cannot be empty\n", rr.Body.String())
+		assert.Equal(t, "empty_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should validate the ID and if the ID is invalid return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/tasks/invalid", nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", "invalid")
@@ -149,50 +240,90 @@ func TestGetTask(t *testing.T) {
 		handler.GetTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "the id is invalid\n", rr.Body.String())
+		assert.Equal(t, "invalid_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should return 404 if task not found", func(t *testing.T) {
 		taskID := 1
 		req, _ := http.NewRequest("GET", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", strconv.Itoa(taskID))
 
-		mockService.On("GetTask", int64(taskID)).Return((*models.Task)(nil), nil).Once()
+		mockService.On("GetTask", mock.Anything, userID, int64(taskID)).Return((*models.Task)(nil), nil).Once()
 
 		handler.GetTask(rr, req)
 
 		assert.Equal(t, http.StatusNotFound, rr.Code)
-		assert.Equal(t, "task not found\n", rr.Body.String())
+		assert.Equal(t, "task_not_found", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 if the task is not shared with the user", func(t *testing.T) {
+		taskID := 1
+		req, _ := http.NewRequest("GET", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		req.SetPathValue("id", strconv.Itoa(taskID))
+
+		mockService.On("GetTask", mock.Anything, userID, int64(taskID)).Return((*models.Task)(nil), utils.ErrForbidden).Once()
+
+		handler.GetTask(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("should return 500 if service fails", func(t *testing.T) {
 		taskID := 1
 		req, _ := http.NewRequest("GET", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", strconv.Itoa(taskID))
 
-		mockService.On("GetTask", int64(taskID)).Return(&models.Task{}, errors.New("service error")).Once()
+		mockService.On("GetTask", mock.Anything, userID, int64(taskID)).Return(&models.Task{}, errors.New("service error")).Once()
 
 		handler.GetTask(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-		assert.Equal(t, "service error\n", rr.Body.String())
+		assert.Equal(t, "internal_error", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should pass the request context to the service", func(t *testing.T) {
+		type ctxKey struct{}
+		taskID := 1
+		req, _ := http.NewRequest("GET", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
+		req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "request-scoped"))
+		rr := httptest.NewRecorder()
+
+		req.SetPathValue("id", strconv.Itoa(taskID))
+
+		hasRequestValue := mock.MatchedBy(func(ctx context.Context) bool {
+			return ctx.Value(ctxKey{}) == "request-scoped"
+		})
+		mockService.On("GetTask", hasRequestValue, userID, int64(taskID)).Return(&models.Task{ID: int64(taskID)}, nil).Once()
+
+		handler.GetTask(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("should return 200 and task if found", func(t *testing.T) {
 		taskID := 1
 		req, _ := http.NewRequest("GET", "/tasks/"+strconv.Itoa(taskID), nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", strconv.Itoa(taskID))
 
 		expectedTask := &models.Task{ID: int64(taskID), Title: "New Task", Description: "New Description", Status: "Pending"}
-		mockService.On("GetTask", int64(taskID)).Return(expectedTask, nil).Once()
+		mockService.On("GetTask", mock.Anything, userID, int64(taskID)).Return(expectedTask, nil).Once()
 
 		handler.GetTask(rr, req)
 
@@ -212,35 +343,138 @@ func TestListTasks(t *testing.T) {
 
 	t.Run("should return 500 if service fails", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/tasks", nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
-		mockService.On("ListTasks").Return([]*models.Task{}, errors.New("service error")).Once()
+		mockService.On("ListTasks", mock.Anything, userID, models.TaskFilter{}).Return(&models.TaskPage{}, errors.New("service error")).Once()
 
 		handler.ListTasks(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-		assert.Equal(t, "service error\n", rr.Body.String())
+		assert.Equal(t, "internal_error", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 if a query parameter is invalid", func(t *testing.T) {
+		for _, query := range []string{"limit=abc", "limit=-1", "created_after=yesterday", "overdue=maybe", "due_before=tomorrow"} {
+			req, _ := http.NewRequest("GET", "/tasks?"+query, nil)
+			req = withUser(req)
+			rr := httptest.NewRecorder()
+
+			handler.ListTasks(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should return 400 if service rejects the filter", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks?sort=description", nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		mockService.On("ListTasks", mock.Anything, userID, models.TaskFilter{Sort: "description"}).Return((*models.TaskPage)(nil), utils.ErrInvalidSort).Once()
+
+		handler.ListTasks(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "invalid_sort", decodeError(t, rr).Code)
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("should return 200 and tasks", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/tasks", nil)
+		createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		req, _ := http.NewRequest("GET", "/tasks?status=Pending&sort=title&order=desc&limit=3&cursor=abc&created_after=2024-01-01T00:00:00Z", nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
-		expectedTask := []*models.Task{
-			{ID: 1, Title: "Task 1", Description: "Descriprition Test", Status: "Pending"},
-			{ID: 2, Title: "Task 2", Description: "Descriprition Test", Status: "Pending"},
-			{ID: 3, Title: "Task 3", Description: "Descriprition Test", Status: "Pending"},
+		expectedPage := &models.TaskPage{
+			Tasks: []*models.Task{
+				{ID: 1, Title: "Task 1", Description: "Descriprition Test", Status: "Pending"},
+				{ID: 2, Title: "Task 2", Description: "Descriprition Test", Status: "Pending"},
+				{ID: 3, Title: "Task 3", Description: "Descriprition Test", Status: "Pending"},
+			},
+			NextCursor: "next",
 		}
 
-		mockService.On("ListTasks").Return(expectedTask, nil).Once()
+		expectedFilter := models.TaskFilter{
+			Status:       "Pending",
+			Sort:         "title",
+			Order:        "desc",
+			Limit:        3,
+			Cursor:       "abc",
+			CreatedAfter: &createdAfter,
+		}
+		mockService.On("ListTasks", mock.Anything, userID, expectedFilter).Return(expectedPage, nil).Once()
 
 		handler.ListTasks(rr, req)
 
-		var actualTask []*models.Task
-		err := json.NewDecoder(rr.Body).Decode(&actualTask)
+		var actualPage models.TaskPage
+		err := json.NewDecoder(rr.Body).Decode(&actualPage)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPage, &actualPage)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestSearchTasks(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the query is empty", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/search", nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		mockService.On("SearchTasks", mock.Anything, userID, "", 0).Return(([]*models.TaskSearchResult)(nil), utils.ErrEmptyQuery).Once()
+
+		handler.SearchTasks(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "empty_query", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 if the limit is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/search?q=deploy&limit=abc", nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		handler.SearchTasks(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 500 if service fails", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/search?q=deploy", nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		mockService.On("SearchTasks", mock.Anything, userID, "deploy", 0).Return(([]*models.TaskSearchResult)(nil), errors.New("service error")).Once()
+
+		handler.SearchTasks(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 200 and the ranked results", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/search?q=deploy&limit=5", nil)
+		req = withUser(req)
+		rr := httptest.NewRecorder()
+
+		expectedResults := []*models.TaskSearchResult{
+			{Task: models.Task{ID: 1, Title: "Deploy", Status: "Pending"}, Rank: 0.5, Snippet: "<mark>Deploy</mark>"},
+		}
+		mockService.On("SearchTasks", mock.Anything, userID, "deploy", 5).Return(expectedResults, nil).Once()
+
+		handler.SearchTasks(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var actualResults []*models.TaskSearchResult
+		err := json.NewDecoder(rr.Body).Decode(&actualResults)
 		assert.NoError(t, err)
-		assert.Equal(t, expectedTask, actualTask)
+		assert.Equal(t, expectedResults, actualResults)
 		mockService.AssertExpectations(t)
 	})
 }
@@ -251,16 +485,18 @@ func TestUpdateTask(t *testing.T) {
 
 	t.Run("should validate the ID and if the ID is empty return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("PUT", "/tasks/", nil)
+		req = withUser(req)
 
 		rr := httptest.NewRecorder()
 		handler.UpdateTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "ID cannot be empty\n", rr.Body.String())
+		assert.Equal(t, "empty_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should validate the ID and if the ID is invalid return 400", func(t *testing.T) {
 		req, _ := http.NewRequest("PUT", "/tasks/invalid", nil)
+		req = withUser(req)
 		rr := httptest.NewRecorder()
 
 		req.SetPathValue("id", "invalid")
@@ -268,20 +504,21 @@ func TestUpdateTask(t *testing.T) {
 		handler.UpdateTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "the id is invalid\n", rr.Body.String())
+		assert.Equal(t, "invalid_id", decodeError(t, rr).Code)
 	})
 
 	t.Run("should make the request and return a bad request error when updating the task", func(t *testing.T) {
 		taskID := 1
 
 		req, _ := http.NewRequest("PUT", "/tasks/"+strconv.Itoa(taskID), bytes.NewBuffer([]byte("invalid json")))
+		req = withUser(req)
 		req.SetPathValue("id", strconv.Itoa(taskID))
 		rr := httptest.NewRecorder()
 
 		handler.UpdateTask(rr, req)
 
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
-		assert.Equal(t, "invalid request payload\n", rr.Body.String())
+		assert.Equal(t, "invalid_payload", decodeError(t, rr).Code)
 	})
 
 	t.Run("should return 500 if service fails", func(t *testing.T) {
@@ -290,15 +527,49 @@ func TestUpdateTask(t *testing.T) {
 		taskID := 1
 
 		req, _ := http.NewRequest("PUT", "/tasks/"+strconv.Itoa(taskID), bytes.NewBuffer(payload))
+		req = withUser(req)
 		req.SetPathValue("id", strconv.Itoa(taskID))
 		rr := httptest.NewRecorder()
 
-		mockService.On("UpdateTask", mock.Anything).Return(errors.New("service error")).Once()
+		mockService.On("UpdateTask", mock.Anything, userID, mock.Anything).Return(errors.New("service error")).Once()
 
 		handler.UpdateTask(rr, req)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-		assert.Equal(t, "service error\n", rr.Body.String())
+		assert.Equal(t, "internal_error", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 404 if the task does not exist", func(t *testing.T) {
+		task := models.Task{Title: "New Task", Status: "Pending"}
+		payload, _ := json.Marshal(task)
+
+		req, _ := http.NewRequest("PUT", "/tasks/999", bytes.NewBuffer(payload))
+		req = withUser(req)
+		req.SetPathValue("id", "999")
+		rr := httptest.NewRecorder()
+
+		mockService.On("UpdateTask", mock.Anything, userID, mock.Anything).Return(utils.ErrTaskNotFound).Once()
+
+		handler.UpdateTask(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should use the id from the path even if the body has another id", func(t *testing.T) {
+		payload := []byte(`{"id": 2, "title": "New Task", "status": "Pending"}`)
+
+		req, _ := http.NewRequest("PUT", "/tasks/1", bytes.NewBuffer(payload))
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		mockService.On("UpdateTask", mock.Anything, userID, &models.Task{ID: 1, Title: "New Task", Status: "Pending"}).Return(nil).Once()
+
+		handler.UpdateTask(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
@@ -308,10 +579,11 @@ func TestUpdateTask(t *testing.T) {
 		taskID := 1
 
 		req, _ := http.NewRequest("PUT", "/tasks/"+strconv.Itoa(taskID), bytes.NewBuffer(payload))
+		req = withUser(req)
 		req.SetPathValue("id", strconv.Itoa(taskID))
 		rr := httptest.NewRecorder()
 
-		mockService.On("UpdateTask", &task).Return(nil).Once()
+		mockService.On("UpdateTask", mock.Anything, userID, &task).Return(nil).Once()
 
 		handler.UpdateTask(rr, req)
 
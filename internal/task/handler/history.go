@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/utils"
+)
+
+func (h *Handler) ListTaskEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	events, err := h.service.ListTaskEvents(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, events)
+}
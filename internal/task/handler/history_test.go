@@ -0,0 +1,52 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListTaskEvents(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the id is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/abc/history", nil)
+		req.SetPathValue("id", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.ListTaskEvents(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 200 and the field-level changes", func(t *testing.T) {
+		events := []*models.TaskEvent{{
+			ID:      2,
+			TaskID:  1,
+			Action:  models.EventUpdated,
+			Changes: map[string]models.FieldChange{"status": {Before: "Pending", After: "Completed"}},
+		}}
+		mockService.On("ListTaskEvents", mock.Anything, userID, int64(1)).Return(events, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/1/history", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.ListTaskEvents(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body []models.TaskEvent
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.Equal(t, "Completed", body[0].Changes["status"].After)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+func (h *Handler) ShareTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var member models.TaskMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+	member.TaskID = ID
+
+	if err := h.service.ShareTask(r.Context(), userID, &member); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, &member)
+}
+
+func (h *Handler) UnshareTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	memberID, ok := httputil.PathID(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnshareTask(r.Context(), userID, ID, memberID); err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListTaskMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListTaskMembers(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, members)
+}
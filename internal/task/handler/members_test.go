@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareTask(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/tasks/1/members", bytes.NewBuffer([]byte("invalid json")))
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.ShareTask(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 403 if the user is not the owner", func(t *testing.T) {
+		member := &models.TaskMember{TaskID: 1, UserID: 8, Role: models.RoleViewer}
+		body, _ := json.Marshal(member)
+		req, _ := http.NewRequest("POST", "/tasks/1/members", bytes.NewBuffer(body))
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		mockService.On("ShareTask", mock.Anything, userID, member).Return(utils.ErrForbidden).Once()
+
+		handler.ShareTask(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 201 if the task was shared", func(t *testing.T) {
+		member := &models.TaskMember{TaskID: 1, UserID: 8, Role: models.RoleEditor}
+		body, _ := json.Marshal(member)
+		req, _ := http.NewRequest("POST", "/tasks/1/members", bytes.NewBuffer(body))
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		mockService.On("ShareTask", mock.Anything, userID, member).Return(nil).Once()
+
+		handler.ShareTask(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestUnshareTask(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the member id is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/tasks/1/members/abc", nil)
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("userID", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.UnshareTask(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 404 if the member does not exist", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/tasks/1/members/8", nil)
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("userID", "8")
+		rr := httptest.NewRecorder()
+
+		mockService.On("UnshareTask", mock.Anything, userID, int64(1), int64(8)).Return(utils.ErrMemberNotFound).Once()
+
+		handler.UnshareTask(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 204 if the member was removed", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/tasks/1/members/8", nil)
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("userID", "8")
+		rr := httptest.NewRecorder()
+
+		mockService.On("UnshareTask", mock.Anything, userID, int64(1), int64(8)).Return(nil).Once()
+
+		handler.UnshareTask(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestListTaskMembers(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 200 and the members", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/1/members", nil)
+		req = withUser(req)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		members := []*models.TaskMember{{TaskID: 1, UserID: 8, Role: models.RoleViewer}}
+		mockService.On("ListTaskMembers", mock.Anything, userID, int64(1)).Return(members, nil).Once()
+
+		handler.ListTaskMembers(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var actual []*models.TaskMember
+		err := json.NewDecoder(rr.Body).Decode(&actual)
+		assert.NoError(t, err)
+		assert.Equal(t, members, actual)
+		mockService.AssertExpectations(t)
+	})
+}
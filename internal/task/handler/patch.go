@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+// PatchTask applies a partial update to a task. The body is read as an
+// RFC 7396 merge patch by default, or as an RFC 6902 JSON Patch when sent
+// with the application/json-patch+json media type.
+func (h *Handler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+
+	var patch *models.TaskPatch
+	switch mediaType(r) {
+	case mergePatchMediaType, "application/json", "":
+		patch, err = ParseMergePatch(body)
+	case jsonPatchMediaType:
+		patch, err = parseJSONPatch(body)
+	default:
+		w.Header().Set("Accept-Patch", mergePatchMediaType+", "+jsonPatchMediaType)
+		err = utils.ErrUnsupportedMedia
+	}
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	task, err := h.service.PatchTask(r.Context(), userID, ID, version, patch)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", taskETag(task))
+	httputil.WriteJSON(w, http.StatusOK, task)
+}
+
+func mediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return parsed
+}
+
+// ParseMergePatch follows RFC 7396: members that are present replace the
+// stored value and a null removes it. Removing an optional field resets it to
+// its default.
+func ParseMergePatch(body []byte) (*models.TaskPatch, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil || document == nil {
+		return nil, utils.ErrInvalidPatch
+	}
+
+	patch := &models.TaskPatch{}
+	for field, raw := range document {
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if err := removeField(patch, field); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := setField(patch, field, raw); err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseJSONPatch supports the add, replace and remove operations of RFC 6902
+// on the top-level task fields.
+func parseJSONPatch(body []byte) (*models.TaskPatch, error) {
+	var operations []patchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, utils.ErrInvalidPatch
+	}
+
+	patch := &models.TaskPatch{}
+	for _, operation := range operations {
+		if len(operation.Path) < 2 || operation.Path[0] != '/' {
+			return nil, utils.ErrInvalidPatch
+		}
+		field := operation.Path[1:]
+
+		var err error
+		switch operation.Op {
+		case "add", "replace":
+			if operation.Value == nil {
+				return nil, utils.ErrInvalidPatch
+			}
+			err = setField(patch, field, operation.Value)
+		case "remove":
+			err = removeField(patch, field)
+		default:
+			return nil, utils.ErrInvalidPatch
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+func setField(patch *models.TaskPatch, field string, raw json.RawMessage) error {
+	var target **string
+	switch field {
+	case "title":
+		target = &patch.Title
+	case "description":
+		target = &patch.Description
+	case "status":
+		target = &patch.Status
+	case "priority":
+		target = &patch.Priority
+	case "recurrence":
+		target = &patch.Recurrence
+	case "due_at":
+		var dueAt time.Time
+		if err := json.Unmarshal(raw, &dueAt); err != nil {
+			return utils.ErrInvalidPatch
+		}
+		value := &dueAt
+		patch.DueAt = &value
+		return nil
+	case "parent_id":
+		var parentID int64
+		if err := json.Unmarshal(raw, &parentID); err != nil {
+			return utils.ErrInvalidPatch
+		}
+		value := &parentID
+		patch.ParentID = &value
+		return nil
+	case "project_id":
+		var projectID int64
+		if err := json.Unmarshal(raw, &projectID); err != nil {
+			return utils.ErrInvalidPatch
+		}
+		value := &projectID
+		patch.ProjectID = &value
+		return nil
+	case "id", "owner_id", "version", "completed_at", "overdue", "progress", "labels", "archived_at", "series_id", "created_at", "updated_at":
+		return utils.ErrImmutableField
+	default:
+		return utils.ErrInvalidPatch
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return utils.ErrInvalidPatch
+	}
+	*target = &value
+	return nil
+}
+
+func removeField(patch *models.TaskPatch, field string) error {
+	switch field {
+	case "description":
+		empty := ""
+		patch.Description = &empty
+		return nil
+	case "due_at":
+		var cleared *time.Time
+		patch.DueAt = &cleared
+		return nil
+	case "priority":
+		medium := models.PriorityMedium
+		patch.Priority = &medium
+		return nil
+	case "parent_id":
+		var detached *int64
+		patch.ParentID = &detached
+		return nil
+	case "project_id":
+		var detached *int64
+		patch.ProjectID = &detached
+		return nil
+	case "recurrence":
+		none := ""
+		patch.Recurrence = &none
+		return nil
+	case "title":
+		return utils.ErrEmptyTitle
+	case "status":
+		return utils.ErrEmptyStatus
+	case "id", "owner_id", "version", "completed_at", "overdue", "progress", "labels", "archived_at", "series_id", "created_at", "updated_at":
+		return utils.ErrImmutableField
+	default:
+		return utils.ErrInvalidPatch
+	}
+}
@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPatchTask(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	newRequest := func(contentType, body string) *http.Request {
+		req, _ := http.NewRequest("PATCH", "/tasks/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.SetPathValue("id", "1")
+		return withUser(req)
+	}
+
+	t.Run("should apply a merge patch with only the supplied fields", func(t *testing.T) {
+		status := "Completed"
+		expected := &models.Task{ID: 1, Title: "Task", Status: status}
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{Status: &status}).Return(expected, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"status":"Completed"}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var task models.Task
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+		assert.Equal(t, status, task.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should clear the description when it is null in a merge patch", func(t *testing.T) {
+		empty := ""
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{Description: &empty}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"description":null}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should apply a JSON Patch document", func(t *testing.T) {
+		title, empty := "Renamed", ""
+		expected := &models.TaskPatch{Title: &title, Description: &empty}
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), expected).Return(&models.Task{ID: 1}, nil).Once()
+
+		body := `[{"op":"replace","path":"/title","value":"Renamed"},{"op":"remove","path":"/description"}]`
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/json-patch+json", body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should set and clear the due date", func(t *testing.T) {
+		dueAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		setDue := &dueAt
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{DueAt: &setDue}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"due_at":"2024-06-01T12:00:00Z"}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var cleared *time.Time
+		medium := models.PriorityMedium
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{DueAt: &cleared, Priority: &medium}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr = httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"due_at":null,"priority":null}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should move the task under a new parent and detach it", func(t *testing.T) {
+		parentID := int64(2)
+		parent := &parentID
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{ParentID: &parent}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"parent_id":2}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var detached *int64
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{ParentID: &detached}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr = httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/json-patch+json", `[{"op":"remove","path":"/parent_id"}]`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should set and stop a recurrence", func(t *testing.T) {
+		rule := "FREQ=WEEKLY;BYDAY=MO"
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{Recurrence: &rule}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"recurrence":"FREQ=WEEKLY;BYDAY=MO"}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		none := ""
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{Recurrence: &none}).Return(&models.Task{ID: 1}, nil).Once()
+
+		rr = httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/json-patch+json", `[{"op":"remove","path":"/recurrence"}]`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 422 if an immutable field is patched", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"owner_id":8}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "immutable_field", decodeError(t, rr).Code)
+	})
+
+	t.Run("should return 422 if the title is removed", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"title":null}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("should return 400 if the patch is malformed", func(t *testing.T) {
+		for _, tc := range []struct{ contentType, body string }{
+			{"application/merge-patch+json", `["status"]`},
+			{"application/merge-patch+json", `{"status":1}`},
+			{"application/merge-patch+json", `{"unknown":"x"}`},
+			{"application/json-patch+json", `[{"op":"move","from":"/title","path":"/description"}]`},
+			{"application/json-patch+json", `[{"op":"replace","path":"/title"}]`},
+		} {
+			rr := httptest.NewRecorder()
+			handler.PatchTask(rr, newRequest(tc.contentType, tc.body))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, tc.body)
+			assert.Equal(t, "invalid_patch", decodeError(t, rr).Code)
+		}
+	})
+
+	t.Run("should return 415 for an unsupported content type", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("text/plain", `status=Completed`))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+		assert.Contains(t, rr.Header().Get("Accept-Patch"), "application/merge-patch+json")
+	})
+
+	t.Run("should return 404 if the task does not exist", func(t *testing.T) {
+		status := "Completed"
+		mockService.On("PatchTask", mock.Anything, userID, int64(1), int64(0), &models.TaskPatch{Status: &status}).Return((*models.Task)(nil), utils.ErrTaskNotFound).Once()
+
+		rr := httptest.NewRecorder()
+		handler.PatchTask(rr, newRequest("application/merge-patch+json", `{"status":"Completed"}`))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
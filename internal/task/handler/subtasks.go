@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/utils"
+)
+
+// ListSubtasks returns every descendant of a task, breadth first.
+func (h *Handler) ListSubtasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	subtasks, err := h.service.ListSubtasks(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, subtasks)
+}
@@ -0,0 +1,49 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListSubtasks(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the id is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/tasks/abc/subtasks", nil)
+		req.SetPathValue("id", "abc")
+		rr := httptest.NewRecorder()
+
+		handler.ListSubtasks(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 200 and the subtree", func(t *testing.T) {
+		parentID := int64(1)
+		subtasks := []*models.Task{{ID: 2, Title: "Child", Status: "Pending", ParentID: &parentID}}
+		mockService.On("ListSubtasks", mock.Anything, userID, int64(1)).Return(subtasks, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/1/subtasks", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+
+		handler.ListSubtasks(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body []models.Task
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		assert.Len(t, body, 1)
+		assert.Equal(t, parentID, *body[0].ParentID)
+		mockService.AssertExpectations(t)
+	})
+}
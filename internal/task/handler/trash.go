@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"todo_list_api/internal/httputil"
+	"todo_list_api/pkg/utils"
+)
+
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.service.ListTrash(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, tasks)
+}
+
+func (h *Handler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ID, ok := httputil.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	task, err := h.service.RestoreTask(r.Context(), userID, ID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", taskETag(task))
+	httputil.WriteJSON(w, http.StatusOK, task)
+}
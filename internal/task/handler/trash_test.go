@@ -0,0 +1,68 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo_list_api/internal/task/handler"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListTrash(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 200 and the trashed tasks", func(t *testing.T) {
+		deletedAt := time.Now().UTC()
+		mockService.On("ListTrash", mock.Anything, userID).Return([]*models.Task{{ID: 1, DeletedAt: &deletedAt}}, nil).Once()
+
+		req, _ := http.NewRequest("GET", "/tasks/trash", nil)
+		rr := httptest.NewRecorder()
+		handler.ListTrash(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var tasks []models.Task
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&tasks))
+		assert.Len(t, tasks, 1)
+		assert.NotNil(t, tasks[0].DeletedAt)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRestoreTask(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 404 if the task is not in the trash", func(t *testing.T) {
+		mockService.On("RestoreTask", mock.Anything, userID, int64(1)).Return((*models.Task)(nil), utils.ErrTaskNotFound).Once()
+
+		req, _ := http.NewRequest("POST", "/tasks/1/restore", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+		handler.RestoreTask(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 200 and the restored task", func(t *testing.T) {
+		mockService.On("RestoreTask", mock.Anything, userID, int64(1)).Return(&models.Task{ID: 1, Version: 5}, nil).Once()
+
+		req, _ := http.NewRequest("POST", "/tasks/1/restore", nil)
+		req.SetPathValue("id", "1")
+		rr := httptest.NewRecorder()
+		handler.RestoreTask(rr, withUser(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"5"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+}
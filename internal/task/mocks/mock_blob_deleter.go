@@ -0,0 +1,17 @@
+package task
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBlobDeleter struct {
+	mock.Mock
+}
+
+// Delete implements service.BlobDeleter.
+func (m *MockBlobDeleter) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
@@ -1,6 +1,9 @@
 package task
 
 import (
+	"context"
+	"time"
+	"todo_list_api/internal/task/repository"
 	"todo_list_api/pkg/models"
 
 	"github.com/stretchr/testify/mock"
@@ -10,32 +13,177 @@ type MockRepository struct {
 	mock.Mock
 }
 
+// AddTaskDependency implements Repository.
+func (m *MockRepository) AddTaskDependency(ctx context.Context, dependency *models.TaskDependency) error {
+	args := m.Called(ctx, dependency)
+	return args.Error(0)
+}
+
+// AddTaskMember implements Repository.
+func (m *MockRepository) AddTaskMember(ctx context.Context, member *models.TaskMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+// CountComments implements Repository.
+func (m *MockRepository) CountComments(ctx context.Context, taskID int64) (int, error) {
+	args := m.Called(ctx, taskID)
+	return args.Int(0), args.Error(1)
+}
+
+// CreateOccurrence implements Repository.
+func (m *MockRepository) CreateOccurrence(ctx context.Context, template *models.Task, dueAt time.Time, status string) (*models.Task, error) {
+	args := m.Called(ctx, template, dueAt, status)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
 // CreateTask implements Repository.
-func (m *MockRepository) CreateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	args := m.Called(ctx, task)
 	return args.Error(0)
 }
 
 // DeleteTask implements Repository.
-func (m *MockRepository) DeleteTask(id int64) error {
-	args := m.Called(id)
+func (m *MockRepository) DeleteTask(ctx context.Context, actorID, id, version int64) error {
+	args := m.Called(ctx, actorID, id, version)
 	return args.Error(0)
 }
 
+// GetProject implements Repository.
+func (m *MockRepository) GetProject(ctx context.Context, id int64) (*models.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+// GetProjectMemberRole implements Repository.
+func (m *MockRepository) GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error) {
+	args := m.Called(ctx, projectID, userID)
+	return args.String(0), args.Error(1)
+}
+
 // GetTask implements Repository.
-func (m *MockRepository) GetTask(id int64) (*models.Task, error) {
-	args := m.Called(id)
+func (m *MockRepository) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
+// GetTaskProgress implements Repository.
+func (m *MockRepository) GetTaskProgress(ctx context.Context, id int64) (*models.TaskProgress, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.TaskProgress), args.Error(1)
+}
+
+// GetTaskRole implements Repository.
+func (m *MockRepository) GetTaskRole(ctx context.Context, userID, taskID int64) (string, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.String(0), args.Error(1)
+}
+
+// LatestOccurrence implements Repository.
+func (m *MockRepository) LatestOccurrence(ctx context.Context, seriesID int64) (*time.Time, error) {
+	args := m.Called(ctx, seriesID)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+// ListBlockers implements Repository.
+func (m *MockRepository) ListBlockers(ctx context.Context, taskID, userID int64) ([]*models.TaskBlocker, error) {
+	args := m.Called(ctx, taskID, userID)
+	return args.Get(0).([]*models.TaskBlocker), args.Error(1)
+}
+
+// ListRecurringTasks implements Repository.
+func (m *MockRepository) ListRecurringTasks(ctx context.Context) ([]*models.Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// ListSubtasks implements Repository.
+func (m *MockRepository) ListSubtasks(ctx context.Context, id int64) ([]*models.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// ListTaskEvents implements Repository.
+func (m *MockRepository) ListTaskEvents(ctx context.Context, taskID int64) ([]*models.TaskEvent, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*models.TaskEvent), args.Error(1)
+}
+
+// ListTaskMembers implements Repository.
+func (m *MockRepository) ListTaskMembers(ctx context.Context, taskID int64) ([]*models.TaskMember, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*models.TaskMember), args.Error(1)
+}
+
 // ListTasks implements Repository.
-func (m *MockRepository) ListTasks() ([]*models.Task, error) {
-	args := m.Called()
+func (m *MockRepository) ListTasks(ctx context.Context, filter models.TaskFilter) (*models.TaskPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
+// ListTrash implements Repository.
+func (m *MockRepository) ListTrash(ctx context.Context, userID int64) ([]*models.Task, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*models.Task), args.Error(1)
 }
 
-// UpdateTask implements Repository.
-func (m *MockRepository) UpdateTask(task *models.Task) error {
-	args := m.Called(task)
+// PatchTask implements Repository. Expectations return the stored task that
+// check runs against, followed by the patched task and the error.
+func (m *MockRepository) PatchTask(ctx context.Context, actorID, id, version int64, patch *models.TaskPatch, check repository.TaskCheck) (*models.Task, error) {
+	args := m.Called(ctx, actorID, id, version, patch)
+	if err := runCheck(check, args.Get(0)); err != nil {
+		return nil, err
+	}
+	return args.Get(1).(*models.Task), args.Error(2)
+}
+
+// PurgeTasks implements Repository.
+func (m *MockRepository) PurgeTasks(ctx context.Context, deletedBefore time.Time) (int64, []string, error) {
+	args := m.Called(ctx, deletedBefore)
+	keys, _ := args.Get(1).([]string)
+	return args.Get(0).(int64), keys, args.Error(2)
+}
+
+// RemoveTaskDependency implements Repository.
+func (m *MockRepository) RemoveTaskDependency(ctx context.Context, taskID, blockedByID int64) error {
+	args := m.Called(ctx, taskID, blockedByID)
 	return args.Error(0)
 }
+
+// RemoveTaskMember implements Repository.
+func (m *MockRepository) RemoveTaskMember(ctx context.Context, taskID, userID int64) error {
+	args := m.Called(ctx, taskID, userID)
+	return args.Error(0)
+}
+
+// RestoreTask implements Repository.
+func (m *MockRepository) RestoreTask(ctx context.Context, actorID, id int64) (*models.Task, error) {
+	args := m.Called(ctx, actorID, id)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// SearchTasks implements Repository.
+func (m *MockRepository) SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error) {
+	args := m.Called(ctx, userID, query, limit)
+	return args.Get(0).([]*models.TaskSearchResult), args.Error(1)
+}
+
+// UpdateTask implements Repository. Expectations return the stored task that
+// check runs against, followed by the error.
+func (m *MockRepository) UpdateTask(ctx context.Context, actorID int64, task *models.Task, check repository.TaskCheck) error {
+	args := m.Called(ctx, actorID, task)
+	if err := runCheck(check, args.Get(0)); err != nil {
+		return err
+	}
+	return args.Error(1)
+}
+
+// runCheck runs check the way the repository does once the row is locked.
+// A nil stored task skips it.
+func runCheck(check repository.TaskCheck, stored any) error {
+	before, _ := stored.(*models.Task)
+	if check == nil || before == nil {
+		return nil
+	}
+	return check(before)
+}
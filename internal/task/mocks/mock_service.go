@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"todo_list_api/pkg/models"
 
 	"github.com/stretchr/testify/mock"
@@ -10,32 +11,104 @@ type MockService struct {
 	mock.Mock
 }
 
+// AddTaskDependency implements task.Service.
+func (m *MockService) AddTaskDependency(ctx context.Context, userID int64, dependency *models.TaskDependency) error {
+	args := m.Called(ctx, userID, dependency)
+	return args.Error(0)
+}
+
 // CreateTask implements task.Service.
-func (m *MockService) CreateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockService) CreateTask(ctx context.Context, userID int64, task *models.Task) error {
+	args := m.Called(ctx, userID, task)
 	return args.Error(0)
 }
 
 // DeleteTask implements task.Service.
-func (m *MockService) DeleteTask(id int64) error {
-	args := m.Called(id)
+func (m *MockService) DeleteTask(ctx context.Context, userID, id, version int64) error {
+	args := m.Called(ctx, userID, id, version)
 	return args.Error(0)
 }
 
 // GetTask implements task.Service.
-func (m *MockService) GetTask(id int64) (*models.Task, error) {
-	args := m.Called(id)
+func (m *MockService) GetTask(ctx context.Context, userID, id int64) (*models.Task, error) {
+	args := m.Called(ctx, userID, id)
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
+// ListSubtasks implements task.Service.
+func (m *MockService) ListSubtasks(ctx context.Context, userID, id int64) ([]*models.Task, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// ListTaskDependencies implements task.Service.
+func (m *MockService) ListTaskDependencies(ctx context.Context, userID, taskID int64) ([]*models.TaskBlocker, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).([]*models.TaskBlocker), args.Error(1)
+}
+
+// ListTaskEvents implements task.Service.
+func (m *MockService) ListTaskEvents(ctx context.Context, userID, taskID int64) ([]*models.TaskEvent, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).([]*models.TaskEvent), args.Error(1)
+}
+
+// ListTaskMembers implements task.Service.
+func (m *MockService) ListTaskMembers(ctx context.Context, userID, taskID int64) ([]*models.TaskMember, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).([]*models.TaskMember), args.Error(1)
+}
+
 // ListTasks implements task.Service.
-func (m *MockService) ListTasks() ([]*models.Task, error) {
-	args := m.Called()
+func (m *MockService) ListTasks(ctx context.Context, userID int64, filter models.TaskFilter) (*models.TaskPage, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
+// ListTrash implements task.Service.
+func (m *MockService) ListTrash(ctx context.Context, userID int64) ([]*models.Task, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*models.Task), args.Error(1)
 }
 
+// PatchTask implements task.Service.
+func (m *MockService) PatchTask(ctx context.Context, userID, id, version int64, patch *models.TaskPatch) (*models.Task, error) {
+	args := m.Called(ctx, userID, id, version, patch)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// RemoveTaskDependency implements task.Service.
+func (m *MockService) RemoveTaskDependency(ctx context.Context, userID, taskID, blockedByID int64) error {
+	args := m.Called(ctx, userID, taskID, blockedByID)
+	return args.Error(0)
+}
+
+// RestoreTask implements task.Service.
+func (m *MockService) RestoreTask(ctx context.Context, userID, id int64) (*models.Task, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// SearchTasks implements task.Service.
+func (m *MockService) SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error) {
+	args := m.Called(ctx, userID, query, limit)
+	return args.Get(0).([]*models.TaskSearchResult), args.Error(1)
+}
+
+// ShareTask implements task.Service.
+func (m *MockService) ShareTask(ctx context.Context, userID int64, member *models.TaskMember) error {
+	args := m.Called(ctx, userID, member)
+	return args.Error(0)
+}
+
+// UnshareTask implements task.Service.
+func (m *MockService) UnshareTask(ctx context.Context, userID, taskID, memberID int64) error {
+	args := m.Called(ctx, userID, taskID, memberID)
+	return args.Error(0)
+}
+
 // UpdateTask implements task.Service.
-func (m *MockService) UpdateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockService) UpdateTask(ctx context.Context, userID int64, task *models.Task) error {
+	args := m.Called(ctx, userID, task)
 	return args.Error(0)
 }
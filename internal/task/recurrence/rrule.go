@@ -0,0 +1,402 @@
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo_list_api/pkg/utils"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry. A non-zero Ordinal picks the nth such weekday
+// of the month, counting from the end when negative.
+type WeekdayNum struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// Rule is an iCalendar (RFC 5545) recurrence rule. The supported subset is
+// FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// maxPeriods bounds the search for the next occurrence, so rules that can
+// never match again (such as the 30th of February) end the series instead of
+// looping forever.
+const maxPeriods = 100_000
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,TH". The "RRULE:" prefix is
+// optional and keys are case-insensitive. Errors wrap ErrInvalidRecurrence.
+func Parse(rule string) (*Rule, error) {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	rule = strings.TrimPrefix(rule, "RRULE:")
+	if rule == "" {
+		return nil, invalid("the rule is empty")
+	}
+
+	r := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, invalid("%q is not a KEY=VALUE pair", part)
+		}
+		if seen[key] {
+			return nil, invalid("%s is given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if !slices.Contains([]Frequency{Daily, Weekly, Monthly, Yearly}, r.Freq) {
+				err = invalid("FREQ=%s is not supported", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(key, value)
+		case "COUNT":
+			r.Count, err = positive(key, value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "BYMONTH":
+			r.ByMonth, err = parseByMonth(value)
+		default:
+			err = invalid("%s is not supported", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+
+	if r.Count > 0 && r.Until != nil {
+		return nil, invalid("COUNT and UNTIL cannot be combined")
+	}
+
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, day := range r.ByDay {
+			if day.Ordinal != 0 {
+				return nil, invalid("BYDAY ordinals need a MONTHLY or YEARLY frequency")
+			}
+		}
+		if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+			return nil, invalid("BYMONTHDAY cannot be used with a WEEKLY frequency")
+		}
+	}
+
+	// Ordinals are counted within each month, which RFC 5545 only does for
+	// yearly rules once BYMONTH narrows them down.
+	if r.Freq == Yearly && len(r.ByMonth) == 0 {
+		for _, day := range r.ByDay {
+			if day.Ordinal != 0 {
+				return nil, invalid("BYDAY ordinals need BYMONTH in a YEARLY rule")
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", utils.ErrInvalidRecurrence, fmt.Sprintf(format, args...))
+}
+
+func positive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, invalid("%s must be a positive number", key)
+	}
+	return n, nil
+}
+
+// parseUntil accepts the UTC, floating and date forms; floating times are read
+// as UTC and a date includes the whole day.
+func parseUntil(value string) (*time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return &until, nil
+		}
+	}
+
+	day, err := time.Parse("20060102", value)
+	if err != nil {
+		return nil, invalid("UNTIL=%s is not a date", value)
+	}
+	until := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	return &until, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, invalid("BYDAY=%s is not a weekday", item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, invalid("BYDAY=%s is not a weekday", item)
+		}
+
+		ordinal := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, invalid("BYDAY=%s has an invalid ordinal", item)
+			}
+			ordinal = n
+		}
+
+		days = append(days, WeekdayNum{Ordinal: ordinal, Day: day})
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, invalid("BYMONTHDAY=%s is not a day of the month", item)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+func parseByMonth(value string) ([]time.Month, error) {
+	var months []time.Month
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 1 || n > 12 {
+			return nil, invalid("BYMONTH=%s is not a month", item)
+		}
+		months = append(months, time.Month(n))
+	}
+	slices.Sort(months)
+	return slices.Compact(months), nil
+}
+
+// Next returns the first occurrence strictly after after, for a series whose
+// first occurrence is start. Like DTSTART, start always counts as an
+// occurrence and supplies the time of day and location of the others. It
+// reports false once the series has ended.
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	if after.Before(start) {
+		return start, true
+	}
+
+	count := 1
+	if r.Count > 0 && count >= r.Count {
+		return time.Time{}, false
+	}
+
+	// Without COUNT the occurrences before after need not be counted, so the
+	// search can skip straight to the period holding after.
+	first := 0
+	if r.Count == 0 {
+		first = r.periodsBetween(start, after)
+	}
+
+	for period := first; period < first+maxPeriods; period++ {
+		for _, occurrence := range r.occurrences(start, period) {
+			if !occurrence.After(start) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return time.Time{}, false
+			}
+			if count++; r.Count > 0 && count > r.Count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// periodsBetween returns a period index at or before the one holding after.
+func (r *Rule) periodsBetween(start, after time.Time) int {
+	var periods int
+	switch r.Freq {
+	case Daily:
+		periods = int(after.Sub(start).Hours() / 24)
+	case Weekly:
+		periods = int(after.Sub(start).Hours() / (24 * 7))
+	case Monthly:
+		periods = (after.Year()-start.Year())*12 + int(after.Month()-start.Month())
+	case Yearly:
+		periods = after.Year() - start.Year()
+	}
+	return max(periods/r.Interval-1, 0)
+}
+
+// occurrences lists, in order, the candidate dates of the nth period after
+// the one holding start.
+func (r *Rule) occurrences(start time.Time, period int) []time.Time {
+	step := period * r.Interval
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	var dates []time.Time
+	switch r.Freq {
+	case Daily:
+		day := start.AddDate(0, 0, step)
+		if r.matchesWeekday(day) && r.matchesMonth(day.Month()) && r.matchesMonthDay(day) {
+			dates = append(dates, day)
+		}
+	case Weekly:
+		// Weeks start on Monday, the RFC 5545 default for WKST.
+		monday := start.AddDate(0, 0, -(int(start.Weekday())+6)%7+step*7)
+		for offset := range 7 {
+			day := at(monday.Year(), monday.Month(), monday.Day()+offset)
+			if r.weeklyDay(start, day.Weekday()) && r.matchesMonth(day.Month()) {
+				dates = append(dates, day)
+			}
+		}
+	case Monthly:
+		first := at(start.Year(), start.Month()+time.Month(step), 1)
+		if r.matchesMonth(first.Month()) {
+			for _, day := range r.monthDays(start, first) {
+				dates = append(dates, at(first.Year(), first.Month(), day))
+			}
+		}
+	case Yearly:
+		year := start.Year() + step
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			first := at(year, month, 1)
+			for _, day := range r.monthDays(start, first) {
+				dates = append(dates, at(year, month, day))
+			}
+		}
+	}
+	return dates
+}
+
+func (r *Rule) weeklyDay(start time.Time, day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return day == start.Weekday()
+	}
+	return slices.ContainsFunc(r.ByDay, func(d WeekdayNum) bool { return d.Day == day })
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	return len(r.ByDay) == 0 || slices.ContainsFunc(r.ByDay, func(d WeekdayNum) bool { return d.Day == day.Weekday() })
+}
+
+func (r *Rule) matchesMonth(month time.Month) bool {
+	return len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, month)
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := daysIn(day.Year(), day.Month())
+	return slices.ContainsFunc(r.ByMonthDay, func(d int) bool { return monthDay(d, last) == day.Day() })
+}
+
+// monthDays returns the sorted days of the month starting at first that the
+// rule selects. BYMONTHDAY and BYDAY narrow each other down when both are
+// given; with neither, the day of start is reused.
+func (r *Rule) monthDays(start, first time.Time) []int {
+	last := daysIn(first.Year(), first.Month())
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			day := monthDay(d, last)
+			if day == 0 {
+				continue
+			}
+			weekday := first.AddDate(0, 0, day-1).Weekday()
+			if len(r.ByDay) == 0 || slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool { return w.Day == weekday }) {
+				days = append(days, day)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, w := range r.ByDay {
+			var matches []int
+			for day := 1; day <= last; day++ {
+				if first.AddDate(0, 0, day-1).Weekday() == w.Day {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case w.Ordinal == 0:
+				days = append(days, matches...)
+			case w.Ordinal > 0 && w.Ordinal <= len(matches):
+				days = append(days, matches[w.Ordinal-1])
+			case w.Ordinal < 0 && -w.Ordinal <= len(matches):
+				days = append(days, matches[len(matches)+w.Ordinal])
+			}
+		}
+	default:
+		if start.Day() <= last {
+			days = append(days, start.Day())
+		}
+	}
+
+	slices.Sort(days)
+	return slices.Compact(days)
+}
+
+// monthDay resolves a BYMONTHDAY value against a month of last days, counting
+// negative values from the end. It returns 0 for days the month lacks.
+func monthDay(d, last int) int {
+	if d < 0 {
+		d = last + 1 + d
+	}
+	if d < 1 || d > last {
+		return 0
+	}
+	return d
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
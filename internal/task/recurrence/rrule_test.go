@@ -0,0 +1,142 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+	"todo_list_api/internal/task/recurrence"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("should read the supported parts with or without the prefix", func(t *testing.T) {
+		rule, err := recurrence.Parse("RRULE:freq=monthly;interval=2;byday=-1FR;bymonth=3,1;count=4")
+		assert.NoError(t, err)
+		assert.Equal(t, recurrence.Monthly, rule.Freq)
+		assert.Equal(t, 2, rule.Interval)
+		assert.Equal(t, 4, rule.Count)
+		assert.Equal(t, []recurrence.WeekdayNum{{Ordinal: -1, Day: time.Friday}}, rule.ByDay)
+		assert.Equal(t, []time.Month{time.January, time.March}, rule.ByMonth)
+	})
+
+	t.Run("should reject invalid or unsupported rules", func(t *testing.T) {
+		for _, rule := range []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=HOURLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=WEEKLY;BYMONTHDAY=3",
+			"FREQ=YEARLY;BYDAY=2SU",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=DAILY;BYSETPOS=1",
+		} {
+			_, err := recurrence.Parse(rule)
+			assert.ErrorIs(t, err, utils.ErrInvalidRecurrence, rule)
+		}
+	})
+}
+
+func TestNext(t *testing.T) {
+	// Monday, 6 January 2025.
+	start := date(2025, time.January, 6, 9)
+
+	t.Run("should return start while it is still ahead", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=DAILY")
+		next, ok := rule.Next(start, start.Add(-time.Hour))
+		assert.True(t, ok)
+		assert.Equal(t, start, next)
+	})
+
+	t.Run("must step daily rules by the interval", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=DAILY;INTERVAL=3")
+		next, ok := rule.Next(start, date(2025, time.January, 10, 9))
+		assert.True(t, ok)
+		assert.Equal(t, date(2025, time.January, 12, 9), next)
+	})
+
+	t.Run("must walk the listed weekdays of every other week", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH")
+
+		next, _ := rule.Next(start, start)
+		assert.Equal(t, date(2025, time.January, 9, 9), next)
+
+		next, _ = rule.Next(start, next)
+		assert.Equal(t, date(2025, time.January, 20, 9), next)
+	})
+
+	t.Run("should skip months that lack the start day", func(t *testing.T) {
+		jan31 := date(2025, time.January, 31, 9)
+		rule, _ := recurrence.Parse("FREQ=MONTHLY")
+		next, _ := rule.Next(jan31, jan31)
+		assert.Equal(t, date(2025, time.March, 31, 9), next)
+	})
+
+	t.Run("must pick ordinal weekdays and negative month days", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=MONTHLY;BYDAY=-1FR")
+		next, _ := rule.Next(start, start)
+		assert.Equal(t, date(2025, time.January, 31, 9), next)
+
+		rule, _ = recurrence.Parse("FREQ=MONTHLY;BYMONTHDAY=-1")
+		next, _ = rule.Next(start, date(2025, time.February, 1, 0))
+		assert.Equal(t, date(2025, time.February, 28, 9), next)
+	})
+
+	t.Run("must expand yearly rules over BYMONTH", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=YEARLY;BYMONTH=1,7;BYDAY=1MO")
+		next, _ := rule.Next(start, start)
+		assert.Equal(t, date(2025, time.July, 7, 9), next)
+
+		next, _ = rule.Next(start, next)
+		assert.Equal(t, date(2026, time.January, 5, 9), next)
+	})
+
+	t.Run("should end the series after COUNT occurrences including start", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=DAILY;COUNT=3")
+		next, ok := rule.Next(start, date(2025, time.January, 7, 9))
+		assert.True(t, ok)
+		assert.Equal(t, date(2025, time.January, 8, 9), next)
+
+		_, ok = rule.Next(start, next)
+		assert.False(t, ok)
+	})
+
+	t.Run("should end the series after UNTIL", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=WEEKLY;UNTIL=20250120")
+		next, ok := rule.Next(start, start)
+		assert.True(t, ok)
+		assert.Equal(t, date(2025, time.January, 13, 9), next)
+
+		next, ok = rule.Next(start, next)
+		assert.True(t, ok)
+		assert.Equal(t, date(2025, time.January, 20, 9), next)
+
+		_, ok = rule.Next(start, next)
+		assert.False(t, ok)
+	})
+
+	t.Run("should end a series that can never match again", func(t *testing.T) {
+		rule, _ := recurrence.Parse("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30")
+		_, ok := rule.Next(start, start)
+		assert.False(t, ok)
+	})
+
+	t.Run("must keep the wall clock time across daylight saving changes", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		if err != nil {
+			t.Skip("time zone data is not available")
+		}
+
+		friday := time.Date(2025, time.March, 28, 9, 0, 0, 0, berlin)
+		rule, _ := recurrence.Parse("FREQ=DAILY")
+		next, _ := rule.Next(friday, friday.Add(48*time.Hour))
+		assert.Equal(t, time.Date(2025, time.March, 31, 9, 0, 0, 0, berlin), next)
+	})
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"todo_list_api/internal/db"
+)
+
+// CountComments returns the number of comments in the thread of a task.
+func (r *TaskRepository) CountComments(ctx context.Context, taskID int64) (int, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT COUNT(*) FROM comments WHERE task_id = $1"
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, taskID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
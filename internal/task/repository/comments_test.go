@@ -0,0 +1,30 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must count the comments of the task", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM comments WHERE task_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+		count, err := repo.CountComments(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// infinity stands in for a missing due date, matching the COALESCE the due
+// date sort is keyed on.
+const infinity = "infinity"
+
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(sort string, task *models.Task) string {
+	c := cursor{Sort: sort, ID: task.ID}
+	switch sort {
+	case models.SortTitle:
+		c.Value = task.Title
+	case models.SortUpdatedAt:
+		c.Value = task.UpdatedAt.Format(time.RFC3339Nano)
+	case models.SortDueAt:
+		c.Value = infinity
+		if task.DueAt != nil {
+			c.Value = task.DueAt.Format(time.RFC3339Nano)
+		}
+	case models.SortPriority:
+		c.Value = strconv.Itoa(models.PriorityRank(task.Priority))
+	default:
+		c.Value = task.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(sort, encoded string) (any, int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, utils.ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return nil, 0, utils.ErrInvalidCursor
+	}
+
+	switch {
+	case sort == models.SortTitle:
+		return c.Value, c.ID, nil
+	case sort == models.SortDueAt && c.Value == infinity:
+		return c.Value, c.ID, nil
+	case sort == models.SortPriority:
+		rank, err := strconv.Atoi(c.Value)
+		if err != nil {
+			return nil, 0, utils.ErrInvalidCursor
+		}
+		return rank, c.ID, nil
+	}
+
+	value, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return nil, 0, utils.ErrInvalidCursor
+	}
+
+	return value, c.ID, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// dependencyLockKey serialises additions to the dependency graph, so that two
+// additions running side by side cannot each miss the cycle the other one
+// closes.
+const dependencyLockKey = 72707371
+
+// AddTaskDependency records the dependency in one transaction: it locks both
+// tasks, refuses either one if it is trashed, and walks the graph for a cycle
+// once every other addition has committed.
+func (r *TaskRepository) AddTaskDependency(ctx context.Context, dependency *models.TaskDependency) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Rows are locked in id order, so that two transactions locking the same
+	// pair cannot deadlock.
+	const lock = "SELECT id FROM tasks WHERE id IN ($1, $2) AND deleted_at IS NULL ORDER BY id ASC FOR UPDATE"
+	live, err := queryIDs(ctx, tx, lock, dependency.TaskID, dependency.BlockedByID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(live, dependency.TaskID) {
+		return utils.ErrTaskNotFound
+	}
+	if !slices.Contains(live, dependency.BlockedByID) {
+		return utils.ErrInvalidDependency
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", dependencyLockKey); err != nil {
+		return err
+	}
+
+	const upstream = `WITH RECURSIVE upstream (id) AS (
+	SELECT $1::BIGINT
+	UNION
+	SELECT d.blocked_by_id FROM task_dependencies d JOIN upstream u ON d.task_id = u.id
+)
+SELECT id FROM upstream`
+	ids, err := queryIDs(ctx, tx, upstream, dependency.BlockedByID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(ids, dependency.TaskID) {
+		return utils.ErrDependencyCycle
+	}
+
+	const query = `INSERT INTO task_dependencies (task_id, blocked_by_id, created_at) VALUES ($1, $2, $3)
+ON CONFLICT (task_id, blocked_by_id) DO UPDATE SET created_at = task_dependencies.created_at
+RETURNING created_at`
+	dependency.CreatedAt = time.Now()
+	if err := tx.QueryRowContext(
+		ctx,
+		query,
+		dependency.TaskID,
+		dependency.BlockedByID,
+		dependency.CreatedAt,
+	).Scan(&dependency.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TaskRepository) RemoveTaskDependency(ctx context.Context, taskID, blockedByID int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM task_dependencies WHERE task_id = $1 AND blocked_by_id = $2"
+	result, err := r.db.ExecContext(ctx, query, taskID, blockedByID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrDependencyNotFound
+	}
+
+	return nil
+}
+
+// ListBlockers returns the live tasks that block a task. Only the blockers
+// userID has access to are returned in full; the others keep just their id
+// and status.
+func (r *TaskRepository) ListBlockers(ctx context.Context, taskID, userID int64) ([]*models.TaskBlocker, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + taskColumns + ", EXISTS (SELECT 1 FROM task_access a WHERE a.task_id = tasks.id AND a.user_id = $2) FROM tasks WHERE id IN (SELECT blocked_by_id FROM task_dependencies WHERE task_id = $1) AND deleted_at IS NULL ORDER BY id ASC"
+	rows, err := r.db.QueryContext(ctx, query, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blockers := []*models.TaskBlocker{}
+	for rows.Next() {
+		task := &models.Task{}
+		var visible bool
+		if err := scanTask(rows, task, &visible); err != nil {
+			return nil, err
+		}
+
+		blocker := &models.TaskBlocker{ID: task.ID, Status: task.Status}
+		if visible {
+			blocker.Task = task
+		}
+		blockers = append(blockers, blocker)
+	}
+
+	return blockers, rows.Err()
+}
+
+// checkOpenBlockers refuses to complete a task while any of its live blockers
+// is still open. It runs after the task's row is locked, which adding a
+// dependency to the task waits on too.
+func checkOpenBlockers(ctx context.Context, tx *sql.Tx, before *models.Task, status string) error {
+	if status != models.StatusCompleted || before.Status == models.StatusCompleted {
+		return nil
+	}
+
+	const query = "SELECT id FROM tasks WHERE id IN (SELECT blocked_by_id FROM task_dependencies WHERE task_id = $1) AND deleted_at IS NULL AND status <> $2 ORDER BY id ASC"
+	blockers, err := queryIDs(ctx, tx, query, before.ID, models.StatusCompleted)
+	if err != nil {
+		return err
+	}
+
+	if len(blockers) > 0 {
+		return &utils.BlockedError{Blockers: blockers}
+	}
+
+	return nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryIDs(ctx context.Context, q querier, query string, args ...any) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTaskDependency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const lock = "SELECT id FROM tasks WHERE id IN \\(\\$1, \\$2\\) AND deleted_at IS NULL ORDER BY id ASC FOR UPDATE"
+	const upstream = "WITH RECURSIVE upstream \\(id\\) AS \\(.+UNION.+JOIN upstream u ON d.task_id = u.id"
+
+	expectLocks := func(live ...int64) {
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, id := range live {
+			rows.AddRow(id)
+		}
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(int64(1), int64(2)).WillReturnRows(rows)
+	}
+
+	t.Run("must insert the dependency and keep the original creation time", func(t *testing.T) {
+		createdAt := time.Now().Add(-time.Hour)
+		dependency := &models.TaskDependency{TaskID: 1, BlockedByID: 2}
+
+		expectLocks(1, 2)
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(upstream).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+		mock.ExpectQuery("INSERT INTO task_dependencies \\(task_id, blocked_by_id, created_at\\) VALUES \\(\\$1, \\$2, \\$3\\) ON CONFLICT \\(task_id, blocked_by_id\\) DO UPDATE SET created_at = task_dependencies.created_at RETURNING created_at").
+			WithArgs(int64(1), int64(2), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		mock.ExpectCommit()
+
+		err := repo.AddTaskDependency(context.Background(), dependency)
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, dependency.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrInvalidDependency if the blocker is trashed", func(t *testing.T) {
+		expectLocks(1)
+		mock.ExpectRollback()
+
+		err := repo.AddTaskDependency(context.Background(), &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrInvalidDependency)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the blocked task is trashed", func(t *testing.T) {
+		expectLocks(2)
+		mock.ExpectRollback()
+
+		err := repo.AddTaskDependency(context.Background(), &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrDependencyCycle if the blocker already waits on the task", func(t *testing.T) {
+		expectLocks(1, 2)
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(upstream).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3).AddRow(1))
+		mock.ExpectRollback()
+
+		err := repo.AddTaskDependency(context.Background(), &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrDependencyCycle)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveTaskDependency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const query = "DELETE FROM task_dependencies WHERE task_id = \\$1 AND blocked_by_id = \\$2"
+
+	t.Run("must delete the dependency", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.RemoveTaskDependency(context.Background(), 1, 2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrDependencyNotFound if nothing was deleted", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RemoveTaskDependency(context.Background(), 1, 2)
+		assert.ErrorIs(t, err, utils.ErrDependencyNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListBlockers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	const query = "EXISTS \\(SELECT 1 FROM task_access a WHERE a.task_id = tasks.id AND a.user_id = \\$2\\) FROM tasks WHERE id IN \\(SELECT blocked_by_id FROM task_dependencies WHERE task_id = \\$1\\) AND deleted_at IS NULL ORDER BY id ASC"
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels", "visible"}
+
+	t.Run("must return the live blocking tasks", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "Blocker", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]", true))
+
+		blockers, err := repo.ListBlockers(context.Background(), 1, 7)
+		assert.NoError(t, err)
+		assert.Len(t, blockers, 1)
+		assert.Equal(t, int64(2), blockers[0].ID)
+		assert.Equal(t, "Blocker", blockers[0].Task.Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must redact the blockers the user has no access to", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(8)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, "Blocker", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]", true).
+				AddRow(3, "Private blocker", "Secret", "In progress", "high", nil, nil, nil, nil, nil, "", nil, 9, 1, now, now, "[]", false))
+
+		blockers, err := repo.ListBlockers(context.Background(), 1, 8)
+		assert.NoError(t, err)
+		assert.Len(t, blockers, 2)
+		assert.NotNil(t, blockers[0].Task)
+		assert.Equal(t, &models.TaskBlocker{ID: 3, Status: "In progress"}, blockers[1])
+
+		body, err := json.Marshal(blockers[1])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id":3,"status":"In progress"}`, string(body))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCompleteBlockedTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+	now := time.Now()
+	status := models.StatusCompleted
+
+	t.Run("should refuse to complete a task while its blockers are open", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "In progress", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]"))
+		mock.ExpectQuery("SELECT id FROM tasks WHERE id IN \\(SELECT blocked_by_id FROM task_dependencies WHERE task_id = \\$1\\) AND deleted_at IS NULL AND status <> \\$2").
+			WithArgs(int64(1), models.StatusCompleted).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(5))
+		mock.ExpectRollback()
+
+		_, err := repo.PatchTask(context.Background(), 7, 1, 0, &models.TaskPatch{Status: &status}, nil)
+		assert.ErrorIs(t, err, utils.ErrTaskBlocked)
+
+		var blockedErr *utils.BlockedError
+		assert.ErrorAs(t, err, &blockedErr)
+		assert.Equal(t, []int64{2, 5}, blockedErr.Blockers)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
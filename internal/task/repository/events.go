@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	outbox "todo_list_api/internal/outbox/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// auditedFields lists the task fields whose changes are written to the
+// history, in the order they are compared.
+var auditedFields = []struct {
+	name  string
+	value func(*models.Task) any
+}{
+	{"title", func(t *models.Task) any { return t.Title }},
+	{"description", func(t *models.Task) any { return t.Description }},
+	{"status", func(t *models.Task) any { return t.Status }},
+	{"priority", func(t *models.Task) any { return t.Priority }},
+	{"due_at", func(t *models.Task) any { return timeValue(t.DueAt) }},
+	{"completed_at", func(t *models.Task) any { return timeValue(t.CompletedAt) }},
+	{"parent_id", func(t *models.Task) any { return idValue(t.ParentID) }},
+	{"project_id", func(t *models.Task) any { return idValue(t.ProjectID) }},
+	{"recurrence", func(t *models.Task) any { return t.Recurrence }},
+}
+
+// timeValue turns an optional timestamp into a value that compares by
+// instant, so re-saving the same due date is not reported as a change.
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// idValue dereferences an optional id so equal ids compare equal.
+func idValue(id *int64) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// diffTasks returns the audited fields that differ between before and after.
+// A nil before describes a newly created task, so every field is reported.
+func diffTasks(before, after *models.Task) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+	for _, field := range auditedFields {
+		value := field.value(after)
+		if before == nil {
+			changes[field.name] = models.FieldChange{After: value}
+			continue
+		}
+		if previous := field.value(before); previous != value {
+			changes[field.name] = models.FieldChange{Before: previous, After: value}
+		}
+	}
+	return changes
+}
+
+// lockTask loads a live task and holds a row lock on it until tx ends, so the
+// caller can diff against it without racing concurrent writers. A non-zero
+// version must match the stored one.
+func lockTask(ctx context.Context, tx *sql.Tx, id, version int64) (*models.Task, error) {
+	const query = "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	task := &models.Task{}
+	if err := scanTask(tx.QueryRowContext(ctx, query, id), task); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	if version > 0 && task.Version != version {
+		return nil, utils.ErrPreconditionFailed
+	}
+	return task, nil
+}
+
+// recordEvent writes a history entry for a task and queues it in the outbox
+// for publishing, both as part of tx.
+func recordEvent(ctx context.Context, tx *sql.Tx, taskID, actorID int64, action string, changes map[string]models.FieldChange) error {
+	if action == models.EventUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	const query = "WITH events AS (INSERT INTO task_events (task_id, actor_id, action, changes, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, task_id, actor_id, action, changes, created_at) " + outbox.FromTaskEvents
+	_, err = tx.ExecContext(ctx, query, taskID, actorID, action, string(payload), time.Now())
+	return err
+}
+
+func (r *TaskRepository) ListTaskEvents(ctx context.Context, taskID int64) ([]*models.TaskEvent, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT id, task_id, actor_id, action, changes, created_at FROM task_events WHERE task_id = $1 ORDER BY id ASC"
+	rows, err := r.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.TaskEvent{}
+	for rows.Next() {
+		var event models.TaskEvent
+		var changes []byte
+		if err := rows.Scan(&event.ID, &event.TaskID, &event.ActorID, &event.Action, &changes, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTaskEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must return the events of the task in the order they happened", func(t *testing.T) {
+		columns := []string{"id", "task_id", "actor_id", "action", "changes", "created_at"}
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT id, task_id, actor_id, action, changes, created_at FROM task_events WHERE task_id = \\$1 ORDER BY id ASC").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 1, 7, models.EventCreated, []byte(`{"title":{"before":null,"after":"Task"}}`), now).
+				AddRow(2, 1, nil, models.EventUpdated, []byte(`{"status":{"before":"Pending","after":"Completed"}}`), now),
+			)
+
+		events, err := repo.ListTaskEvents(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, int64(7), *events[0].ActorID)
+		assert.Equal(t, models.FieldChange{Before: nil, After: "Task"}, events[0].Changes["title"])
+		assert.Nil(t, events[1].ActorID)
+		assert.Equal(t, models.FieldChange{Before: "Pending", After: "Completed"}, events[1].Changes["status"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// GetTaskRole returns the highest role the user holds on a live task,
+// directly or through its project, or an empty role when the task is not
+// shared with them. A trashed task is reported as not found.
+func (r *TaskRepository) GetTaskRole(ctx context.Context, userID, taskID int64) (string, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT ARRAY(SELECT a.role FROM task_access a WHERE a.task_id = t.id AND a.user_id = $2) FROM tasks t WHERE t.id = $1 AND t.deleted_at IS NULL"
+
+	var roles []string
+	if err := r.db.QueryRowContext(ctx, query, taskID, userID).Scan(pq.Array(&roles)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", utils.ErrTaskNotFound
+		}
+		return "", err
+	}
+	return utils.HighestRole(roles), nil
+}
+
+func (r *TaskRepository) AddTaskMember(ctx context.Context, member *models.TaskMember) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `INSERT INTO task_members (task_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (task_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING created_at`
+	member.CreatedAt = time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		member.TaskID,
+		member.UserID,
+		member.Role,
+		member.CreatedAt,
+	).Scan(&member.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return utils.ErrUserNotFound
+	}
+
+	return err
+}
+
+func (r *TaskRepository) RemoveTaskMember(ctx context.Context, taskID, userID int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM task_members WHERE task_id = $1 AND user_id = $2"
+	result, err := r.db.ExecContext(ctx, query, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *TaskRepository) ListTaskMembers(ctx context.Context, taskID int64) ([]*models.TaskMember, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT task_id, user_id, role, created_at FROM task_members WHERE task_id = $1 ORDER BY created_at, user_id"
+	rows, err := r.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.TaskMember{}
+	for rows.Next() {
+		var member models.TaskMember
+		if err := rows.Scan(
+			&member.TaskID,
+			&member.UserID,
+			&member.Role,
+			&member.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	return members, rows.Err()
+}
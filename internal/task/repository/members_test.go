@@ -0,0 +1,158 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const query = "SELECT ARRAY\\(SELECT a.role FROM task_access a WHERE a.task_id = t.id AND a.user_id = \\$2\\) FROM tasks t WHERE t.id = \\$1"
+
+	t.Run("must return the highest role of the user on the task", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow("{viewer,editor}"))
+
+		role, err := repo.GetTaskRole(context.Background(), 7, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleEditor, role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return an empty role if the task is not shared with the user", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow("{}"))
+
+		role, err := repo.GetTaskRole(context.Background(), 7, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task does not exist", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}))
+
+		_, err := repo.GetTaskRole(context.Background(), 7, 1)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAddTaskMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const query = "INSERT INTO task_members \\(task_id, user_id, role, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT \\(task_id, user_id\\) DO UPDATE SET role = EXCLUDED.role RETURNING created_at"
+
+	t.Run("must insert or update the membership", func(t *testing.T) {
+		member := &models.TaskMember{TaskID: 1, UserID: 8, Role: models.RoleViewer}
+		createdAt := time.Now().Add(-time.Hour)
+
+		mock.ExpectQuery(query).
+			WithArgs(member.TaskID, member.UserID, member.Role, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+		err := repo.AddTaskMember(context.Background(), member)
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, member.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrUserNotFound if the user does not exist", func(t *testing.T) {
+		member := &models.TaskMember{TaskID: 1, UserID: 99, Role: models.RoleViewer}
+
+		mock.ExpectQuery(query).
+			WithArgs(member.TaskID, member.UserID, member.Role, sqlmock.AnyArg()).
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		err := repo.AddTaskMember(context.Background(), member)
+		assert.ErrorIs(t, err, utils.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveTaskMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const query = "DELETE FROM task_members WHERE task_id = \\$1 AND user_id = \\$2"
+
+	t.Run("must delete the membership", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(1), int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RemoveTaskMember(context.Background(), 1, 8)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrMemberNotFound if nothing was deleted", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(1), int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RemoveTaskMember(context.Background(), 1, 8)
+		assert.ErrorIs(t, err, utils.ErrMemberNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListTaskMembers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const query = "SELECT task_id, user_id, role, created_at FROM task_members WHERE task_id = \\$1"
+
+	t.Run("must return the members of the task", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"task_id", "user_id", "role", "created_at"}).
+				AddRow(1, 8, "viewer", now).
+				AddRow(1, 9, "editor", now))
+
+		members, err := repo.ListTaskMembers(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, members, 2)
+		assert.Equal(t, models.RoleEditor, members[1].Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return a error if the query fails", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1)).
+			WillReturnError(errors.New("query invalid"))
+
+		_, err := repo.ListTaskMembers(context.Background(), 1)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"todo_list_api/internal/db"
+	projectRepository "todo_list_api/internal/project/repository"
+	"todo_list_api/pkg/models"
+)
+
+// GetProject loads the project a task is being filed under, so the service
+// can check who owns it and whether it is archived.
+func (r *TaskRepository) GetProject(ctx context.Context, id int64) (*models.Project, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT id, owner_id, name, description, archived_at, created_at, updated_at FROM projects WHERE id = $1"
+	project := &models.Project{}
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&project.ID,
+		&project.OwnerID,
+		&project.Name,
+		&project.Description,
+		&project.ArchivedAt,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// GetProjectMemberRole returns the role a project is shared with a user
+// with, or an empty role when it is not shared with them.
+func (r *TaskRepository) GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return projectRepository.MemberRole(ctx, r.db, projectID, userID)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+)
+
+// ListRecurringTasks returns the live series templates the scheduler has to
+// keep materializing.
+func (r *TaskRepository) ListRecurringTasks(ctx context.Context) ([]*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + taskColumns + " FROM tasks WHERE recurrence <> '' AND due_at IS NOT NULL AND deleted_at IS NULL AND archived_at IS NULL ORDER BY id ASC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		var task models.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// LatestOccurrence returns the latest due date in a series, counting the
+// template and trashed occurrences so a deleted occurrence is not generated
+// again.
+func (r *TaskRepository) LatestOccurrence(ctx context.Context, seriesID int64) (*time.Time, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT MAX(due_at) FROM tasks WHERE id = $1 OR series_id = $1"
+	var latest *time.Time
+	if err := r.db.QueryRowContext(ctx, query, seriesID).Scan(&latest); err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// CreateOccurrence adds the occurrence of template's series due at dueAt,
+// copying the template's details, labels and members. It returns nil if the
+// series already has an occurrence at that time.
+func (r *TaskRepository) CreateOccurrence(ctx context.Context, template *models.Task, dueAt time.Time, status string) (*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	task := &models.Task{
+		Title:       template.Title,
+		Description: template.Description,
+		Status:      status,
+		Priority:    template.Priority,
+		DueAt:       &dueAt,
+		CompletedAt: completionTime(nil, status, now),
+		ParentID:    template.ParentID,
+		ProjectID:   template.ProjectID,
+		SeriesID:    &template.ID,
+		Labels:      template.Labels,
+		OwnerID:     template.OwnerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	const query = "INSERT INTO tasks (title, description, status, priority, due_at, completed_at, parent_id, project_id, series_id, owner_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT (series_id, due_at) DO NOTHING RETURNING id, version"
+	if err := tx.QueryRowContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Priority,
+		task.DueAt,
+		task.CompletedAt,
+		task.ParentID,
+		task.ProjectID,
+		task.SeriesID,
+		task.OwnerID,
+		task.CreatedAt,
+		task.UpdatedAt,
+	).Scan(&task.ID, &task.Version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	task.Overdue = task.IsOverdue(now)
+
+	const copyLabels = "INSERT INTO task_labels (task_id, label_id) SELECT $1, label_id FROM task_labels WHERE task_id = $2"
+	if _, err := tx.ExecContext(ctx, copyLabels, task.ID, template.ID); err != nil {
+		return nil, err
+	}
+
+	const copyMembers = "INSERT INTO task_members (task_id, user_id, role, created_at) SELECT $1, user_id, role, $3 FROM task_members WHERE task_id = $2"
+	if _, err := tx.ExecContext(ctx, copyMembers, task.ID, template.ID, now); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, task.ID, task.OwnerID, models.EventCreated, diffTasks(nil, task)); err != nil {
+		return nil, err
+	}
+
+	if err := touchParents(ctx, tx, task.ParentID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
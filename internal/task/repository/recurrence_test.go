@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOccurrence(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	dueAt := time.Now().Add(24 * time.Hour)
+	template := &models.Task{ID: 1, Title: "Chores", Priority: "medium", Recurrence: "FREQ=WEEKLY", OwnerID: 7}
+
+	const insert = "INSERT INTO tasks \\(title, description, status, priority, due_at, completed_at, parent_id, project_id, series_id, owner_id, created_at, updated_at\\) VALUES .+ ON CONFLICT \\(series_id, due_at\\) DO NOTHING RETURNING id, version"
+
+	t.Run("must copy the template with its labels and members", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).
+			WithArgs("Chores", "", "Pending", "medium", &dueAt, nil, nil, nil, &template.ID, int64(7), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(5, 1))
+		mock.ExpectExec("INSERT INTO task_labels \\(task_id, label_id\\) SELECT \\$1, label_id FROM task_labels WHERE task_id = \\$2").
+			WithArgs(int64(5), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO task_members \\(task_id, user_id, role, created_at\\) SELECT \\$1, user_id, role, \\$3 FROM task_members WHERE task_id = \\$2").
+			WithArgs(int64(5), int64(1), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO task_events").
+			WithArgs(int64(5), int64(7), models.EventCreated, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.CreateOccurrence(context.Background(), template, dueAt, "Pending")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), task.ID)
+		assert.Equal(t, int64(1), *task.SeriesID)
+		assert.Empty(t, task.Recurrence)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return nil if the occurrence already exists", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}))
+		mock.ExpectRollback()
+
+		task, err := repo.CreateOccurrence(context.Background(), template, dueAt, "Pending")
+		assert.NoError(t, err)
+		assert.Nil(t, task)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the labels cannot be copied", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(5, 1))
+		mock.ExpectExec("INSERT INTO task_labels").WillReturnError(errors.New("copy failed"))
+		mock.ExpectRollback()
+
+		_, err := repo.CreateOccurrence(context.Background(), template, dueAt, "Pending")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLatestOccurrence(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must include the template and trashed occurrences", func(t *testing.T) {
+		latest := time.Now()
+		mock.ExpectQuery("SELECT MAX\\(due_at\\) FROM tasks WHERE id = \\$1 OR series_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(latest))
+
+		got, err := repo.LatestOccurrence(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, latest, *got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,53 +1,181 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"html"
+	"strings"
 	"time"
+	"todo_list_api/internal/db"
 	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
 )
 
 type Repository interface {
-	CreateTask(task *models.Task) error
-	DeleteTask(id int64) error
-	GetTask(id int64) (*models.Task, error)
-	ListTasks() ([]*models.Task, error)
-	UpdateTask(task *models.Task) error
+	AddTaskDependency(ctx context.Context, dependency *models.TaskDependency) error
+	AddTaskMember(ctx context.Context, member *models.TaskMember) error
+	CountComments(ctx context.Context, taskID int64) (int, error)
+	CreateOccurrence(ctx context.Context, template *models.Task, dueAt time.Time, status string) (*models.Task, error)
+	CreateTask(ctx context.Context, task *models.Task) error
+	DeleteTask(ctx context.Context, actorID, id, version int64) error
+	GetProject(ctx context.Context, id int64) (*models.Project, error)
+	GetProjectMemberRole(ctx context.Context, projectID, userID int64) (string, error)
+	GetTask(ctx context.Context, id int64) (*models.Task, error)
+	GetTaskRole(ctx context.Context, userID, taskID int64) (string, error)
+	GetTaskProgress(ctx context.Context, id int64) (*models.TaskProgress, error)
+	ListBlockers(ctx context.Context, taskID, userID int64) ([]*models.TaskBlocker, error)
+	LatestOccurrence(ctx context.Context, seriesID int64) (*time.Time, error)
+	ListTaskEvents(ctx context.Context, taskID int64) ([]*models.TaskEvent, error)
+	ListTaskMembers(ctx context.Context, taskID int64) ([]*models.TaskMember, error)
+	ListRecurringTasks(ctx context.Context) ([]*models.Task, error)
+	ListSubtasks(ctx context.Context, id int64) ([]*models.Task, error)
+	ListTasks(ctx context.Context, filter models.TaskFilter) (*models.TaskPage, error)
+	ListTrash(ctx context.Context, userID int64) ([]*models.Task, error)
+	PatchTask(ctx context.Context, actorID, id, version int64, patch *models.TaskPatch, check TaskCheck) (*models.Task, error)
+	PurgeTasks(ctx context.Context, deletedBefore time.Time) (int64, []string, error)
+	RemoveTaskDependency(ctx context.Context, taskID, blockedByID int64) error
+	RemoveTaskMember(ctx context.Context, taskID, userID int64) error
+	RestoreTask(ctx context.Context, actorID, id int64) (*models.Task, error)
+	SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error)
+	UpdateTask(ctx context.Context, actorID int64, task *models.Task, check TaskCheck) error
+}
+
+// TaskCheck vets a write against the task as stored. UpdateTask and
+// PatchTask run it once they hold the lock on the task's row, so the task
+// cannot change between the check and the write.
+type TaskCheck func(before *models.Task) error
+
+// labelsColumn aggregates the labels attached to the current tasks row, so
+// every query selecting taskColumns returns them embedded.
+const labelsColumn = "COALESCE((SELECT json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) ORDER BY l.name) FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id), '[]') AS labels"
+
+const taskColumns = "id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, " + labelsColumn
+
+var sortColumns = map[string]string{
+	models.SortCreatedAt: "created_at",
+	models.SortUpdatedAt: "updated_at",
+	models.SortTitle:     "title",
+	models.SortDueAt:     "COALESCE(due_at, 'infinity')",
+	models.SortPriority:  "priority_rank",
 }
 
 type TaskRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewTaskRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &TaskRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask reads a row selected with taskColumns, followed by any extra
+// columns the caller appended to the select list.
+func scanTask(row scanner, task *models.Task, extra ...any) error {
+	var labels []byte
+	dest := []any{
+		&task.ID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.DueAt,
+		&task.CompletedAt,
+		&task.ParentID,
+		&task.ProjectID,
+		&task.ArchivedAt,
+		&task.Recurrence,
+		&task.SeriesID,
+		&task.OwnerID,
+		&task.Version,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&labels,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(labels, &task.Labels); err != nil {
+		return err
+	}
+
+	task.Overdue = task.IsOverdue(time.Now())
+	return nil
 }
 
-func NewTaskRepository(db *sql.DB) Repository {
-	return &TaskRepository{db: db}
+// completionTime derives completed_at for a task moving to status: it is kept
+// while the task stays completed and cleared when it is reopened.
+func completionTime(before *models.Task, status string, now time.Time) *time.Time {
+	if status != models.StatusCompleted {
+		return nil
+	}
+	if before != nil && before.CompletedAt != nil {
+		return before.CompletedAt
+	}
+	return &now
 }
 
-func (r *TaskRepository) CreateTask(task *models.Task) error {
-	const query = "INSERT INTO tasks (title, description, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+func (r *TaskRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const query = "INSERT INTO tasks (title, description, status, priority, due_at, completed_at, parent_id, project_id, recurrence, owner_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, version"
 	task.CreatedAt = time.Now()
-	task.UpdatedAt = time.Now()
-	return r.db.QueryRow(
+	task.UpdatedAt = task.CreatedAt
+	task.CompletedAt = completionTime(nil, task.Status, task.CreatedAt)
+	if err := tx.QueryRowContext(
+		ctx,
 		query,
 		task.Title,
 		task.Description,
 		task.Status,
+		task.Priority,
+		task.DueAt,
+		task.CompletedAt,
+		task.ParentID,
+		task.ProjectID,
+		task.Recurrence,
+		task.OwnerID,
 		task.CreatedAt,
 		task.UpdatedAt,
-	).Scan(&task.ID)
+	).Scan(&task.ID, &task.Version); err != nil {
+		return err
+	}
+	task.Overdue = task.IsOverdue(task.CreatedAt)
+
+	if err := recordEvent(ctx, tx, task.ID, task.OwnerID, models.EventCreated, diffTasks(nil, task)); err != nil {
+		return err
+	}
+
+	if err := touchParents(ctx, tx, task.ParentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TaskRepository) GetTask(id int64) (*models.Task, error) {
-	const query = "SELECT id, title, description, status, created_at, updated_at FROM tasks WHERE id = $1"
+func (r *TaskRepository) GetTask(ctx context.Context, id int64) (*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND deleted_at IS NULL"
 	task := &models.Task{}
-	if err := r.db.QueryRow(query, id).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	); err != nil {
+	if err := scanTask(r.db.QueryRowContext(ctx, query, id), task); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -56,52 +184,366 @@ func (r *TaskRepository) GetTask(id int64) (*models.Task, error) {
 	return task, nil
 }
 
-func (r *TaskRepository) UpdateTask(task *models.Task) error {
-	const query = "UPDATE tasks SET title = $2, description = $3, status = $4, created_at = $5, updated_at = $6  WHERE id = $1"
+func (r *TaskRepository) UpdateTask(ctx context.Context, actorID int64, task *models.Task, check TaskCheck) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockTask(ctx, tx, task.ID, task.Version)
+	if err != nil {
+		return err
+	}
+
+	if check != nil {
+		if err := check(before); err != nil {
+			return err
+		}
+	}
+
+	if err := checkOpenBlockers(ctx, tx, before, task.Status); err != nil {
+		return err
+	}
+
+	if !sameID(before.ParentID, task.ParentID) {
+		if err := checkParentCycle(ctx, tx, task.ID, task.ParentID); err != nil {
+			return err
+		}
+	}
+
+	// Tasks are only archived along with their project, so a task moved
+	// out of its project is reactivated by the same statement.
+	const query = "UPDATE tasks SET title = $2, description = $3, status = $4, priority = $5, due_at = $6, completed_at = $7, parent_id = $8, project_id = $9, archived_at = CASE WHEN project_id IS DISTINCT FROM $9 THEN NULL ELSE archived_at END, recurrence = $10, updated_at = $11, version = version + 1 WHERE id = $1 RETURNING archived_at, series_id, owner_id, version, created_at, " + labelsColumn
 	task.UpdatedAt = time.Now()
-	if _, err := r.db.Exec(
+	task.CompletedAt = completionTime(before, task.Status, task.UpdatedAt)
+	var labels []byte
+	if err := tx.QueryRowContext(
+		ctx,
 		query,
 		task.ID,
 		task.Title,
 		task.Description,
 		task.Status,
-		task.CreatedAt,
+		task.Priority,
+		task.DueAt,
+		task.CompletedAt,
+		task.ParentID,
+		task.ProjectID,
+		task.Recurrence,
 		task.UpdatedAt,
-	); err != nil {
+	).Scan(&task.ArchivedAt, &task.SeriesID, &task.OwnerID, &task.Version, &task.CreatedAt, &labels); err != nil {
 		return err
 	}
-	return nil
+	if err := json.Unmarshal(labels, &task.Labels); err != nil {
+		return err
+	}
+	task.Overdue = task.IsOverdue(task.UpdatedAt)
+
+	if err := recordEvent(ctx, tx, task.ID, actorID, models.EventUpdated, diffTasks(before, task)); err != nil {
+		return err
+	}
+
+	if before.Status != task.Status || !sameID(before.ParentID, task.ParentID) {
+		if err := touchParents(ctx, tx, before.ParentID, task.ParentID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *TaskRepository) DeleteTask(id int64) error {
-	const query = "DELETE FROM tasks WHERE id = $1"
-	if _, err := r.db.Exec(query, id); err != nil {
+func (r *TaskRepository) DeleteTask(ctx context.Context, actorID, id, version int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockTask(ctx, tx, id, version)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	const query = "UPDATE tasks SET deleted_at = $2, version = version + 1 WHERE id = $1"
+	deletedAt := time.Now()
+	if _, err := tx.ExecContext(ctx, query, id, deletedAt); err != nil {
+		return err
+	}
+
+	changes := map[string]models.FieldChange{"deleted_at": {After: deletedAt}}
+	if err := recordEvent(ctx, tx, id, actorID, models.EventDeleted, changes); err != nil {
+		return err
+	}
+
+	if err := touchParents(ctx, tx, before.ParentID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TaskRepository) ListTasks() ([]*models.Task, error) {
-	const query = "SELECT id, title, description, status, created_at, updated_at FROM tasks"
-	rows, err := r.db.Query(query)
+func (r *TaskRepository) ListTasks(ctx context.Context, filter models.TaskFilter) (*models.TaskPage, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	sortColumn, ok := sortColumns[filter.Sort]
+	if !ok {
+		return nil, utils.ErrInvalidSort
+	}
+
+	direction := "ASC"
+	comparison := ">"
+	if filter.Order == models.OrderDesc {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	addCondition("id IN (SELECT task_id FROM task_access WHERE user_id = $%d)", filter.UserID)
+	conditions = append(conditions, "deleted_at IS NULL")
+	if filter.Archived {
+		conditions = append(conditions, "archived_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	if filter.ProjectID != nil {
+		addCondition("project_id = $%d", *filter.ProjectID)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.Priority != "" {
+		addCondition("priority = $%d", filter.Priority)
+	}
+	if len(filter.Labels) > 0 {
+		// Both modes join task_labels to the user's own labels by name, as
+		// a shared task may carry another owner's label of the same name;
+		// "all" further requires every requested name to be present on the
+		// task.
+		args = append(args, filter.UserID, pq.Array(filter.Labels))
+		labeled := fmt.Sprintf("id IN (SELECT tl.task_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE l.owner_id = $%d AND l.name = ANY($%d)", len(args)-1, len(args))
+		if filter.LabelMatch == models.LabelMatchAll {
+			args = append(args, len(filter.Labels))
+			labeled += fmt.Sprintf(" GROUP BY tl.task_id HAVING COUNT(DISTINCT l.name) = $%d", len(args))
+		}
+		conditions = append(conditions, labeled+")")
+	}
+	if filter.DueAfter != nil {
+		addCondition("due_at >= $%d", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		addCondition("due_at < $%d", *filter.DueBefore)
+	}
+	if filter.Overdue != nil {
+		if *filter.Overdue {
+			addCondition("(due_at < $%d AND completed_at IS NULL)", time.Now())
+		} else {
+			addCondition("(due_at IS NULL OR due_at >= $%d OR completed_at IS NOT NULL)", time.Now())
+		}
+	}
+	if filter.CreatedAfter != nil {
+		addCondition("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		addCondition("created_at < $%d", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		addCondition("updated_at >= $%d", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		addCondition("updated_at < $%d", *filter.UpdatedBefore)
+	}
+
+	if filter.Cursor != "" {
+		value, id, err := decodeCursor(filter.Sort, filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value, id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(conditions, " AND ")
+	args = append(args, filter.Limit+1)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", sortColumn, direction, direction, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	var tasks []*models.Task
+	defer rows.Close()
+
+	tasks := []*models.Task{}
 	for rows.Next() {
 		var task models.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanTask(rows, &task); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, &task)
 	}
-	return tasks, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.TaskPage{Tasks: tasks}
+	if len(tasks) > filter.Limit {
+		page.Tasks = tasks[:filter.Limit]
+		page.NextCursor = encodeCursor(filter.Sort, page.Tasks[len(page.Tasks)-1])
+	}
+
+	return page, nil
+}
+
+// ts_headline wraps matches in these control characters, which are stripped
+// from the text beforehand, so that highlight can escape the user's text and
+// only then turn them into markup.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+var highlighter = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlight turns a headline into HTML that is safe to render.
+func highlight(headline string) string {
+	return highlighter.Replace(html.EscapeString(headline))
+}
+
+func (r *TaskRepository) SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const searchQuery = `SELECT ` + taskColumns + `,
+	ts_rank(search_vector, q) AS rank,
+	ts_headline('simple', translate(title || ' ' || description, chr(2) || chr(3), ''), q, 'StartSel="' || chr(2) || '", StopSel="' || chr(3) || '", MaxFragments=2') AS snippet
+FROM tasks, websearch_to_tsquery('simple', $1) q
+WHERE search_vector @@ q AND deleted_at IS NULL AND id IN (SELECT task_id FROM task_access WHERE user_id = $2)
+ORDER BY rank DESC, id ASC
+LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, searchQuery, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.TaskSearchResult{}
+	for rows.Next() {
+		var result models.TaskSearchResult
+		if err := scanTask(rows, &result.Task, &result.Rank, &result.Snippet); err != nil {
+			return nil, err
+		}
+		result.Snippet = highlight(result.Snippet)
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+func (r *TaskRepository) PatchTask(ctx context.Context, actorID, id, version int64, patch *models.TaskPatch, check TaskCheck) (*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockTask(ctx, tx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if check != nil {
+		if err := check(before); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.Status != nil {
+		if err := checkOpenBlockers(ctx, tx, before, *patch.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.ParentID != nil && !sameID(before.ParentID, *patch.ParentID) {
+		if err := checkParentCycle(ctx, tx, id, *patch.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	args := []any{id}
+	var assignments []string
+	set := func(column string, value any) {
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	now := time.Now()
+	if patch.Status != nil {
+		set("status", *patch.Status)
+		set("completed_at", completionTime(before, *patch.Status, now))
+	}
+	if patch.Priority != nil {
+		set("priority", *patch.Priority)
+	}
+	if patch.DueAt != nil {
+		set("due_at", *patch.DueAt)
+	}
+	if patch.ParentID != nil {
+		set("parent_id", *patch.ParentID)
+	}
+	if patch.ProjectID != nil {
+		set("project_id", *patch.ProjectID)
+		// A task moved out of the project it was archived with is
+		// reactivated, as in UpdateTask.
+		assignments = append(assignments, fmt.Sprintf("archived_at = CASE WHEN project_id IS DISTINCT FROM $%d THEN NULL ELSE archived_at END", len(args)))
+	}
+	if patch.Recurrence != nil {
+		set("recurrence", *patch.Recurrence)
+	}
+	set("updated_at", now)
+	assignments = append(assignments, "version = version + 1")
+
+	query := "UPDATE tasks SET " + strings.Join(assignments, ", ") + " WHERE id = $1 RETURNING " + taskColumns
+
+	task := &models.Task{}
+	if err := scanTask(tx.QueryRowContext(ctx, query, args...), task); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, id, actorID, models.EventUpdated, diffTasks(before, task)); err != nil {
+		return nil, err
+	}
+
+	if task.Status != before.Status || !sameID(before.ParentID, task.ParentID) {
+		if err := touchParents(ctx, tx, before.ParentID, task.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
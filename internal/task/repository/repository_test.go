@@ -1,13 +1,17 @@
 package repository_test
 
 import (
+	"context"
 	"errors"
+	"regexp"
 	"testing"
 	"time"
 	"todo_list_api/internal/task/repository"
 	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,30 +20,44 @@ func TestCreateTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := repository.NewTaskRepository(db)
+	repo := repository.NewTaskRepository(db, time.Second)
 
 	t.Run("must validate the query and if query is valid, create a new task in the tasks table", func(t *testing.T) {
 		task := &models.Task{
 			Title:       "Test Task",
 			Description: "Test Description",
 			Status:      "Pending",
+			OwnerID:     7,
 		}
 
-		const query = "INSERT INTO tasks \\(title, description, status, created_at, updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING id"
+		const query = "INSERT INTO tasks \\(title, description, status, priority, due_at, completed_at, parent_id, project_id, recurrence, owner_id, created_at, updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10, \\$11, \\$12\\) RETURNING id, version"
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(query).
 			WithArgs(
 				task.Title,
 				task.Description,
 				task.Status,
+				task.Priority,
+				task.DueAt,
+				task.CompletedAt,
+				task.ParentID,
+				task.ProjectID,
+				task.Recurrence,
+				task.OwnerID,
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 			).
-			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+		mock.ExpectExec("WITH events AS \\(INSERT INTO task_events \\(task_id, actor_id, action, changes, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING .+\\) INSERT INTO outbox \\(idempotency_key, topic, task_id, payload, created_at\\) SELECT 'task-event-' \\|\\| e.id, 'task.' \\|\\| e.action, .+ FROM events e").
+			WithArgs(int64(1), int64(7), models.EventCreated, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		err = repo.CreateTask(task)
+		err = repo.CreateTask(context.Background(), task)
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), task.ID)
+		assert.Equal(t, int64(1), task.Version)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -48,21 +66,29 @@ func TestCreateTask(t *testing.T) {
 			Title:       "Test Task",
 			Description: "Test Description",
 			Status:      "Pending",
+			OwnerID:     7,
 		}
 
-		const query = "INSERT INTO tasks (title, description, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+		const query = "INSERT INTO tasks (title, description, status, priority, due_at, completed_at, parent_id, project_id, recurrence, owner_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, version"
 
 		mock.ExpectQuery(query).
 			WithArgs(
 				task.Title,
 				task.Description,
 				task.Status,
+				task.Priority,
+				task.DueAt,
+				task.CompletedAt,
+				task.ParentID,
+				task.ProjectID,
+				task.Recurrence,
+				task.OwnerID,
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 			).
 			WillReturnError(errors.New("query invalid"))
 
-		err = repo.CreateTask(task)
+		err = repo.CreateTask(context.Background(), task)
 		assert.Error(t, err)
 		assert.Error(t, mock.ExpectationsWereMet())
 	})
@@ -73,21 +99,25 @@ func TestGetTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := repository.NewTaskRepository(db)
+	repo := repository.NewTaskRepository(db, time.Second)
 
 	t.Run("should validate the query and if the query is valid, return a task by id", func(t *testing.T) {
-		columns := []string{"id", "title", "description", "status", "created_at", "updated_at"}
+		columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
 
 		expectedTask := &models.Task{
 			ID:          1,
 			Title:       "Test Task",
 			Description: "Test Description",
 			Status:      "Pending",
+			Priority:    "medium",
+			Labels:      []models.TaskLabel{{ID: 3, Name: "work", Color: "#ff0000"}},
+			OwnerID:     7,
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
 
-		const query = "SELECT id, title, description, status, created_at, updated_at FROM tasks WHERE id = \\$1"
+		const query = "SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id = \\$1 AND deleted_at IS NULL"
 
 		mock.ExpectQuery(query).
 			WithArgs(expectedTask.ID).
@@ -97,12 +127,23 @@ func TestGetTask(t *testing.T) {
 					expectedTask.Title,
 					expectedTask.Description,
 					expectedTask.Status,
+					expectedTask.Priority,
+					expectedTask.DueAt,
+					expectedTask.CompletedAt,
+					expectedTask.ParentID,
+					expectedTask.ProjectID,
+					expectedTask.ArchivedAt,
+					expectedTask.Recurrence,
+					expectedTask.SeriesID,
+					expectedTask.OwnerID,
+					expectedTask.Version,
 					expectedTask.CreatedAt,
 					expectedTask.UpdatedAt,
+					`[{"id":3,"name":"work","color":"#ff0000"}]`,
 				),
 			)
 
-		task, err := repo.GetTask(expectedTask.ID)
+		task, err := repo.GetTask(context.Background(), expectedTask.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedTask, task)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -111,78 +152,189 @@ func TestGetTask(t *testing.T) {
 	t.Run("should validate the query and return a error if the query is invalid", func(t *testing.T) {
 		expectedTask := &models.Task{ID: 1}
 
-		const query = "SELECT id, title, description, status, created_at, updated_at FROM tasks WHERE id = $1"
+		const query = "SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id = $1 AND deleted_at IS NULL"
 
 		mock.ExpectQuery(query).
 			WithArgs(expectedTask.ID).
 			WillReturnError(errors.New("query invalid"))
 
-		_, err := repo.GetTask(expectedTask.ID)
+		_, err := repo.GetTask(context.Background(), expectedTask.ID)
 		assert.Error(t, err)
 		assert.Error(t, mock.ExpectationsWereMet())
 	})
 }
 
+func TestQueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, 10*time.Millisecond)
+
+	t.Run("should cancel the query when the timeout elapses", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id = \\$1 AND deleted_at IS NULL").
+			WithArgs(int64(1)).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		start := time.Now()
+		_, err := repo.GetTask(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("should stop the query when the caller context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := repo.DeleteTask(ctx, 7, 1, 0)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func TestUpdateTask(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := repository.NewTaskRepository(db)
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const lock = "SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE"
+	const query = "UPDATE tasks SET title = \\$2, description = \\$3, status = \\$4, priority = \\$5, due_at = \\$6, completed_at = \\$7, parent_id = \\$8, project_id = \\$9, archived_at = CASE WHEN project_id IS DISTINCT FROM \\$9 THEN NULL ELSE archived_at END, recurrence = \\$10, updated_at = \\$11, version = version \\+ 1 WHERE id = \\$1 RETURNING archived_at, series_id, owner_id, version, created_at"
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+	createdAt := time.Now().Add(-24 * time.Hour)
+
+	expectLock := func(id, version int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "Old Task", "Test Description", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, version, createdAt, createdAt, "[]"))
+	}
 
 	t.Run("must be a valid query and if the query is valid, return an updated task", func(t *testing.T) {
 		taskUpdated := &models.Task{
 			ID:          1,
 			Title:       "Test Task",
 			Description: "Test Description",
-			Status:      "Pending",
-			CreatedAt:   time.Now().Add(-24 * time.Hour),
-			UpdatedAt:   time.Now(),
+			Status:      "In progress",
+			Priority:    "medium",
 		}
 
-		const query = "UPDATE tasks SET title = \\$2, description = \\$3, status = \\$4, created_at = \\$5, updated_at = \\$6 WHERE id = \\$1"
-
-		mock.ExpectExec(query).
+		expectLock(1, 1)
+		mock.ExpectQuery(query).
 			WithArgs(
 				taskUpdated.ID,
 				taskUpdated.Title,
 				taskUpdated.Description,
 				taskUpdated.Status,
-				taskUpdated.CreatedAt,
+				taskUpdated.Priority,
+				nil,
+				nil,
+				nil,
+				nil,
+				"",
 				sqlmock.AnyArg(),
-			).WillReturnResult(sqlmock.NewResult(0, 1))
+			).WillReturnRows(sqlmock.NewRows([]string{"archived_at", "series_id", "owner_id", "version", "created_at", "labels"}).AddRow(nil, nil, 7, 2, createdAt, "[]"))
+		mock.ExpectExec("INSERT INTO task_events").
+			WithArgs(int64(1), int64(8), models.EventUpdated, `{"status":{"before":"Pending","after":"In progress"},"title":{"before":"Old Task","after":"Test Task"}}`, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		err = repo.UpdateTask(taskUpdated)
+		err = repo.UpdateTask(context.Background(), 8, taskUpdated, nil)
 		assert.NoError(t, err)
 		assert.WithinDuration(t, time.Now(), taskUpdated.UpdatedAt, time.Second)
+		assert.Equal(t, createdAt, taskUpdated.CreatedAt)
+		assert.Equal(t, int64(7), taskUpdated.OwnerID)
+		assert.Equal(t, int64(2), taskUpdated.Version)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should validate the query and return a error if the query is invalid", func(t *testing.T) {
+	t.Run("should not record an event if no audited field changed", func(t *testing.T) {
+		taskUpdated := &models.Task{ID: 1, Title: "Old Task", Description: "Test Description", Status: "Pending", Priority: "medium", CreatedAt: time.Time{}}
+
+		expectLock(1, 1)
+		mock.ExpectQuery(query).
+			WithArgs(taskUpdated.ID, taskUpdated.Title, taskUpdated.Description, taskUpdated.Status, taskUpdated.Priority, nil, nil, nil, nil, "", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"archived_at", "series_id", "owner_id", "version", "created_at", "labels"}).AddRow(nil, nil, 7, 2, createdAt, "[]"))
+		mock.ExpectCommit()
+
+		err = repo.UpdateTask(context.Background(), 7, taskUpdated, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, taskUpdated.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task does not exist", func(t *testing.T) {
+		taskUpdated := &models.Task{ID: 999, Title: "Test Task", Status: "Pending"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(999)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		err = repo.UpdateTask(context.Background(), 7, taskUpdated, nil)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrPreconditionFailed if the task moved past the expected version", func(t *testing.T) {
+		taskUpdated := &models.Task{ID: 1, Title: "Test Task", Status: "Pending", Version: 3}
+
+		expectLock(1, 4)
+		mock.ExpectRollback()
+
+		err = repo.UpdateTask(context.Background(), 7, taskUpdated, nil)
+		assert.ErrorIs(t, err, utils.ErrPreconditionFailed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should run the check against the locked row and roll back if it fails", func(t *testing.T) {
+		taskUpdated := &models.Task{ID: 1, Title: "Test Task", Status: "Completed"}
+
+		var seen *models.Task
+		check := func(before *models.Task) error {
+			seen = before
+			return utils.ErrInvalidTransition
+		}
+
+		expectLock(1, 1)
+		mock.ExpectRollback()
+
+		err = repo.UpdateTask(context.Background(), 7, taskUpdated, check)
+		assert.ErrorIs(t, err, utils.ErrInvalidTransition)
+		assert.Equal(t, "Pending", seen.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back and return a error if the query is invalid", func(t *testing.T) {
 		taskUpdated := &models.Task{
 			ID:          1,
 			Title:       "Test Task",
 			Description: "Test Description",
 			Status:      "Pending",
-			CreatedAt:   time.Now().Add(-24 * time.Hour),
-			UpdatedAt:   time.Now(),
 		}
 
-		const query = "UPDATE tasks SET title = $2, description = $3, status = $4, created_at = $5, updated_at = $6 WHERE id = $1"
-
-		mock.ExpectExec(query).
+		expectLock(1, 1)
+		mock.ExpectQuery(query).
 			WithArgs(
 				taskUpdated.ID,
 				taskUpdated.Title,
 				taskUpdated.Description,
 				taskUpdated.Status,
-				taskUpdated.CreatedAt,
+				taskUpdated.Priority,
+				nil,
+				nil,
+				nil,
+				nil,
+				"",
 				sqlmock.AnyArg(),
 			).WillReturnError(errors.New("query invalid"))
+		mock.ExpectRollback()
 
-		err = repo.UpdateTask(taskUpdated)
+		err = repo.UpdateTask(context.Background(), 7, taskUpdated, nil)
 		assert.Error(t, err)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -191,34 +343,84 @@ func TestDeleteTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := repository.NewTaskRepository(db)
+	repo := repository.NewTaskRepository(db, time.Second)
 
-	t.Run("must validate the query and if the query is valid, delete the task from the tasks table", func(t *testing.T) {
-		const id = 1
+	const lock = "FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE"
+	const query = "UPDATE tasks SET deleted_at = \\$2, version = version \\+ 1 WHERE id = \\$1"
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
 
-		const query = "DELETE FROM tasks WHERE id = \\$1"
+	expectLock := func(id, version int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, version, time.Now(), time.Now(), "[]"))
+	}
 
+	t.Run("must validate the query and if the query is valid, move the task to the trash", func(t *testing.T) {
+		expectLock(1, 1)
 		mock.ExpectExec(query).
-			WithArgs(id).
+			WithArgs(int64(1), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO task_events").
+			WithArgs(int64(1), int64(7), models.EventDeleted, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		err := repo.DeleteTask(id)
+		err := repo.DeleteTask(context.Background(), 7, 1, 0)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should validate the query and return a error if the query is invalid", func(t *testing.T) {
-		const id = 1
+	t.Run("must bump the parent's version when a subtask moves to the trash", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "Subtask", "", "Pending", "medium", nil, nil, 1, nil, nil, "", nil, 7, 1, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec(query).
+			WithArgs(int64(2), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO task_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE tasks SET version = version + 1 WHERE id = ANY($1)")).
+			WithArgs(pq.Array([]int64{1})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
-		const query = "DELETE FROM tasks WHERE id = $1"
+		err := repo.DeleteTask(context.Background(), 7, 2, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task does not exist", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(999)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		err := repo.DeleteTask(context.Background(), 7, 999, 0)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrPreconditionFailed if the version does not match", func(t *testing.T) {
+		expectLock(1, 4)
+		mock.ExpectRollback()
+
+		err := repo.DeleteTask(context.Background(), 7, 1, 3)
+		assert.ErrorIs(t, err, utils.ErrPreconditionFailed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 
+	t.Run("should roll back and return a error if the query is invalid", func(t *testing.T) {
+		expectLock(1, 1)
 		mock.ExpectExec(query).
-			WithArgs(id).
+			WithArgs(int64(1), sqlmock.AnyArg()).
 			WillReturnError(errors.New("query invalid"))
+		mock.ExpectRollback()
 
-		err := repo.DeleteTask(id)
+		err := repo.DeleteTask(context.Background(), 7, 1, 0)
 		assert.Error(t, err)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -227,77 +429,375 @@ func TestListTasks(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := repository.NewTaskRepository(db)
+	repo := repository.NewTaskRepository(db, time.Second)
 
-	t.Run("must validate the query and if the query is valid, return a list of tasks from the tasks table", func(t *testing.T) {
-		columns := []string{"id", "title", "description", "status", "created_at", "updated_at"}
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
 
+	t.Run("must validate the query and if the query is valid, return a list of tasks from the tasks table", func(t *testing.T) {
 		task := models.Task{
 			ID:          1,
 			Title:       "Task",
 			Description: "Description",
 			Status:      "Pending",
+			Priority:    "medium",
+			OwnerID:     7,
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
 
-		const query = "SELECT id, title, description, status, created_at, updated_at FROM tasks"
+		const query = "SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$1\\) AND deleted_at IS NULL AND archived_at IS NULL ORDER BY created_at ASC, id ASC LIMIT \\$2"
 
 		mock.ExpectQuery(query).
-			WithArgs().
+			WithArgs(int64(7), 21).
 			WillReturnRows(sqlmock.NewRows(columns).
 				AddRow(
 					task.ID,
 					task.Title,
 					task.Description,
 					task.Status,
+					task.Priority,
+					task.DueAt,
+					task.CompletedAt,
+					task.ParentID,
+					task.ProjectID,
+					task.ArchivedAt,
+					task.Recurrence,
+					task.SeriesID,
+					task.OwnerID,
+					task.Version,
 					task.CreatedAt,
 					task.UpdatedAt,
+					"[]",
 				),
 			)
 
-		taskResult, err := repo.ListTasks()
+		page, err := repo.ListTasks(context.Background(), models.TaskFilter{UserID: 7, Sort: models.SortCreatedAt, Order: models.OrderAsc, Limit: 20})
 		assert.NoError(t, err)
-		assert.Equal(t, int64(1), taskResult[0].ID)
-		assert.Equal(t, "Task", taskResult[0].Title)
-		assert.Equal(t, "Description", taskResult[0].Description)
-		assert.Equal(t, "Pending", taskResult[0].Status)
+		assert.Len(t, page.Tasks, 1)
+		assert.Equal(t, int64(1), page.Tasks[0].ID)
+		assert.Equal(t, "Task", page.Tasks[0].Title)
+		assert.Equal(t, "Description", page.Tasks[0].Description)
+		assert.Equal(t, "Pending", page.Tasks[0].Status)
+		assert.Equal(t, int64(7), page.Tasks[0].OwnerID)
+		assert.Empty(t, page.NextCursor)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should validate the query and return a error if the query is invalid", func(t *testing.T) {
-		columns := []string{"id", "title", "description", "status", "created_at", "updated_at"}
+	t.Run("must push the filters and sorting down into the query", func(t *testing.T) {
+		createdAfter := time.Now().Add(-48 * time.Hour)
+		updatedBefore := time.Now()
 
-		task := models.Task{
-			ID:          1,
-			Title:       "Task",
-			Description: "Description",
-			Status:      "Pending",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		}
+		const query = "SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks WHERE id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$1\\) AND deleted_at IS NULL AND archived_at IS NULL AND status = \\$2 AND created_at >= \\$3 AND updated_at < \\$4 ORDER BY title DESC, id DESC LIMIT \\$5"
+
+		mock.ExpectQuery(query).
+			WithArgs(int64(7), "Pending", createdAfter, updatedBefore, 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		page, err := repo.ListTasks(context.Background(), models.TaskFilter{
+			UserID:        7,
+			Status:        "Pending",
+			CreatedAfter:  &createdAfter,
+			UpdatedBefore: &updatedBefore,
+			Sort:          models.SortTitle,
+			Order:         models.OrderDesc,
+			Limit:         10,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, page.Tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must match tasks carrying any of the user's requested labels", func(t *testing.T) {
+		mock.ExpectQuery("AND archived_at IS NULL AND id IN \\(SELECT tl.task_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE l.owner_id = \\$2 AND l.name = ANY\\(\\$3\\)\\) ORDER BY created_at ASC, id ASC LIMIT \\$4").
+			WithArgs(int64(7), int64(7), pq.Array([]string{"work", "home"}), 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.ListTasks(context.Background(), models.TaskFilter{
+			UserID:     7,
+			Labels:     []string{"work", "home"},
+			LabelMatch: models.LabelMatchAny,
+			Sort:       models.SortCreatedAt,
+			Order:      models.OrderAsc,
+			Limit:      10,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must list the archived tasks of a project", func(t *testing.T) {
+		projectID := int64(4)
+		mock.ExpectQuery("AND deleted_at IS NULL AND archived_at IS NOT NULL AND project_id = \\$2 ORDER BY created_at ASC, id ASC LIMIT \\$3").
+			WithArgs(int64(7), projectID, 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.ListTasks(context.Background(), models.TaskFilter{
+			UserID:    7,
+			ProjectID: &projectID,
+			Archived:  true,
+			Sort:      models.SortCreatedAt,
+			Order:     models.OrderAsc,
+			Limit:     10,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must require every requested label in all mode", func(t *testing.T) {
+		mock.ExpectQuery("WHERE l.owner_id = \\$2 AND l.name = ANY\\(\\$3\\) GROUP BY tl.task_id HAVING COUNT\\(DISTINCT l.name\\) = \\$4\\) ORDER BY created_at ASC, id ASC LIMIT \\$5").
+			WithArgs(int64(7), int64(7), pq.Array([]string{"work", "home"}), 2, 11).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.ListTasks(context.Background(), models.TaskFilter{
+			UserID:     7,
+			Labels:     []string{"work", "home"},
+			LabelMatch: models.LabelMatchAll,
+			Sort:       models.SortCreatedAt,
+			Order:      models.OrderAsc,
+			Limit:      10,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must return a next cursor when there are more rows and use it to fetch the next page", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "Task 1", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]").
+			AddRow(2, "Task 2", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now.Add(time.Second), now, "[]").
+			AddRow(3, "Task 3", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now.Add(2*time.Second), now, "[]")
+
+		mock.ExpectQuery("WHERE id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$1\\) AND deleted_at IS NULL AND archived_at IS NULL ORDER BY created_at ASC, id ASC LIMIT \\$2").
+			WithArgs(int64(7), 3).
+			WillReturnRows(rows)
+
+		filter := models.TaskFilter{UserID: 7, Sort: models.SortCreatedAt, Order: models.OrderAsc, Limit: 2}
+		page, err := repo.ListTasks(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 2)
+		assert.NotEmpty(t, page.NextCursor)
+
+		mock.ExpectQuery("WHERE id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$1\\) AND deleted_at IS NULL AND archived_at IS NULL AND \\(created_at, id\\) > \\(\\$2, \\$3\\) ORDER BY created_at ASC, id ASC LIMIT \\$4").
+			WithArgs(int64(7), sqlmock.AnyArg(), int64(2), 3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "Task 3", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now.Add(2*time.Second), now, "[]"))
 
-		const query = "SELECT id, title, description, status, created_at, updated_at FROM "
+		filter.Cursor = page.NextCursor
+		page, err = repo.ListTasks(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 1)
+		assert.Equal(t, int64(3), page.Tasks[0].ID)
+		assert.Empty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must filter by priority, due window and overdue state", func(t *testing.T) {
+		dueAfter := time.Now()
+		dueBefore := dueAfter.Add(72 * time.Hour)
+		overdue := true
+
+		const query = "AND deleted_at IS NULL AND archived_at IS NULL AND priority = \\$2 AND due_at >= \\$3 AND due_at < \\$4 AND \\(due_at < \\$5 AND completed_at IS NULL\\) ORDER BY COALESCE\\(due_at, 'infinity'\\) ASC, id ASC LIMIT \\$6"
 
 		mock.ExpectQuery(query).
-			WithArgs().
+			WithArgs(int64(7), models.PriorityHigh, dueAfter, dueBefore, sqlmock.AnyArg(), 21).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "high", dueAfter.Add(-time.Hour), nil, nil, nil, nil, "", nil, 7, 1, dueAfter, dueAfter, "[]"))
+
+		page, err := repo.ListTasks(context.Background(), models.TaskFilter{
+			UserID:    7,
+			Priority:  models.PriorityHigh,
+			DueAfter:  &dueAfter,
+			DueBefore: &dueBefore,
+			Overdue:   &overdue,
+			Sort:      models.SortDueAt,
+			Order:     models.OrderAsc,
+			Limit:     20,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 1)
+		assert.True(t, page.Tasks[0].Overdue)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must page by priority rank", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("ORDER BY priority_rank DESC, id DESC LIMIT \\$2").
+			WithArgs(int64(7), 2).
 			WillReturnRows(sqlmock.NewRows(columns).
-				AddRow(
-					task.ID,
-					task.Title,
-					task.Description,
-					task.Status,
-					task.CreatedAt,
-					task.UpdatedAt,
-				),
+				AddRow(4, "Task 4", "", "Pending", "urgent", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]").
+				AddRow(3, "Task 3", "", "Pending", "high", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]"))
+
+		filter := models.TaskFilter{UserID: 7, Sort: models.SortPriority, Order: models.OrderDesc, Limit: 1}
+		page, err := repo.ListTasks(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, page.NextCursor)
+
+		mock.ExpectQuery("AND \\(priority_rank, id\\) < \\(\\$2, \\$3\\) ORDER BY priority_rank DESC, id DESC").
+			WithArgs(int64(7), 4, int64(4), 2).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		filter.Cursor = page.NextCursor
+		_, err = repo.ListTasks(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return an error if the cursor is invalid", func(t *testing.T) {
+		_, err := repo.ListTasks(context.Background(), models.TaskFilter{Sort: models.SortCreatedAt, Limit: 20, Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+
+	t.Run("should validate the query and return a error if the query is invalid", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels FROM tasks").
+			WillReturnError(errors.New("query invalid"))
+
+		_, err := repo.ListTasks(context.Background(), models.TaskFilter{Sort: models.SortCreatedAt, Limit: 20})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSearchTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must rank the matching tasks and return highlighted snippets", func(t *testing.T) {
+		columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels", "rank", "snippet"}
+		now := time.Now()
+
+		mock.ExpectQuery("FROM tasks, websearch_to_tsquery\\('simple', \\$1\\) q WHERE search_vector @@ q AND deleted_at IS NULL AND id IN \\(SELECT task_id FROM task_access WHERE user_id = \\$2\\) ORDER BY rank DESC, id ASC LIMIT \\$3").
+			WithArgs("deploy", int64(7), 20).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, "Deploy API", "<b>Ship</b> it", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]", 0.6, "\x02Deploy\x03 API <b>Ship</b> it").
+				AddRow(1, "Review", "Review the deploy", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]", 0.2, "Review the \x02deploy\x03"),
 			)
 
-		taskResult, err := repo.ListTasks()
+		results, err := repo.SearchTasks(context.Background(), 7, "deploy", 20)
 		assert.NoError(t, err)
-		assert.Equal(t, int64(1), taskResult[0].ID)
-		assert.Equal(t, "Task", taskResult[0].Title)
-		assert.Equal(t, "Description", taskResult[0].Description)
-		assert.Equal(t, "Pending", taskResult[0].Status)
+		assert.Len(t, results, 2)
+		assert.Equal(t, int64(2), results[0].ID)
+		assert.Equal(t, 0.6, results[0].Rank)
+		assert.Equal(t, "<mark>Deploy</mark> API &lt;b&gt;Ship&lt;/b&gt; it", results[0].Snippet)
+		assert.Equal(t, "Review the <mark>deploy</mark>", results[1].Snippet)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return a error if the query fails", func(t *testing.T) {
+		mock.ExpectQuery("websearch_to_tsquery").
+			WithArgs("deploy", int64(7), 20).
+			WillReturnError(errors.New("query invalid"))
+
+		_, err := repo.SearchTasks(context.Background(), 7, "deploy", 20)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPatchTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+
+	expectLock := func(id int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, time.Now(), time.Now(), "[]"))
+	}
+
+	t.Run("should update only the supplied columns and record the diff", func(t *testing.T) {
+		status := "In progress"
+		const query = "UPDATE tasks SET status = \\$2, completed_at = \\$3, updated_at = \\$4, version = version \\+ 1 WHERE id = \\$1 RETURNING id, title, description, status, priority, due_at, completed_at, parent_id, project_id, archived_at, recurrence, series_id, owner_id, version, created_at, updated_at, .+ AS labels"
+
+		expectLock(1)
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), status, nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", status, "medium", nil, nil, nil, nil, nil, "", nil, 7, 2, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec("INSERT INTO task_events").
+			WithArgs(int64(1), int64(8), models.EventUpdated, `{"status":{"before":"Pending","after":"In progress"}}`, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.PatchTask(context.Background(), 8, 1, 0, &models.TaskPatch{Status: &status}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, status, task.Status)
+		assert.Equal(t, "Task", task.Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should stamp completed_at when the status becomes Completed", func(t *testing.T) {
+		status := models.StatusCompleted
+		completedAt := time.Now()
+
+		expectLock(1)
+		mock.ExpectQuery("SELECT id FROM tasks WHERE id IN \\(SELECT blocked_by_id FROM task_dependencies").
+			WithArgs(int64(1), status).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery("UPDATE tasks SET status = \\$2, completed_at = \\$3, updated_at = \\$4").
+			WithArgs(int64(1), status, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", status, "medium", nil, completedAt, nil, nil, nil, "", nil, 7, 2, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec("INSERT INTO task_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.PatchTask(context.Background(), 8, 1, 0, &models.TaskPatch{Status: &status}, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, task.CompletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should set every supplied column in a stable order", func(t *testing.T) {
+		title, description := "Title", "Description"
+		const query = "UPDATE tasks SET title = \\$2, description = \\$3, updated_at = \\$4, version = version \\+ 1 WHERE id = \\$1 RETURNING"
+
+		expectLock(1)
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), title, description, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, title, description, "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 2, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec("INSERT INTO task_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		_, err := repo.PatchTask(context.Background(), 7, 1, 0, &models.TaskPatch{Title: &title, Description: &description}, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should reactivate a task moved out of the project it was archived with", func(t *testing.T) {
+		archivedAt := time.Now().Add(-time.Hour)
+		var project *int64
+		const query = "UPDATE tasks SET project_id = \\$2, archived_at = CASE WHEN project_id IS DISTINCT FROM \\$2 THEN NULL ELSE archived_at END, updated_at = \\$3, version = version \\+ 1 WHERE id = \\$1 RETURNING"
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, nil, 4, archivedAt, "", nil, 7, 1, time.Now(), time.Now(), "[]"))
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 2, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec("INSERT INTO task_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.PatchTask(context.Background(), 7, 1, 0, &models.TaskPatch{ProjectID: &project}, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, task.ProjectID)
+		assert.Nil(t, task.ArchivedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task does not exist", func(t *testing.T) {
+		status := "Completed"
+		mock.ExpectBegin()
+		mock.ExpectQuery("FOR UPDATE").
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		task, err := repo.PatchTask(context.Background(), 7, 99, 0, &models.TaskPatch{Status: &status}, nil)
+		assert.Nil(t, task)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
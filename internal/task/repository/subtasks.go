@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// ListSubtasks returns every live descendant of a task, breadth first. A
+// trashed subtask hides its own subtree.
+func (r *TaskRepository) ListSubtasks(ctx context.Context, id int64) ([]*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `WITH RECURSIVE subtree (id, depth) AS (
+	SELECT id, 1 FROM tasks WHERE parent_id = $1 AND deleted_at IS NULL
+	UNION ALL
+	SELECT t.id, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+)
+SELECT ` + taskColumns + ` FROM tasks JOIN subtree USING (id) ORDER BY subtree.depth ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task := &models.Task{}
+		if err := scanTask(rows, task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// hierarchyLockKey serialises moves between parents, so that two moves
+// running side by side cannot each miss the cycle the other one closes.
+const hierarchyLockKey = 72707370
+
+// checkParentCycle refuses to nest task id under parentID when the parent is
+// the task itself or one of its subtasks. It runs in the transaction that
+// moves the task, and walks the ancestors only once every other move has
+// committed. UNION stops the walk should the stored hierarchy ever loop.
+func checkParentCycle(ctx context.Context, tx *sql.Tx, id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", hierarchyLockKey); err != nil {
+		return err
+	}
+
+	const query = `WITH RECURSIVE ancestors (id, parent_id) AS (
+	SELECT id, parent_id FROM tasks WHERE id = $1
+	UNION
+	SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+)
+SELECT id FROM ancestors`
+	ancestors, err := queryIDs(ctx, tx, query, *parentID)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(ancestors, id) {
+		return utils.ErrParentCycle
+	}
+
+	return nil
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// touchParents bumps the version of the given parents as part of tx. The
+// progress of a task is rolled up from its subtasks, so adding, removing,
+// moving or completing a subtask changes the parent's representation and
+// must change its ETag too.
+func touchParents(ctx context.Context, tx *sql.Tx, parentIDs ...*int64) error {
+	var ids []int64
+	for _, id := range parentIDs {
+		if id != nil && !slices.Contains(ids, *id) {
+			ids = append(ids, *id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	const query = "UPDATE tasks SET version = version + 1 WHERE id = ANY($1)"
+	_, err := tx.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+// GetTaskProgress counts the live direct subtasks of a task and how many of
+// them are completed.
+func (r *TaskRepository) GetTaskProgress(ctx context.Context, id int64) (*models.TaskProgress, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT COUNT(*), COUNT(*) FILTER (WHERE status = $2) FROM tasks WHERE parent_id = $1 AND deleted_at IS NULL"
+	progress := &models.TaskProgress{}
+	if err := r.db.QueryRowContext(ctx, query, id, models.StatusCompleted).Scan(&progress.Total, &progress.Completed); err != nil {
+		return nil, err
+	}
+
+	if progress.Total > 0 {
+		progress.Percent = progress.Completed * 100 / progress.Total
+	}
+	return progress, nil
+}
@@ -0,0 +1,136 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSubtasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+
+	t.Run("must walk the subtree with a recursive CTE", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("WITH RECURSIVE subtree \\(id, depth\\) AS \\(.+parent_id = \\$1 AND deleted_at IS NULL.+JOIN subtree s ON t.parent_id = s.id.+ORDER BY subtree.depth ASC, id ASC").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, "Child", "", "Pending", "medium", nil, nil, 1, nil, nil, "", nil, 7, 1, now, now, "[]").
+				AddRow(3, "Grandchild", "", "Pending", "medium", nil, nil, 2, nil, nil, "", nil, 7, 1, now, now, "[]"),
+			)
+
+		subtasks, err := repo.ListSubtasks(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, subtasks, 2)
+		assert.Equal(t, int64(1), *subtasks[0].ParentID)
+		assert.Equal(t, int64(2), *subtasks[1].ParentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return an error if the query fails", func(t *testing.T) {
+		mock.ExpectQuery("WITH RECURSIVE subtree").WillReturnError(errors.New("query invalid"))
+
+		_, err := repo.ListSubtasks(context.Background(), 1)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMoveTaskParentCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const lock = "FROM tasks WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE"
+	const ancestors = "WITH RECURSIVE ancestors \\(id, parent_id\\) AS \\(.+WHERE id = \\$1.+UNION.+JOIN ancestors a ON t.id = a.parent_id"
+	columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+	now := time.Now()
+	parentID := int64(3)
+	parent := &parentID
+	patch := &models.TaskPatch{ParentID: &parent}
+
+	expectLock := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 1, now, now, "[]"))
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("should reject nesting a task under one of its own subtasks", func(t *testing.T) {
+		expectLock()
+		mock.ExpectQuery(ancestors).
+			WithArgs(parentID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(2).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err := repo.PatchTask(context.Background(), 7, 1, 0, patch, nil)
+		assert.ErrorIs(t, err, utils.ErrParentCycle)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("must move the task once the walk finds no cycle", func(t *testing.T) {
+		expectLock()
+		mock.ExpectQuery(ancestors).
+			WithArgs(parentID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery("UPDATE tasks SET parent_id = \\$2, updated_at = \\$3").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, parentID, nil, nil, "", nil, 7, 2, now, now, "[]"))
+		mock.ExpectExec("INSERT INTO task_events").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE tasks SET version = version + 1 WHERE id = ANY($1)")).
+			WithArgs(pq.Array([]int64{parentID})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.PatchTask(context.Background(), 7, 1, 0, patch, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &parentID, task.ParentID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTaskProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+	const query = "SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER \\(WHERE status = \\$2\\) FROM tasks WHERE parent_id = \\$1 AND deleted_at IS NULL"
+
+	t.Run("must count the completed direct subtasks", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), models.StatusCompleted).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "completed"}).AddRow(3, 1))
+
+		progress, err := repo.GetTaskProgress(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.TaskProgress{Total: 3, Completed: 1, Percent: 33}, progress)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should report no progress for a task without subtasks", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(int64(2), models.StatusCompleted).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "completed"}).AddRow(0, 0))
+
+		progress, err := repo.GetTaskProgress(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, progress.Percent)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+func (r *TaskRepository) ListTrash(ctx context.Context, userID int64) ([]*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + taskColumns + ", deleted_at FROM tasks WHERE owner_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC"
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		var task models.Task
+		if err := scanTask(rows, &task, &task.DeletedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// RestoreTask takes a task out of the trash. Like the trash itself, this is
+// reserved for the task's owner, given as actorID.
+func (r *TaskRepository) RestoreTask(ctx context.Context, actorID, id int64) (*models.Task, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	const lock = "SELECT deleted_at FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL FOR UPDATE"
+	if err := tx.QueryRowContext(ctx, lock, id, actorID).Scan(&deletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	const query = "UPDATE tasks SET deleted_at = NULL, updated_at = $2, version = version + 1 WHERE id = $1 RETURNING " + taskColumns
+	task := &models.Task{}
+	if err := scanTask(tx.QueryRowContext(ctx, query, id, time.Now()), task); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.FieldChange{"deleted_at": {Before: deletedAt}}
+	if err := recordEvent(ctx, tx, id, actorID, models.EventRestored, changes); err != nil {
+		return nil, err
+	}
+
+	if err := touchParents(ctx, tx, task.ParentID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// PurgeTasks permanently removes the tasks that were moved to the trash
+// before deletedBefore. It reports how many tasks were removed and the
+// storage keys of their attachments: the attachment rows go with the tasks,
+// but their content is left for the caller to delete.
+func (r *TaskRepository) PurgeTasks(ctx context.Context, deletedBefore time.Time) (int64, []string, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `WITH purged AS (
+	DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id
+)
+SELECT (SELECT COUNT(*) FROM purged), ARRAY(SELECT a.storage_key FROM attachments a JOIN purged p ON a.task_id = p.id)`
+	var purged int64
+	var keys []string
+	if err := r.db.QueryRowContext(ctx, query, deletedBefore).Scan(&purged, pq.Array(&keys)); err != nil {
+		return 0, nil, err
+	}
+	return purged, keys, nil
+}
@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/task/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTrash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must return the trashed tasks of the owner, most recently deleted first", func(t *testing.T) {
+		columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels", "deleted_at"}
+		now := time.Now()
+
+		mock.ExpectQuery("FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC").
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 2, now, now, "[]", now))
+
+		tasks, err := repo.ListTrash(context.Background(), 7)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.NotNil(t, tasks[0].DeletedAt)
+		assert.Equal(t, now, *tasks[0].DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRestoreTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	const lock = "SELECT deleted_at FROM tasks WHERE id = \\$1 AND owner_id = \\$2 AND deleted_at IS NOT NULL FOR UPDATE"
+	const query = "UPDATE tasks SET deleted_at = NULL, updated_at = \\$2, version = version \\+ 1 WHERE id = \\$1 RETURNING"
+
+	t.Run("must take the task out of the trash and record the event", func(t *testing.T) {
+		columns := []string{"id", "title", "description", "status", "priority", "due_at", "completed_at", "parent_id", "project_id", "archived_at", "recurrence", "series_id", "owner_id", "version", "created_at", "updated_at", "labels"}
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}).AddRow(time.Now()))
+		mock.ExpectQuery(query).
+			WithArgs(int64(1), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task", "", "Pending", "medium", nil, nil, nil, nil, nil, "", nil, 7, 3, time.Now(), time.Now(), "[]"))
+		mock.ExpectExec("INSERT INTO task_events").
+			WithArgs(int64(1), int64(7), models.EventRestored, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		task, err := repo.RestoreTask(context.Background(), 7, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), task.Version)
+		assert.Nil(t, task.DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrTaskNotFound if the task is not in the owner's trash", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).
+			WithArgs(int64(1), int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}))
+		mock.ExpectRollback()
+
+		_, err := repo.RestoreTask(context.Background(), 7, 1)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPurgeTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTaskRepository(db, time.Second)
+
+	t.Run("must permanently delete the tasks trashed before the cutoff and return their blob keys", func(t *testing.T) {
+		cutoff := time.Now().Add(-time.Hour)
+		mock.ExpectQuery("DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < \\$1 RETURNING id").
+			WithArgs(cutoff).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "keys"}).AddRow(3, "{a,b}"))
+
+		purged, keys, err := repo.PurgeTasks(context.Background(), cutoff)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		assert.Equal(t, []string{"a", "b"}, keys)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// AddTaskDependency marks a task as blocked by another. The user must be able
+// to edit the blocked task and see the blocking one. The repository keeps the
+// dependency graph acyclic.
+func (s *TaskService) AddTaskDependency(ctx context.Context, userID int64, dependency *models.TaskDependency) error {
+	if dependency.TaskID < 0 || dependency.BlockedByID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	if dependency.TaskID == dependency.BlockedByID {
+		return utils.ErrDependencyCycle
+	}
+
+	if err := s.requireRole(ctx, userID, dependency.TaskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	blocker, err := s.repo.GetTask(ctx, dependency.BlockedByID)
+	if err != nil {
+		return err
+	}
+
+	if blocker == nil {
+		return utils.ErrInvalidDependency
+	}
+
+	if err := s.requireRole(ctx, userID, dependency.BlockedByID, models.RoleViewer); err != nil {
+		return err
+	}
+
+	return s.repo.AddTaskDependency(ctx, dependency)
+}
+
+func (s *TaskService) RemoveTaskDependency(ctx context.Context, userID, taskID, blockedByID int64) error {
+	if taskID < 0 || blockedByID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	if err := s.requireRole(ctx, userID, taskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveTaskDependency(ctx, taskID, blockedByID)
+}
+
+// ListTaskDependencies returns the tasks that block a task. Seeing the
+// blocked task does not grant access to its blockers, so the ones the user
+// cannot see are redacted to their id and status.
+func (s *TaskService) ListTaskDependencies(ctx context.Context, userID, taskID int64) ([]*models.TaskBlocker, error) {
+	if taskID < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	blockers, err := s.repo.ListBlockers(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if blockers == nil {
+		return []*models.TaskBlocker{}, nil
+	}
+
+	return blockers, nil
+}
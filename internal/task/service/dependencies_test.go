@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddTaskDependency(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should reject a task blocking itself", func(t *testing.T) {
+		err := svc.AddTaskDependency(context.Background(), userID, &models.TaskDependency{TaskID: 1, BlockedByID: 1})
+		assert.ErrorIs(t, err, utils.ErrDependencyCycle)
+	})
+
+	t.Run("should return ErrForbidden if the user cannot edit the task", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleViewer, nil).Once()
+
+		err := svc.AddTaskDependency(context.Background(), userID, &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a blocker that does not exist", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(2)).Return((*models.Task)(nil), nil).Once()
+
+		err := svc.AddTaskDependency(context.Background(), userID, &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrInvalidDependency)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a dependency that closes a cycle", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(2)).Return(&models.Task{ID: 2}, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(2)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("AddTaskDependency", mock.Anything, &models.TaskDependency{TaskID: 1, BlockedByID: 2}).Return(utils.ErrDependencyCycle).Once()
+
+		err := svc.AddTaskDependency(context.Background(), userID, &models.TaskDependency{TaskID: 1, BlockedByID: 2})
+		assert.ErrorIs(t, err, utils.ErrDependencyCycle)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should add the dependency if the graph stays acyclic", func(t *testing.T) {
+		dependency := &models.TaskDependency{TaskID: 1, BlockedByID: 2}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(2)).Return(&models.Task{ID: 2}, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(2)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("AddTaskDependency", mock.Anything, dependency).Return(nil).Once()
+
+		assert.NoError(t, svc.AddTaskDependency(context.Background(), userID, dependency))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRemoveTaskDependency(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should remove the dependency if the user can edit the task", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("RemoveTaskDependency", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+
+		assert.NoError(t, svc.RemoveTaskDependency(context.Background(), userID, 1, 2))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestListTaskDependencies(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	viewerID := int64(8)
+
+	t.Run("should list the blockers as seen by the requesting viewer", func(t *testing.T) {
+		blockers := []*models.TaskBlocker{{ID: 2, Status: models.StatusPending}}
+		mockRepo.On("GetTaskRole", mock.Anything, viewerID, int64(1)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("ListBlockers", mock.Anything, int64(1), viewerID).Return(blockers, nil).Once()
+
+		result, err := svc.ListTaskDependencies(context.Background(), viewerID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, blockers, result)
+		assert.Nil(t, result[0].Task)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrForbidden if the user cannot see the task", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, viewerID, int64(1)).Return("", nil).Once()
+
+		_, err := svc.ListTaskDependencies(context.Background(), viewerID, 1)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+)
+
+func (s *TaskService) ListTaskEvents(ctx context.Context, userID, taskID int64) ([]*models.TaskEvent, error) {
+	if err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.ListTaskEvents(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		return []*models.TaskEvent{}, nil
+	}
+
+	return events, nil
+}
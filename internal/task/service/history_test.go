@@ -0,0 +1,39 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListTaskEvents(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return ErrForbidden if the user has no access to the task", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return("", nil).Once()
+
+		_, err := svc.ListTaskEvents(context.Background(), userID, 1)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return the history to a viewer", func(t *testing.T) {
+		events := []*models.TaskEvent{{ID: 1, TaskID: 1, Action: models.EventCreated}}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("ListTaskEvents", mock.Anything, int64(1)).Return(events, nil).Once()
+
+		result, err := svc.ListTaskEvents(context.Background(), userID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+func (s *TaskService) ShareTask(ctx context.Context, userID int64, member *models.TaskMember) error {
+	if member.TaskID < 0 || member.UserID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	if member.Role == "" {
+		return utils.ErrEmptyRole
+	}
+
+	if err := utils.ValidateRole(member.Role); err != nil {
+		return err
+	}
+
+	if err := s.requireRole(ctx, userID, member.TaskID, models.RoleOwner); err != nil {
+		return err
+	}
+
+	task, err := s.repo.GetTask(ctx, member.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if task == nil {
+		return utils.ErrTaskNotFound
+	}
+
+	if task.OwnerID == member.UserID {
+		return utils.ErrCannotShareOwner
+	}
+
+	return s.repo.AddTaskMember(ctx, member)
+}
+
+func (s *TaskService) UnshareTask(ctx context.Context, userID, taskID, memberID int64) error {
+	if taskID < 0 || memberID <= 0 {
+		return utils.ErrInvalidId
+	}
+
+	// Members may always leave a task; removing anyone else requires ownership.
+	if userID != memberID {
+		if err := s.requireRole(ctx, userID, taskID, models.RoleOwner); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveTaskMember(ctx, taskID, memberID)
+}
+
+func (s *TaskService) ListTaskMembers(ctx context.Context, userID, taskID int64) ([]*models.TaskMember, error) {
+	if taskID < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if err := s.requireRole(ctx, userID, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListTaskMembers(ctx, taskID)
+}
@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShareTask(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return error if role is empty", func(t *testing.T) {
+		err := svc.ShareTask(context.Background(), userID, &models.TaskMember{TaskID: 1, UserID: 8})
+		assert.ErrorIs(t, err, utils.ErrEmptyRole)
+	})
+
+	t.Run("should return error if role is invalid", func(t *testing.T) {
+		err := svc.ShareTask(context.Background(), userID, &models.TaskMember{TaskID: 1, UserID: 8, Role: "admin"})
+		assert.ErrorIs(t, err, utils.ErrInvalidRole)
+	})
+
+	t.Run("should return ErrForbidden if the user is not an owner", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+
+		err := svc.ShareTask(context.Background(), userID, &models.TaskMember{TaskID: 1, UserID: 8, Role: models.RoleViewer})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if sharing with the task owner", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, OwnerID: userID}, nil).Once()
+
+		err := svc.ShareTask(context.Background(), userID, &models.TaskMember{TaskID: 1, UserID: userID, Role: models.RoleViewer})
+		assert.ErrorIs(t, err, utils.ErrCannotShareOwner)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should add the member if the user owns the task", func(t *testing.T) {
+		member := &models.TaskMember{TaskID: 1, UserID: 8, Role: models.RoleEditor}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, OwnerID: userID}, nil).Once()
+		mockRepo.On("AddTaskMember", mock.Anything, member).Return(nil).Once()
+
+		err := svc.ShareTask(context.Background(), userID, member)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUnshareTask(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should let a member leave the task without ownership", func(t *testing.T) {
+		mockRepo.On("RemoveTaskMember", mock.Anything, int64(1), userID).Return(nil).Once()
+
+		err := svc.UnshareTask(context.Background(), userID, 1, userID)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrForbidden if a non-owner removes someone else", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+
+		err := svc.UnshareTask(context.Background(), userID, 1, 8)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should remove the member if the user owns the task", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("RemoveTaskMember", mock.Anything, int64(1), int64(8)).Return(utils.ErrMemberNotFound).Once()
+
+		err := svc.UnshareTask(context.Background(), userID, 1, 8)
+		assert.ErrorIs(t, err, utils.ErrMemberNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestListTaskMembers(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return ErrForbidden if the task is not shared with the user", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return("", nil).Once()
+
+		_, err := svc.ListTaskMembers(context.Background(), userID, 1)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return the members to any collaborator", func(t *testing.T) {
+		members := []*models.TaskMember{{TaskID: 1, UserID: 8, Role: models.RoleViewer}}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("ListTaskMembers", mock.Anything, int64(1)).Return(members, nil).Once()
+
+		result, err := svc.ListTaskMembers(context.Background(), userID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, members, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if repository fails", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return("", errors.New("repository error")).Once()
+
+		_, err := svc.ListTaskMembers(context.Background(), userID, 1)
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
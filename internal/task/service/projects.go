@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// checkProject verifies that a task may be filed under projectID: the
+// project must exist, be owned by the user or shared with them as an editor,
+// and still be active. Projects not shared with the user are reported as
+// invalid rather than forbidden, so their ids do not leak. An existing task,
+// passed as current, may only be moved in or out of a project by its owner,
+// as the move hands the task to whoever the project is shared with; current
+// is nil for a task that does not exist yet.
+func (s *TaskService) checkProject(ctx context.Context, userID int64, current *models.Task, projectID *int64) error {
+	if current != nil && current.OwnerID != userID {
+		return utils.ErrForbidden
+	}
+
+	if projectID == nil {
+		return nil
+	}
+
+	if *projectID <= 0 {
+		return utils.ErrInvalidProject
+	}
+
+	project, err := s.repo.GetProject(ctx, *projectID)
+	if err != nil {
+		return err
+	}
+
+	if project == nil {
+		return utils.ErrInvalidProject
+	}
+
+	if project.OwnerID != userID {
+		role, err := s.repo.GetProjectMemberRole(ctx, project.ID, userID)
+		if err != nil {
+			return err
+		}
+		if role == "" {
+			return utils.ErrInvalidProject
+		}
+		if !utils.HasRole(role, models.RoleEditor) {
+			return utils.ErrForbidden
+		}
+	}
+
+	if project.ArchivedAt != nil {
+		return utils.ErrProjectArchived
+	}
+
+	return nil
+}
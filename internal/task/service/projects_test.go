@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskProject(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+	projectID := int64(4)
+
+	t.Run("should create a task in a project the user owns", func(t *testing.T) {
+		task := &models.Task{Title: "Task", Status: "Pending", ProjectID: &projectID}
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: userID}, nil).Once()
+		mockRepo.On("CreateTask", mock.Anything, task).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateTask(context.Background(), userID, task))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should treat a project not shared with the user as invalid", func(t *testing.T) {
+		task := &models.Task{Title: "Task", Status: "Pending", ProjectID: &projectID}
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, projectID, userID).Return("", nil).Once()
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrInvalidProject)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should create a task in a project shared with the user as an editor", func(t *testing.T) {
+		task := &models.Task{Title: "Task", Status: "Pending", ProjectID: &projectID}
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, projectID, userID).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("CreateTask", mock.Anything, task).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateTask(context.Background(), userID, task))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrForbidden to viewers of the project", func(t *testing.T) {
+		task := &models.Task{Title: "Task", Status: "Pending", ProjectID: &projectID}
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: 8}, nil).Once()
+		mockRepo.On("GetProjectMemberRole", mock.Anything, projectID, userID).Return(models.RoleViewer, nil).Once()
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything, task)
+	})
+
+	t.Run("should refuse to file tasks under an archived project", func(t *testing.T) {
+		archivedAt := time.Now()
+		moved := &projectID
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", OwnerID: userID}, nil).Once()
+		mockRepo.On("GetProject", mock.Anything, projectID).Return(&models.Project{ID: projectID, OwnerID: userID, ArchivedAt: &archivedAt}, nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{ProjectID: &moved})
+		assert.ErrorIs(t, err, utils.ErrProjectArchived)
+		mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should move a task out of its project without a lookup", func(t *testing.T) {
+		var detached *int64
+		patch := &models.TaskPatch{ProjectID: &detached}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", ProjectID: &projectID, OwnerID: userID}, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(1), int64(0), patch).Return(&models.Task{ID: 1, Status: "Pending", ProjectID: &projectID, OwnerID: userID}, &models.Task{ID: 1}, nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, patch)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should only let the task owner move it between projects", func(t *testing.T) {
+		moved := &projectID
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", OwnerID: 8}, nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{ProjectID: &moved})
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+}
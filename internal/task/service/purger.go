@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	r "todo_list_api/internal/task/repository"
+)
+
+// BlobDeleter removes attachment content from blob storage once the tasks
+// it was attached to have been purged.
+type BlobDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// Purger permanently removes tasks that have stayed in the trash for longer
+// than the configured retention, along with the content of their
+// attachments.
+type Purger struct {
+	repo      r.Repository
+	blobs     BlobDeleter
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewPurger(repo r.Repository, blobs BlobDeleter, retention time.Duration) *Purger {
+	return &Purger{repo: repo, blobs: blobs, retention: retention, now: time.Now}
+}
+
+// Purge removes every task trashed before the retention window and returns
+// how many were removed.
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	purged, keys, err := p.repo.PurgeTasks(ctx, p.now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	// The records are gone, so a blob left behind is unreachable: log it
+	// rather than fail the purge.
+	for _, key := range keys {
+		if err := p.blobs.Delete(ctx, key); err != nil {
+			log.Printf("could not remove blob %s: %v", key, err)
+		}
+	}
+
+	return purged, nil
+}
+
+// Run purges once immediately and then on every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.Purge(ctx)
+		if err != nil {
+			log.Printf("could not purge the trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d tasks from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"todo_list_api/internal/task/recurrence"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// checkRecurrence trims and validates the rule of task, which needs a due
+// date to anchor its series. Occurrences follow the rule of their template
+// and cannot carry one of their own.
+func checkRecurrence(task *models.Task) error {
+	task.Recurrence = strings.TrimSpace(task.Recurrence)
+	if task.Recurrence == "" {
+		return nil
+	}
+
+	if task.SeriesID != nil {
+		return utils.ErrInvalidRecurrence
+	}
+
+	if _, err := recurrence.Parse(task.Recurrence); err != nil {
+		return err
+	}
+
+	if task.DueAt == nil {
+		return utils.ErrMissingDueDate
+	}
+
+	return nil
+}
+
+// checkPatchedRecurrence validates the rule current would carry once patch
+// is applied, as clearing the due date of a template also breaks its series.
+func checkPatchedRecurrence(current *models.Task, patch *models.TaskPatch) error {
+	patched := models.Task{Recurrence: current.Recurrence, DueAt: current.DueAt, SeriesID: current.SeriesID}
+	if patch.Recurrence != nil {
+		patched.Recurrence = *patch.Recurrence
+	}
+	if patch.DueAt != nil {
+		patched.DueAt = *patch.DueAt
+	}
+
+	if err := checkRecurrence(&patched); err != nil {
+		return err
+	}
+
+	if patch.Recurrence != nil {
+		patch.Recurrence = &patched.Recurrence
+	}
+	return nil
+}
+
+// spawnNext creates the occurrence that follows task in its series, once
+// task has been completed. The update that completed task is already
+// committed, so failures are logged rather than returned; the scheduler
+// still materializes the occurrence once it comes within its horizon.
+func (s *TaskService) spawnNext(ctx context.Context, task *models.Task) {
+	if task.DueAt == nil {
+		return
+	}
+
+	template, err := s.seriesTemplate(ctx, task)
+	if err != nil {
+		log.Printf("could not load the series of task %d: %v", task.ID, err)
+		return
+	}
+	if template == nil {
+		return
+	}
+
+	rule, err := recurrence.Parse(template.Recurrence)
+	if err != nil {
+		log.Printf("task %d has an invalid recurrence: %v", template.ID, err)
+		return
+	}
+
+	next, ok := rule.Next(*template.DueAt, *task.DueAt)
+	if !ok {
+		return
+	}
+
+	if _, err := s.repo.CreateOccurrence(ctx, template, next, s.workflow.Initial()); err != nil {
+		log.Printf("could not create the next occurrence of task %d: %v", template.ID, err)
+	}
+}
+
+// seriesTemplate returns the template of the series task belongs to, or nil
+// if task does not repeat.
+func (s *TaskService) seriesTemplate(ctx context.Context, task *models.Task) (*models.Task, error) {
+	if task.Recurrence != "" {
+		return task, nil
+	}
+
+	if task.SeriesID == nil {
+		return nil, nil
+	}
+
+	template, err := s.repo.GetTask(ctx, *task.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	if template == nil || template.Recurrence == "" || template.DueAt == nil {
+		return nil, nil
+	}
+
+	return template, nil
+}
+
+// completes reports whether moving from status before to status after
+// completes a task.
+func completes(before, after string) bool {
+	return before != models.StatusCompleted && after == models.StatusCompleted
+}
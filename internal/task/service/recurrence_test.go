@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTaskRecurrence(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+	// Monday, 6 January 2025.
+	dueAt := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+	nextWeek := dueAt.AddDate(0, 0, 7)
+
+	t.Run("should reject an invalid rule", func(t *testing.T) {
+		task := &models.Task{Title: "Chores", Status: "Pending", DueAt: &dueAt, Recurrence: "FREQ=HOURLY"}
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrInvalidRecurrence)
+	})
+
+	t.Run("should require a due date to anchor the series", func(t *testing.T) {
+		task := &models.Task{Title: "Chores", Status: "Pending", Recurrence: "FREQ=WEEKLY"}
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrMissingDueDate)
+	})
+
+	t.Run("should refuse to clear the due date of a template", func(t *testing.T) {
+		var cleared *time.Time
+		patch := &models.TaskPatch{DueAt: &cleared}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(1), int64(0), patch).Return(&models.Task{ID: 1, Status: "Pending", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}, (*models.Task)(nil), nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, patch)
+		assert.ErrorIs(t, err, utils.ErrMissingDueDate)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not let an occurrence carry a rule of its own", func(t *testing.T) {
+		seriesID := int64(1)
+		rule := "FREQ=DAILY"
+		patch := &models.TaskPatch{Recurrence: &rule}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(2)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(2), int64(0), patch).Return(&models.Task{ID: 2, Status: "Pending", DueAt: &dueAt, SeriesID: &seriesID}, (*models.Task)(nil), nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 2, 0, patch)
+		assert.ErrorIs(t, err, utils.ErrInvalidRecurrence)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("must generate the next occurrence when the template is completed", func(t *testing.T) {
+		template := &models.Task{ID: 1, Title: "Chores", Status: "Completed", Priority: "medium", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, template).Return(&models.Task{ID: 1, Status: "Pending", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}, nil).Once()
+		mockRepo.On("CreateOccurrence", mock.Anything, template, nextWeek, "Pending").Return(&models.Task{ID: 2}, nil).Once()
+
+		assert.NoError(t, svc.UpdateTask(context.Background(), userID, template))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("must follow the template's rule when an occurrence is completed", func(t *testing.T) {
+		seriesID := int64(1)
+		status := "Completed"
+		patch := &models.TaskPatch{Status: &status}
+		template := &models.Task{ID: 1, Status: "Completed", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}
+		completed := &models.Task{ID: 2, Status: "Completed", DueAt: &nextWeek, SeriesID: &seriesID}
+
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(2)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(2), int64(0), patch).Return(&models.Task{ID: 2, Status: "In progress", DueAt: &nextWeek, SeriesID: &seriesID}, completed, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(template, nil).Once()
+		mockRepo.On("CreateOccurrence", mock.Anything, template, dueAt.AddDate(0, 0, 14), "Pending").Return((*models.Task)(nil), errors.New("insert failed")).Once()
+
+		task, err := svc.PatchTask(context.Background(), userID, 2, 0, patch)
+		assert.NoError(t, err, "a failed occurrence must not fail the completed update")
+		assert.Equal(t, completed, task)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should stop generating once the series has ended", func(t *testing.T) {
+		template := &models.Task{ID: 1, Title: "Chores", Status: "Completed", Priority: "medium", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY;COUNT=1"}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", DueAt: &dueAt, Recurrence: template.Recurrence}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, template).Return(&models.Task{ID: 1, Status: "Pending", DueAt: &dueAt, Recurrence: template.Recurrence}, nil).Once()
+
+		assert.NoError(t, svc.UpdateTask(context.Background(), userID, template))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	scheduler := service.NewScheduler(mockRepo, workflow.Default(), 7*24*time.Hour)
+
+	t.Run("must materialize the occurrences due within the horizon", func(t *testing.T) {
+		dueAt := time.Now().Add(-time.Hour)
+		latest := dueAt.AddDate(0, 0, 1)
+		template := &models.Task{ID: 1, DueAt: &dueAt, Recurrence: "FREQ=DAILY;INTERVAL=2"}
+
+		mockRepo.On("ListRecurringTasks", mock.Anything).Return([]*models.Task{template, {ID: 2, DueAt: &dueAt, Recurrence: "FREQ=SECONDLY"}}, nil).Once()
+		mockRepo.On("LatestOccurrence", mock.Anything, int64(1)).Return(&latest, nil).Once()
+		for _, days := range []int{2, 4, 6} {
+			mockRepo.On("CreateOccurrence", mock.Anything, template, dueAt.AddDate(0, 0, days), "Pending").Return(&models.Task{}, nil).Once()
+		}
+
+		created, err := scheduler.Materialize(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, created)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not count occurrences that already existed", func(t *testing.T) {
+		dueAt := time.Now().Add(-24 * time.Hour)
+		template := &models.Task{ID: 1, DueAt: &dueAt, Recurrence: "FREQ=WEEKLY"}
+
+		mockRepo.On("ListRecurringTasks", mock.Anything).Return([]*models.Task{template}, nil).Once()
+		mockRepo.On("LatestOccurrence", mock.Anything, int64(1)).Return(&dueAt, nil).Once()
+		mockRepo.On("CreateOccurrence", mock.Anything, template, dueAt.AddDate(0, 0, 7), "Pending").Return((*models.Task)(nil), nil).Once()
+
+		created, err := scheduler.Materialize(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, created)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo_list_api/internal/task/recurrence"
+	r "todo_list_api/internal/task/repository"
+	"todo_list_api/internal/task/workflow"
+)
+
+// Scheduler materializes the upcoming occurrences of recurring tasks, so they
+// show up in listings ahead of their due date.
+type Scheduler struct {
+	repo     r.Repository
+	workflow *workflow.Workflow
+	horizon  time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(repo r.Repository, workflow *workflow.Workflow, horizon time.Duration) *Scheduler {
+	return &Scheduler{repo: repo, workflow: workflow, horizon: horizon, now: time.Now}
+}
+
+// Materialize creates every missing occurrence due within the horizon and
+// returns how many were created. Occurrences missed in the past are not
+// backfilled.
+func (s *Scheduler) Materialize(ctx context.Context) (int, error) {
+	templates, err := s.repo.ListRecurringTasks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	until := now.Add(s.horizon)
+	created := 0
+	for _, template := range templates {
+		rule, err := recurrence.Parse(template.Recurrence)
+		if err != nil {
+			log.Printf("task %d has an invalid recurrence: %v", template.ID, err)
+			continue
+		}
+
+		latest, err := s.repo.LatestOccurrence(ctx, template.ID)
+		if err != nil {
+			return created, err
+		}
+
+		after := now
+		if latest != nil && latest.After(after) {
+			after = *latest
+		}
+
+		for {
+			next, ok := rule.Next(*template.DueAt, after)
+			if !ok || next.After(until) {
+				break
+			}
+
+			occurrence, err := s.repo.CreateOccurrence(ctx, template, next, s.workflow.Initial())
+			if err != nil {
+				return created, err
+			}
+			if occurrence != nil {
+				created++
+			}
+			after = next
+		}
+	}
+
+	return created, nil
+}
+
+// Run materializes once immediately and then on every interval until ctx is
+// done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := s.Materialize(ctx)
+		if err != nil {
+			log.Printf("could not materialize recurring tasks: %v", err)
+		} else if created > 0 {
+			log.Printf("materialized %d recurring task occurrences", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,28 +1,45 @@
 package service
 
 import (
+	"context"
+	"slices"
+	"strings"
 	r "todo_list_api/internal/task/repository"
+	"todo_list_api/internal/task/workflow"
 	"todo_list_api/pkg/models"
 	"todo_list_api/pkg/utils"
 )
 
 type Service interface {
-	CreateTask(task *models.Task) error
-	DeleteTask(id int64) error
-	GetTask(id int64) (*models.Task, error)
-	ListTasks() ([]*models.Task, error)
-	UpdateTask(task *models.Task) error
+	AddTaskDependency(ctx context.Context, userID int64, dependency *models.TaskDependency) error
+	CreateTask(ctx context.Context, userID int64, task *models.Task) error
+	DeleteTask(ctx context.Context, userID, id, version int64) error
+	GetTask(ctx context.Context, userID, id int64) (*models.Task, error)
+	ListTaskDependencies(ctx context.Context, userID, taskID int64) ([]*models.TaskBlocker, error)
+	ListTaskEvents(ctx context.Context, userID, taskID int64) ([]*models.TaskEvent, error)
+	ListTaskMembers(ctx context.Context, userID, taskID int64) ([]*models.TaskMember, error)
+	ListSubtasks(ctx context.Context, userID, id int64) ([]*models.Task, error)
+	ListTasks(ctx context.Context, userID int64, filter models.TaskFilter) (*models.TaskPage, error)
+	ListTrash(ctx context.Context, userID int64) ([]*models.Task, error)
+	PatchTask(ctx context.Context, userID, id, version int64, patch *models.TaskPatch) (*models.Task, error)
+	RemoveTaskDependency(ctx context.Context, userID, taskID, blockedByID int64) error
+	RestoreTask(ctx context.Context, userID, id int64) (*models.Task, error)
+	SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error)
+	ShareTask(ctx context.Context, userID int64, member *models.TaskMember) error
+	UnshareTask(ctx context.Context, userID, taskID, memberID int64) error
+	UpdateTask(ctx context.Context, userID int64, task *models.Task) error
 }
 
 type TaskService struct {
-	repo r.Repository
+	repo     r.Repository
+	workflow *workflow.Workflow
 }
 
-func NewTaskService(repo r.Repository) Service {
-	return &TaskService{repo: repo}
+func NewTaskService(repo r.Repository, workflow *workflow.Workflow) Service {
+	return &TaskService{repo: repo, workflow: workflow}
 }
 
-func (s *TaskService) CreateTask(task *models.Task) error {
+func (s *TaskService) CreateTask(ctx context.Context, userID int64, task *models.Task) error {
 	if task.Title == "" {
 		return utils.ErrEmptyTitle
 	}
@@ -31,28 +48,54 @@ func (s *TaskService) CreateTask(task *models.Task) error {
 		return utils.ErrEmptyStatus
 	}
 
-	err := utils.ValidateStatus(task.Status)
+	err := s.workflow.ValidateStatus(task.Status)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.CreateTask(task)
+	if err := normalizePriority(task); err != nil {
+		return err
+	}
+
+	if err := s.checkParent(ctx, userID, 0, task.ParentID); err != nil {
+		return err
+	}
+
+	if err := s.checkProject(ctx, userID, nil, task.ProjectID); err != nil {
+		return err
+	}
+
+	task.SeriesID = nil
+	if err := checkRecurrence(task); err != nil {
+		return err
+	}
+
+	task.OwnerID = userID
+	return s.repo.CreateTask(ctx, task)
 }
 
-func (s *TaskService) DeleteTask(id int64) error {
+func (s *TaskService) DeleteTask(ctx context.Context, userID, id, version int64) error {
 	if id < 0 {
 		return utils.ErrInvalidId
 	}
 
-	return s.repo.DeleteTask(id)
+	if err := s.requireRole(ctx, userID, id, models.RoleOwner); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTask(ctx, userID, id, version)
 }
 
-func (s *TaskService) GetTask(id int64) (*models.Task, error) {
+func (s *TaskService) GetTask(ctx context.Context, userID, id int64) (*models.Task, error) {
 	if id < 0 {
 		return nil, utils.ErrInvalidId
 	}
 
-	task, err := s.repo.GetTask(id)
+	if err := s.requireRole(ctx, userID, id, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	task, err := s.repo.GetTask(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -61,23 +104,116 @@ func (s *TaskService) GetTask(id int64) (*models.Task, error) {
 		return nil, utils.ErrTaskNotFound
 	}
 
+	progress, err := s.repo.GetTaskProgress(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress.Total > 0 {
+		task.Progress = progress
+	}
+
+	comments, err := s.repo.CountComments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task.CommentCount = &comments
+
 	return task, nil
 }
 
-func (s *TaskService) ListTasks() ([]*models.Task, error) {
-	tasks, err := s.repo.ListTasks()
+func (s *TaskService) ListTasks(ctx context.Context, userID int64, filter models.TaskFilter) (*models.TaskPage, error) {
+	filter.UserID = userID
+
+	if filter.Status != "" {
+		if err := s.workflow.ValidateStatus(filter.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Priority != "" {
+		if err := utils.ValidatePriority(filter.Priority); err != nil {
+			return nil, err
+		}
+	}
+
+	switch filter.LabelMatch {
+	case "":
+		filter.LabelMatch = models.LabelMatchAny
+	case models.LabelMatchAny, models.LabelMatchAll:
+	default:
+		return nil, utils.ErrInvalidFilter
+	}
+
+	if len(filter.Labels) > 0 {
+		filter.Labels = uniqueLabels(filter.Labels)
+	}
+
+	if filter.DueAfter != nil && filter.DueBefore != nil && !filter.DueAfter.Before(*filter.DueBefore) {
+		return nil, utils.ErrInvalidFilter
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = models.SortCreatedAt
+	case models.SortCreatedAt, models.SortUpdatedAt, models.SortTitle, models.SortDueAt, models.SortPriority:
+	default:
+		return nil, utils.ErrInvalidSort
+	}
+
+	switch filter.Order {
+	case "":
+		filter.Order = models.OrderAsc
+	case models.OrderAsc, models.OrderDesc:
+	default:
+		return nil, utils.ErrInvalidOrder
+	}
+
+	if filter.Limit < 0 || filter.Limit > models.MaxPageSize {
+		return nil, utils.ErrInvalidLimit
+	}
+	if filter.Limit == 0 {
+		filter.Limit = models.DefaultPageSize
+	}
+
+	page, err := s.repo.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Tasks == nil {
+		page.Tasks = []*models.Task{}
+	}
+
+	return page, nil
+}
+
+func (s *TaskService) SearchTasks(ctx context.Context, userID int64, query string, limit int) ([]*models.TaskSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, utils.ErrEmptyQuery
+	}
+
+	if limit < 0 || limit > models.MaxPageSize {
+		return nil, utils.ErrInvalidLimit
+	}
+	if limit == 0 {
+		limit = models.DefaultPageSize
+	}
+
+	results, err := s.repo.SearchTasks(ctx, userID, query, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	if tasks == nil {
-		return []*models.Task{}, nil
+	if results == nil {
+		return []*models.TaskSearchResult{}, nil
 	}
 
-	return tasks, nil
+	return results, nil
 }
 
-func (s *TaskService) UpdateTask(task *models.Task) error {
+func (s *TaskService) UpdateTask(ctx context.Context, userID int64, task *models.Task) error {
 	if task.Title == "" {
 		return utils.ErrEmptyTitle
 	}
@@ -86,10 +222,195 @@ func (s *TaskService) UpdateTask(task *models.Task) error {
 		return utils.ErrEmptyStatus
 	}
 
-	err := utils.ValidateStatus(task.Status)
+	err := s.workflow.ValidateStatus(task.Status)
+	if err != nil {
+		return err
+	}
+
+	if err := normalizePriority(task); err != nil {
+		return err
+	}
+
+	if err := s.requireRole(ctx, userID, task.ID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	current, err := s.storedTask(ctx, task.ID)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.UpdateTask(task)
+	if !sameID(current.ParentID, task.ParentID) {
+		if err := s.checkParent(ctx, userID, task.ID, task.ParentID); err != nil {
+			return err
+		}
+	}
+
+	if !sameID(current.ProjectID, task.ProjectID) {
+		if err := s.checkProject(ctx, userID, current, task.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	var before *models.Task
+	check := func(stored *models.Task) error {
+		before = stored
+		if err := s.workflow.Transition(stored.Status, task.Status); err != nil {
+			return err
+		}
+
+		task.SeriesID = stored.SeriesID
+		return checkRecurrence(task)
+	}
+
+	if err := s.repo.UpdateTask(ctx, userID, task, check); err != nil {
+		return err
+	}
+
+	if completes(before.Status, task.Status) {
+		s.spawnNext(ctx, task)
+	}
+
+	return nil
+}
+
+func (s *TaskService) PatchTask(ctx context.Context, userID, id, version int64, patch *models.TaskPatch) (*models.Task, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if patch.Title != nil && *patch.Title == "" {
+		return nil, utils.ErrEmptyTitle
+	}
+
+	if patch.Status != nil {
+		if *patch.Status == "" {
+			return nil, utils.ErrEmptyStatus
+		}
+
+		if err := s.workflow.ValidateStatus(*patch.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.Priority != nil {
+		if err := utils.ValidatePriority(*patch.Priority); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.requireRole(ctx, userID, id, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if patch.IsEmpty() {
+		task, err := s.repo.GetTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			return nil, utils.ErrTaskNotFound
+		}
+		if version > 0 && task.Version != version {
+			return nil, utils.ErrPreconditionFailed
+		}
+		return task, nil
+	}
+
+	if patch.ParentID != nil || patch.ProjectID != nil {
+		current, err := s.storedTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if patch.ParentID != nil && !sameID(current.ParentID, *patch.ParentID) {
+			if err := s.checkParent(ctx, userID, id, *patch.ParentID); err != nil {
+				return nil, err
+			}
+		}
+
+		if patch.ProjectID != nil && !sameID(current.ProjectID, *patch.ProjectID) {
+			if err := s.checkProject(ctx, userID, current, *patch.ProjectID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var before *models.Task
+	check := func(stored *models.Task) error {
+		before = stored
+		if patch.Status != nil {
+			if err := s.workflow.Transition(stored.Status, *patch.Status); err != nil {
+				return err
+			}
+		}
+
+		if patch.Recurrence != nil || patch.DueAt != nil {
+			return checkPatchedRecurrence(stored, patch)
+		}
+
+		return nil
+	}
+
+	task, err := s.repo.PatchTask(ctx, userID, id, version, patch, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if completes(before.Status, task.Status) {
+		s.spawnNext(ctx, task)
+	}
+
+	return task, nil
+}
+
+// storedTask loads the live task an update is about to overwrite.
+func (s *TaskService) storedTask(ctx context.Context, id int64) (*models.Task, error) {
+	current, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return nil, utils.ErrTaskNotFound
+	}
+
+	return current, nil
+}
+
+// uniqueLabels drops blank and repeated label names, so "all" matching can
+// compare the number of distinct names found against the number requested.
+func uniqueLabels(labels []string) []string {
+	unique := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label != "" && !slices.Contains(unique, label) {
+			unique = append(unique, label)
+		}
+	}
+	return unique
+}
+
+// normalizePriority defaults a missing priority to medium and rejects
+// unknown ones.
+func normalizePriority(task *models.Task) error {
+	if task.Priority == "" {
+		task.Priority = models.PriorityMedium
+		return nil
+	}
+
+	return utils.ValidatePriority(task.Priority)
+}
+
+func (s *TaskService) requireRole(ctx context.Context, userID, taskID int64, required string) error {
+	role, err := s.repo.GetTaskRole(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.HasRole(role, required) {
+		return utils.ErrForbidden
+	}
+
+	return nil
 }
@@ -1,52 +1,66 @@
 package service_test
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"testing"
+	"time"
 	m "todo_list_api/internal/task/mocks"
 	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
 	"todo_list_api/pkg/models"
 	"todo_list_api/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCreateTask(t *testing.T) {
 	mockRepo := new(m.MockRepository)
-	svc := service.NewTaskService(mockRepo)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
 
 	t.Run("should return error if title is empty", func(t *testing.T) {
 		task := &models.Task{Status: "Pending"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrEmptyTitle)
 	})
 
 	t.Run("should return error if status is empty", func(t *testing.T) {
 		task := &models.Task{Title: "New Task"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrEmptyStatus)
 	})
 
 	t.Run("should return error if status is invalid", func(t *testing.T) {
 		task := &models.Task{Title: "New Task", Status: "Unknown"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrInvalidStatus)
 	})
 
+	t.Run("should return error if priority is invalid", func(t *testing.T) {
+		task := &models.Task{Title: "New Task", Status: "Pending", Priority: "whenever"}
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrInvalidPriority)
+	})
+
 	t.Run("should create task if data is valid", func(t *testing.T) {
 		task := &models.Task{Title: "New Task", Status: "Pending"}
-		mockRepo.On("CreateTask", task).Return(nil).Once()
+		mockRepo.On("CreateTask", mock.Anything, task).Return(nil).Once()
 
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.NoError(t, err)
+		assert.Equal(t, userID, task.OwnerID)
+		assert.Equal(t, models.PriorityMedium, task.Priority)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return error if repository fails", func(t *testing.T) {
 		task := &models.Task{Title: "New Task", Status: "Pending"}
-		mockRepo.On("CreateTask", task).Return(errors.New("repository error")).Once()
+		mockRepo.On("CreateTask", mock.Anything, task).Return(errors.New("repository error")).Once()
 
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
@@ -54,27 +68,38 @@ func TestCreateTask(t *testing.T) {
 
 func TestDeleteTask(t *testing.T) {
 	mockRepo := new(m.MockRepository)
-	svc := service.NewTaskService(mockRepo)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
 
 	taskID := int64(1)
 
 	t.Run("should a error if id is invalid", func(t *testing.T) {
-		err := svc.DeleteTask(-1)
+		err := svc.DeleteTask(context.Background(), userID, -1, 0)
 		assert.ErrorIs(t, err, utils.ErrInvalidId)
 	})
 
+	t.Run("should return ErrForbidden if the user is not the owner", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleEditor, nil).Once()
+
+		err := svc.DeleteTask(context.Background(), userID, taskID, 0)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("must delete a task from the task table", func(t *testing.T) {
-		mockRepo.On("DeleteTask", taskID).Return(nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("DeleteTask", mock.Anything, userID, taskID, int64(0)).Return(nil).Once()
 
-		err := svc.DeleteTask(taskID)
+		err := svc.DeleteTask(context.Background(), userID, taskID, 0)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return error if repository fails", func(t *testing.T) {
-		mockRepo.On("DeleteTask", taskID).Return(errors.New("repository error")).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("DeleteTask", mock.Anything, userID, taskID, int64(0)).Return(errors.New("repository error")).Once()
 
-		err := svc.DeleteTask(taskID)
+		err := svc.DeleteTask(context.Background(), userID, taskID, 0)
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
@@ -82,12 +107,13 @@ func TestDeleteTask(t *testing.T) {
 
 func TestGetTask(t *testing.T) {
 	mockRepo := new(m.MockRepository)
-	svc := service.NewTaskService(mockRepo)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
 
 	taskID := int64(1)
 
 	t.Run("should return error if ID is invalid", func(t *testing.T) {
-		_, err := svc.GetTask(-1)
+		_, err := svc.GetTask(context.Background(), userID, -1)
 		assert.ErrorIs(t, err, utils.ErrInvalidId)
 	})
 
@@ -97,28 +123,56 @@ func TestGetTask(t *testing.T) {
 			Title: "Valid Task",
 		}
 
-		mockRepo.On("GetTask", taskID).Return(mockTask, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, taskID).Return(mockTask, nil).Once()
+		mockRepo.On("GetTaskProgress", mock.Anything, taskID).Return(&models.TaskProgress{}, nil).Once()
+		mockRepo.On("CountComments", mock.Anything, taskID).Return(0, nil).Once()
 
-		task, err := svc.GetTask(taskID)
+		task, err := svc.GetTask(context.Background(), userID, taskID)
 		assert.NoError(t, err)
 		assert.NotNil(t, task)
 		assert.Equal(t, mockTask, task)
+		assert.Nil(t, task.Progress)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should roll up the progress of the subtasks", func(t *testing.T) {
+		progress := &models.TaskProgress{Total: 4, Completed: 3, Percent: 75}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, taskID).Return(&models.Task{ID: taskID}, nil).Once()
+		mockRepo.On("GetTaskProgress", mock.Anything, taskID).Return(progress, nil).Once()
+		mockRepo.On("CountComments", mock.Anything, taskID).Return(2, nil).Once()
+
+		task, err := svc.GetTask(context.Background(), userID, taskID)
+		assert.NoError(t, err)
+		assert.Equal(t, progress, task.Progress)
+		assert.Equal(t, 2, *task.CommentCount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrForbidden if the task is not shared with the user", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return("", nil).Once()
+
+		task, err := svc.GetTask(context.Background(), userID, taskID)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		assert.Nil(t, task)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return error if task not found", func(t *testing.T) {
-		mockRepo.On("GetTask", taskID).Return((*models.Task)(nil), nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return("", utils.ErrTaskNotFound).Once()
 
-		task, err := svc.GetTask(taskID)
+		task, err := svc.GetTask(context.Background(), userID, taskID)
 		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
 		assert.Nil(t, task)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return error if repository fails", func(t *testing.T) {
-		mockRepo.On("GetTask", taskID).Return((*models.Task)(nil), errors.New("repository error")).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, taskID).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, taskID).Return((*models.Task)(nil), errors.New("repository error")).Once()
 
-		task, err := svc.GetTask(taskID)
+		task, err := svc.GetTask(context.Background(), userID, taskID)
 		assert.Error(t, err)
 		assert.Nil(t, task)
 		mockRepo.AssertExpectations(t)
@@ -127,71 +181,263 @@ func TestGetTask(t *testing.T) {
 
 func TestListTasks(t *testing.T) {
 	mockRepo := new(m.MockRepository)
-	svc := service.NewTaskService(mockRepo)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
 
 	t.Run("should return a task list", func(t *testing.T) {
-		mockTask := []*models.Task{
-			{ID: 1, Title: "Task 1"},
-			{ID: 2, Title: "Task 2"},
+		mockPage := &models.TaskPage{
+			Tasks: []*models.Task{
+				{ID: 1, Title: "Task 1"},
+				{ID: 2, Title: "Task 2"},
+			},
+			NextCursor: "next",
 		}
 
-		mockRepo.On("ListTasks").Return(mockTask, nil).Once()
+		expectedFilter := models.TaskFilter{UserID: userID, LabelMatch: models.LabelMatchAny, Sort: models.SortCreatedAt, Order: models.OrderAsc, Limit: models.DefaultPageSize}
+		mockRepo.On("ListTasks", mock.Anything, expectedFilter).Return(mockPage, nil).Once()
+
+		page, err := svc.ListTasks(context.Background(), userID, models.TaskFilter{})
+		assert.NoError(t, err)
+		assert.NotNil(t, page)
+		assert.Equal(t, 2, len(page.Tasks))
+		assert.Equal(t, mockPage, page)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return an empty list instead of nil", func(t *testing.T) {
+		filter := models.TaskFilter{UserID: userID, Status: "Pending", LabelMatch: models.LabelMatchAny, Sort: models.SortTitle, Order: models.OrderDesc, Limit: 5}
+		mockRepo.On("ListTasks", mock.Anything, filter).Return(&models.TaskPage{}, nil).Once()
+
+		page, err := svc.ListTasks(context.Background(), userID, filter)
+		assert.NoError(t, err)
+		assert.NotNil(t, page.Tasks)
+		assert.Empty(t, page.Tasks)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should drop blank and repeated label names", func(t *testing.T) {
+		mockRepo.On("ListTasks", mock.Anything, mock.MatchedBy(func(filter models.TaskFilter) bool {
+			return slices.Equal(filter.Labels, []string{"bug", "backend"}) && filter.LabelMatch == models.LabelMatchAll
+		})).Return(&models.TaskPage{}, nil).Once()
 
-		tasks, err := svc.ListTasks()
+		_, err := svc.ListTasks(context.Background(), userID, models.TaskFilter{Labels: []string{"bug", " ", "backend", "bug"}, LabelMatch: models.LabelMatchAll})
 		assert.NoError(t, err)
-		assert.NotNil(t, tasks)
-		assert.Equal(t, 2, len(tasks))
-		assert.Equal(t, mockTask, tasks)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should return error if the filter is invalid", func(t *testing.T) {
+		_, err := svc.ListTasks(context.Background(), userID, models.TaskFilter{Status: "Unknown"})
+		assert.ErrorIs(t, err, utils.ErrInvalidStatus)
+
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{Sort: "description"})
+		assert.ErrorIs(t, err, utils.ErrInvalidSort)
+
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{Order: "sideways"})
+		assert.ErrorIs(t, err, utils.ErrInvalidOrder)
+
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{Limit: models.MaxPageSize + 1})
+		assert.ErrorIs(t, err, utils.ErrInvalidLimit)
+
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{LabelMatch: "some"})
+		assert.ErrorIs(t, err, utils.ErrInvalidFilter)
+
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{Priority: "whenever"})
+		assert.ErrorIs(t, err, utils.ErrInvalidPriority)
+
+		now := time.Now()
+		_, err = svc.ListTasks(context.Background(), userID, models.TaskFilter{DueAfter: &now, DueBefore: &now})
+		assert.ErrorIs(t, err, utils.ErrInvalidFilter)
+	})
+
 	t.Run("should return error if repository fails", func(t *testing.T) {
-		mockRepo.On("ListTasks").Return(([]*models.Task)(nil), errors.New("repository error")).Once()
+		mockRepo.On("ListTasks", mock.Anything, mock.Anything).Return((*models.TaskPage)(nil), errors.New("repository error")).Once()
 
-		tasks, err := svc.ListTasks()
+		page, err := svc.ListTasks(context.Background(), userID, models.TaskFilter{})
 		assert.Error(t, err)
-		assert.Nil(t, tasks)
+		assert.Nil(t, page)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSearchTasks(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return error if query is empty", func(t *testing.T) {
+		_, err := svc.SearchTasks(context.Background(), userID, "   ", 0)
+		assert.ErrorIs(t, err, utils.ErrEmptyQuery)
+	})
+
+	t.Run("should return error if limit is invalid", func(t *testing.T) {
+		_, err := svc.SearchTasks(context.Background(), userID, "deploy", models.MaxPageSize+1)
+		assert.ErrorIs(t, err, utils.ErrInvalidLimit)
+	})
+
+	t.Run("should trim the query and apply the default limit", func(t *testing.T) {
+		mockResults := []*models.TaskSearchResult{
+			{Task: models.Task{ID: 1, Title: "Deploy"}, Rank: 0.5, Snippet: "<mark>Deploy</mark>"},
+		}
+		mockRepo.On("SearchTasks", mock.Anything, userID, "deploy", models.DefaultPageSize).Return(mockResults, nil).Once()
+
+		results, err := svc.SearchTasks(context.Background(), userID, "  deploy ", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, mockResults, results)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if repository fails", func(t *testing.T) {
+		mockRepo.On("SearchTasks", mock.Anything, userID, "deploy", 5).Return(([]*models.TaskSearchResult)(nil), errors.New("repository error")).Once()
+
+		results, err := svc.SearchTasks(context.Background(), userID, "deploy", 5)
+		assert.Error(t, err)
+		assert.Nil(t, results)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestUpdateTask(t *testing.T) {
 	mockRepo := new(m.MockRepository)
-	svc := service.NewTaskService(mockRepo)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
 
 	t.Run("should return error if title is empty", func(t *testing.T) {
 		task := &models.Task{Status: "Pending"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrEmptyTitle)
 	})
 
 	t.Run("should return error if status is empty", func(t *testing.T) {
 		task := &models.Task{Title: "New Task"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrEmptyStatus)
 	})
 
 	t.Run("should return error if status is invalid", func(t *testing.T) {
 		task := &models.Task{Title: "New Task", Status: "Unknown"}
-		err := svc.CreateTask(task)
+		err := svc.CreateTask(context.Background(), userID, task)
 		assert.ErrorIs(t, err, utils.ErrInvalidStatus)
 	})
 
-	task := &models.Task{Title: "New Task", Status: "Pending"}
+	task := &models.Task{ID: 1, Title: "New Task", Status: "Pending"}
+
+	t.Run("should return ErrForbidden if the user is only a viewer", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, task.ID).Return(models.RoleViewer, nil).Once()
+
+		err := svc.UpdateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
 
 	t.Run("should update the task if data is valid", func(t *testing.T) {
-		mockRepo.On("UpdateTask", task).Return(nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, task.ID).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, task.ID).Return(&models.Task{ID: 1, Status: "In progress"}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, task).Return(&models.Task{ID: 1, Status: "In progress"}, nil).Once()
 
-		err := svc.UpdateTask(task)
+		err := svc.UpdateTask(context.Background(), userID, task)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should reject a transition the workflow does not allow from the locked row", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, task.ID).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, task.ID).Return(&models.Task{ID: 1, Status: "In progress"}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, task).Return(&models.Task{ID: 1, Status: "Completed"}, nil).Once()
+
+		err := svc.UpdateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrInvalidTransition)
+
+		var transitionErr *utils.TransitionError
+		assert.ErrorAs(t, err, &transitionErr)
+		assert.Equal(t, []string{"In progress"}, transitionErr.Allowed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrTaskNotFound if the task does not exist", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, task.ID).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, task.ID).Return((*models.Task)(nil), nil).Once()
+
+		err := svc.UpdateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should return error if repository fails", func(t *testing.T) {
-		mockRepo.On("UpdateTask", task).Return(errors.New("repository error")).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, task.ID).Return(models.RoleOwner, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, task.ID).Return(&models.Task{ID: 1, Status: "Pending"}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, task).Return(&models.Task{ID: 1, Status: "Pending"}, errors.New("repository error")).Once()
 
-		err := svc.UpdateTask(task)
+		err := svc.UpdateTask(context.Background(), userID, task)
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestPatchTask(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return error if the title is patched to empty", func(t *testing.T) {
+		empty := ""
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{Title: &empty})
+		assert.ErrorIs(t, err, utils.ErrEmptyTitle)
+	})
+
+	t.Run("should return error if the status is invalid", func(t *testing.T) {
+		status := "Unknown"
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{Status: &status})
+		assert.ErrorIs(t, err, utils.ErrInvalidStatus)
+	})
+
+	status := "Completed"
+	patch := &models.TaskPatch{Status: &status}
+
+	t.Run("should return ErrForbidden if the user is only a viewer", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleViewer, nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, patch)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should patch the task if the patch is valid", func(t *testing.T) {
+		expected := &models.Task{ID: 1, Title: "Task", Status: status}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(1), int64(0), patch).Return(&models.Task{ID: 1, Status: "Pending"}, expected, nil).Once()
+
+		task, err := svc.PatchTask(context.Background(), userID, 1, 0, patch)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a status patch the workflow does not allow", func(t *testing.T) {
+		pending := "Pending"
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(1), int64(0), mock.Anything).Return(&models.Task{ID: 1, Status: "Completed"}, (*models.Task)(nil), nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{Status: &pending})
+		assert.ErrorIs(t, err, utils.ErrInvalidTransition)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return the current task if the patch is empty", func(t *testing.T) {
+		expected := &models.Task{ID: 1, Title: "Task", Status: "Pending"}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(expected, nil).Once()
+
+		task, err := svc.PatchTask(context.Background(), userID, 1, 0, &models.TaskPatch{})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("should fail the precondition of an empty patch against a stale version", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Version: 4}, nil).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 3, &models.TaskPatch{})
+		assert.ErrorIs(t, err, utils.ErrPreconditionFailed)
+		mockRepo.AssertExpectations(t)
+	})
+}
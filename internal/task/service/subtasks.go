@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// ListSubtasks returns the whole subtree below a task. Access to the task
+// grants access to its subtasks, since attaching one requires editing rights
+// on the parent.
+func (s *TaskService) ListSubtasks(ctx context.Context, userID, id int64) ([]*models.Task, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if err := s.requireRole(ctx, userID, id, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := s.repo.ListSubtasks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtasks == nil {
+		return []*models.Task{}, nil
+	}
+
+	return subtasks, nil
+}
+
+// checkParent verifies that task id may be nested under parentID: the parent
+// must be a live task the user can edit, and must not be the task itself. An
+// id of 0 stands for a task that does not exist yet. Whether the parent is
+// one of the task's subtasks is checked by the repository, under the same
+// lock as the move.
+func (s *TaskService) checkParent(ctx context.Context, userID, id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+
+	if *parentID <= 0 {
+		return utils.ErrInvalidParent
+	}
+
+	parent, err := s.repo.GetTask(ctx, *parentID)
+	if err != nil {
+		return err
+	}
+
+	if parent == nil {
+		return utils.ErrInvalidParent
+	}
+
+	if err := s.requireRole(ctx, userID, *parentID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	if *parentID == id {
+		return utils.ErrParentCycle
+	}
+
+	return nil
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListSubtasks(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return ErrForbidden if the task is not shared with the user", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return("", nil).Once()
+
+		_, err := svc.ListSubtasks(context.Background(), userID, 1)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return an empty list instead of nil", func(t *testing.T) {
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleViewer, nil).Once()
+		mockRepo.On("ListSubtasks", mock.Anything, int64(1)).Return([]*models.Task(nil), nil).Once()
+
+		subtasks, err := svc.ListSubtasks(context.Background(), userID, 1)
+		assert.NoError(t, err)
+		assert.NotNil(t, subtasks)
+		assert.Empty(t, subtasks)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskParent(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+	parentID := int64(2)
+
+	t.Run("should create a subtask under a parent the user can edit", func(t *testing.T) {
+		task := &models.Task{Title: "Child", Status: "Pending", ParentID: &parentID}
+		mockRepo.On("GetTask", mock.Anything, parentID).Return(&models.Task{ID: parentID}, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, parentID).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("CreateTask", mock.Anything, task).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateTask(context.Background(), userID, task))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a parent that does not exist", func(t *testing.T) {
+		task := &models.Task{Title: "Child", Status: "Pending", ParentID: &parentID}
+		mockRepo.On("GetTask", mock.Anything, parentID).Return((*models.Task)(nil), nil).Once()
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrInvalidParent)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a parent the user can only view", func(t *testing.T) {
+		task := &models.Task{Title: "Child", Status: "Pending", ParentID: &parentID}
+		mockRepo.On("GetTask", mock.Anything, parentID).Return(&models.Task{ID: parentID}, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, parentID).Return(models.RoleViewer, nil).Once()
+
+		err := svc.CreateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject nesting a task under one of its own subtasks", func(t *testing.T) {
+		parent := &parentID
+		patch := &models.TaskPatch{ParentID: &parent}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending"}, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, parentID).Return(&models.Task{ID: parentID}, nil).Once()
+		mockRepo.On("GetTaskRole", mock.Anything, userID, parentID).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("PatchTask", mock.Anything, userID, int64(1), int64(0), patch).Return(&models.Task{ID: 1, Status: "Pending"}, (*models.Task)(nil), utils.ErrParentCycle).Once()
+
+		_, err := svc.PatchTask(context.Background(), userID, 1, 0, patch)
+		assert.ErrorIs(t, err, utils.ErrParentCycle)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a task as its own parent", func(t *testing.T) {
+		self := int64(1)
+		task := &models.Task{ID: 1, Title: "Task", Status: "Pending", ParentID: &self}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Twice()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending"}, nil).Twice()
+
+		err := svc.UpdateTask(context.Background(), userID, task)
+		assert.ErrorIs(t, err, utils.ErrParentCycle)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not re-check an unchanged parent", func(t *testing.T) {
+		task := &models.Task{ID: 1, Title: "Task", Status: "Pending", ParentID: &parentID}
+		mockRepo.On("GetTaskRole", mock.Anything, userID, int64(1)).Return(models.RoleEditor, nil).Once()
+		mockRepo.On("GetTask", mock.Anything, int64(1)).Return(&models.Task{ID: 1, Status: "Pending", ParentID: &parentID}, nil).Once()
+		mockRepo.On("UpdateTask", mock.Anything, userID, task).Return(&models.Task{ID: 1, Status: "Pending", ParentID: &parentID}, nil).Once()
+
+		assert.NoError(t, svc.UpdateTask(context.Background(), userID, task))
+		mockRepo.AssertExpectations(t)
+	})
+}
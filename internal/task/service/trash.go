@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+func (s *TaskService) ListTrash(ctx context.Context, userID int64) ([]*models.Task, error) {
+	tasks, err := s.repo.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tasks == nil {
+		return []*models.Task{}, nil
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask takes a task out of the user's trash. Roles are not consulted,
+// since they only cover live tasks: the repository restores the task only if
+// the user owns it.
+func (s *TaskService) RestoreTask(ctx context.Context, userID, id int64) (*models.Task, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	return s.repo.RestoreTask(ctx, userID, id)
+}
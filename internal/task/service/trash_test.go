@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	m "todo_list_api/internal/task/mocks"
+	"todo_list_api/internal/task/service"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListTrash(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return an empty list instead of nil", func(t *testing.T) {
+		mockRepo.On("ListTrash", mock.Anything, userID).Return(([]*models.Task)(nil), nil).Once()
+
+		tasks, err := svc.ListTrash(context.Background(), userID)
+		assert.NoError(t, err)
+		assert.NotNil(t, tasks)
+		assert.Empty(t, tasks)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRestoreTask(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewTaskService(mockRepo, workflow.Default())
+	userID := int64(7)
+
+	t.Run("should return ErrTaskNotFound if the task is not in the user's trash", func(t *testing.T) {
+		mockRepo.On("RestoreTask", mock.Anything, userID, int64(1)).Return((*models.Task)(nil), utils.ErrTaskNotFound).Once()
+
+		_, err := svc.RestoreTask(context.Background(), userID, 1)
+		assert.ErrorIs(t, err, utils.ErrTaskNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should restore the task from the user's trash", func(t *testing.T) {
+		expected := &models.Task{ID: 1, Title: "Task"}
+		mockRepo.On("RestoreTask", mock.Anything, userID, int64(1)).Return(expected, nil).Once()
+
+		task, err := svc.RestoreTask(context.Background(), userID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPurger(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	mockBlobs := new(m.MockBlobDeleter)
+	retention := 24 * time.Hour
+	purger := service.NewPurger(mockRepo, mockBlobs, retention)
+
+	t.Run("should purge the tasks trashed before the retention window and delete their blobs", func(t *testing.T) {
+		mockRepo.On("PurgeTasks", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+			return time.Since(cutoff) >= retention && time.Since(cutoff) < retention+time.Minute
+		})).Return(int64(2), []string{"a", "b"}, nil).Once()
+		mockBlobs.On("Delete", mock.Anything, "a").Return(nil).Once()
+		mockBlobs.On("Delete", mock.Anything, "b").Return(errors.New("unavailable")).Once()
+
+		purged, err := purger.Purge(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+		mockRepo.AssertExpectations(t)
+		mockBlobs.AssertExpectations(t)
+	})
+
+	t.Run("should stop running when the context is cancelled", func(t *testing.T) {
+		mockRepo.On("PurgeTasks", mock.Anything, mock.Anything).Return(int64(0), []string(nil), nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			purger.Run(ctx, time.Millisecond)
+			close(done)
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the purger did not stop")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+// Workflow lists the statuses a task may have and, for each status, the
+// statuses it may move to.
+type Workflow struct {
+	Statuses    []string            `json:"statuses"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// Default is the workflow used when no definition is configured. Completed
+// tasks can be reopened, but only back into progress.
+func Default() *Workflow {
+	return &Workflow{
+		Statuses: []string{models.StatusPending, models.StatusInProgress, models.StatusCompleted},
+		Transitions: map[string][]string{
+			models.StatusPending:    {models.StatusInProgress, models.StatusCompleted},
+			models.StatusInProgress: {models.StatusPending, models.StatusCompleted},
+			models.StatusCompleted:  {models.StatusInProgress},
+		},
+	}
+}
+
+// Load reads a workflow definition from a JSON file.
+func Load(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read workflow: %w", err)
+	}
+
+	var workflow Workflow
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("could not parse workflow: %w", err)
+	}
+
+	if err := workflow.validate(); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+
+	return &workflow, nil
+}
+
+func (w *Workflow) validate() error {
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, status := range w.Statuses {
+		if status == "" {
+			return errors.New("status cannot be empty")
+		}
+		if seen[status] {
+			return fmt.Errorf("status %q is listed twice", status)
+		}
+		seen[status] = true
+	}
+
+	// Completion timestamps and overdue detection are keyed on this status.
+	if !seen[models.StatusCompleted] {
+		return fmt.Errorf("status %q is required", models.StatusCompleted)
+	}
+
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition from %q to unknown status %q", from, to)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *Workflow) ValidateStatus(status string) error {
+	if !slices.Contains(w.Statuses, status) {
+		return utils.ErrInvalidStatus
+	}
+
+	return nil
+}
+
+// Initial is the status generated tasks start in: the first one listed.
+func (w *Workflow) Initial() string {
+	return w.Statuses[0]
+}
+
+// Next returns the statuses a task in status from may move to.
+func (w *Workflow) Next(from string) []string {
+	return slices.Clone(w.Transitions[from])
+}
+
+// Transition checks that a task may move from one status to another. Keeping
+// the same status is always allowed, and so is leaving a status the workflow
+// no longer knows, so tasks stranded by a definition change can be repaired.
+func (w *Workflow) Transition(from, to string) error {
+	if err := w.ValidateStatus(to); err != nil {
+		return err
+	}
+
+	if from == to || !slices.Contains(w.Statuses, from) || slices.Contains(w.Transitions[from], to) {
+		return nil
+	}
+
+	return &utils.TransitionError{From: from, To: to, Allowed: w.Next(from)}
+}
@@ -0,0 +1,76 @@
+package workflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"todo_list_api/internal/task/workflow"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition(t *testing.T) {
+	w := workflow.Default()
+
+	t.Run("should allow a listed transition and keeping the same status", func(t *testing.T) {
+		assert.NoError(t, w.Transition("Pending", "In progress"))
+		assert.NoError(t, w.Transition("Completed", "Completed"))
+	})
+
+	t.Run("should reject an unlisted transition with the allowed next statuses", func(t *testing.T) {
+		err := w.Transition("Completed", "Pending")
+		assert.ErrorIs(t, err, utils.ErrInvalidTransition)
+
+		var transitionErr *utils.TransitionError
+		assert.ErrorAs(t, err, &transitionErr)
+		assert.Equal(t, []string{"In progress"}, transitionErr.Allowed)
+	})
+
+	t.Run("should reject an unknown target status", func(t *testing.T) {
+		assert.ErrorIs(t, w.Transition("Pending", "Archived"), utils.ErrInvalidStatus)
+	})
+
+	t.Run("should let a task leave a status the workflow no longer knows", func(t *testing.T) {
+		assert.NoError(t, w.Transition("Blocked", "Pending"))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "workflow.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("should load a valid definition", func(t *testing.T) {
+		path := write(t, `{
+			"statuses": ["Pending", "In review", "Completed"],
+			"transitions": {"Pending": ["In review"], "In review": ["Pending", "Completed"]}
+		}`)
+
+		w, err := workflow.Load(path)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Pending", "Completed"}, w.Next("In review"))
+		assert.ErrorIs(t, w.Transition("Pending", "Completed"), utils.ErrInvalidTransition)
+		assert.ErrorIs(t, w.Transition("Completed", "Pending"), utils.ErrInvalidTransition)
+		assert.Equal(t, "Pending", w.Initial())
+	})
+
+	t.Run("should reject an invalid definition", func(t *testing.T) {
+		for _, content := range []string{
+			`not json`,
+			`{"statuses": ["Pending"]}`,
+			`{"statuses": ["Completed", "Completed"]}`,
+			`{"statuses": ["Completed"], "transitions": {"Completed": ["Archived"]}}`,
+		} {
+			_, err := workflow.Load(write(t, content))
+			assert.Error(t, err, content)
+		}
+	})
+
+	t.Run("should return an error if the file is missing", func(t *testing.T) {
+		_, err := workflow.Load(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
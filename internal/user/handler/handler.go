@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo_list_api/internal/httputil"
+	s "todo_list_api/internal/user/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Handler struct {
+	service s.Service
+}
+
+func NewHandler(service s.Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+
+	user, err := h.service.SignUp(r.Context(), &credentials)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		utils.WriteError(w, utils.ErrInvalidPayload)
+		return
+	}
+
+	token, err := h.service.Login(r.Context(), &credentials)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		utils.WriteError(w, utils.ErrFailedEncode)
+	}
+}
+
+// StreamToken hands the signed in user a token for opening event streams
+// where the Authorization header cannot be set.
+func (h *Handler) StreamToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := httputil.RequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.service.StreamToken(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, token)
+}
@@ -0,0 +1,179 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/user/handler"
+	m "todo_list_api/internal/user/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+func TestSignUp(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	credentials := &models.Credentials{Email: "user@example.com", Password: "password123"}
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/signup", bytes.NewBuffer([]byte("invalid json")))
+		rr := httptest.NewRecorder()
+
+		handler.SignUp(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "invalid_payload", decodeError(t, rr).Code)
+	})
+
+	t.Run("should return 409 if the email is already registered", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mockService.On("SignUp", mock.Anything, credentials).Return((*models.User)(nil), utils.ErrEmailTaken).Once()
+
+		handler.SignUp(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 422 if the credentials fail validation", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mockService.On("SignUp", mock.Anything, credentials).Return((*models.User)(nil), utils.ErrWeakPassword).Once()
+
+		handler.SignUp(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "weak_password", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 201 and the created user without the password hash", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		user := &models.User{ID: 1, Email: credentials.Email, PasswordHash: "hash"}
+		mockService.On("SignUp", mock.Anything, credentials).Return(user, nil).Once()
+
+		handler.SignUp(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "hash")
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	credentials := &models.Credentials{Email: "user@example.com", Password: "password123"}
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer([]byte("invalid json")))
+		rr := httptest.NewRecorder()
+
+		handler.Login(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 401 if the credentials are invalid", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mockService.On("Login", mock.Anything, credentials).Return((*models.AuthToken)(nil), utils.ErrInvalidCredentials).Once()
+
+		handler.Login(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "invalid_credentials", decodeError(t, rr).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 500 if service fails", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mockService.On("Login", mock.Anything, credentials).Return((*models.AuthToken)(nil), errors.New("service error")).Once()
+
+		handler.Login(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 200 and the token", func(t *testing.T) {
+		body, _ := json.Marshal(credentials)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		expectedToken := &models.AuthToken{Token: "token", ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+		mockService.On("Login", mock.Anything, credentials).Return(expectedToken, nil).Once()
+
+		handler.Login(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var actualToken models.AuthToken
+		err := json.NewDecoder(rr.Body).Decode(&actualToken)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedToken, &actualToken)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestStreamToken(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 401 if the user is not authenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/stream-token", nil)
+		rr := httptest.NewRecorder()
+
+		handler.StreamToken(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should return 200 and the token", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/stream-token", nil)
+		req = req.WithContext(auth.WithUserID(req.Context(), 7))
+		rr := httptest.NewRecorder()
+
+		expectedToken := &models.AuthToken{Token: "token", ExpiresAt: time.Now().Add(time.Minute).UTC().Truncate(time.Second)}
+		mockService.On("StreamToken", mock.Anything, int64(7)).Return(expectedToken, nil).Once()
+
+		handler.StreamToken(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var actualToken models.AuthToken
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&actualToken))
+		assert.Equal(t, expectedToken.Token, actualToken.Token)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// Login implements user.Service.
+func (m *MockService) Login(ctx context.Context, credentials *models.Credentials) (*models.AuthToken, error) {
+	args := m.Called(ctx, credentials)
+	return args.Get(0).(*models.AuthToken), args.Error(1)
+}
+
+// SignUp implements user.Service.
+func (m *MockService) SignUp(ctx context.Context, credentials *models.Credentials) (*models.User, error) {
+	args := m.Called(ctx, credentials)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// StreamToken implements user.Service.
+func (m *MockService) StreamToken(ctx context.Context, userID int64) (*models.AuthToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*models.AuthToken), args.Error(1)
+}
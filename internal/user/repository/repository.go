@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+type UserRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewUserRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &UserRepository{db: db, timeout: timeout}
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "INSERT INTO users (email, password_hash, created_at) VALUES ($1, $2, $3) RETURNING id"
+	user.CreatedAt = time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		user.Email,
+		user.PasswordHash,
+		user.CreatedAt,
+	).Scan(&user.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return utils.ErrEmailTaken
+	}
+
+	return err
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT id, email, password_hash, created_at FROM users WHERE email = $1"
+	user := &models.User{}
+	if err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/user/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db, time.Second)
+
+	const query = "INSERT INTO users \\(email, password_hash, created_at\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id"
+
+	t.Run("must insert the user and return the generated id", func(t *testing.T) {
+		user := &models.User{Email: "user@example.com", PasswordHash: "hash"}
+
+		mock.ExpectQuery(query).
+			WithArgs(user.Email, user.PasswordHash, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		err := repo.CreateUser(context.Background(), user)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), user.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrEmailTaken if the email already exists", func(t *testing.T) {
+		user := &models.User{Email: "user@example.com", PasswordHash: "hash"}
+
+		mock.ExpectQuery(query).
+			WithArgs(user.Email, user.PasswordHash, sqlmock.AnyArg()).
+			WillReturnError(&pq.Error{Code: "23505"})
+
+		err := repo.CreateUser(context.Background(), user)
+		assert.ErrorIs(t, err, utils.ErrEmailTaken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db, time.Second)
+
+	const query = "SELECT id, email, password_hash, created_at FROM users WHERE email = \\$1"
+
+	t.Run("must return the user with the given email", func(t *testing.T) {
+		expectedUser := &models.User{ID: 1, Email: "user@example.com", PasswordHash: "hash", CreatedAt: time.Now()}
+
+		mock.ExpectQuery(query).
+			WithArgs(expectedUser.Email).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at"}).
+				AddRow(expectedUser.ID, expectedUser.Email, expectedUser.PasswordHash, expectedUser.CreatedAt))
+
+		user, err := repo.GetUserByEmail(context.Background(), expectedUser.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return nil if the user does not exist", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("missing@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "created_at"}))
+
+		user, err := repo.GetUserByEmail(context.Background(), "missing@example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return a error if the query fails", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("user@example.com").
+			WillReturnError(errors.New("query invalid"))
+
+		_, err := repo.GetUserByEmail(context.Background(), "user@example.com")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
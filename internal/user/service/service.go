@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"todo_list_api/internal/auth"
+	r "todo_list_api/internal/user/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+const minPasswordLength = 8
+
+type Service interface {
+	Login(ctx context.Context, credentials *models.Credentials) (*models.AuthToken, error)
+	SignUp(ctx context.Context, credentials *models.Credentials) (*models.User, error)
+	StreamToken(ctx context.Context, userID int64) (*models.AuthToken, error)
+}
+
+type UserService struct {
+	repo   r.Repository
+	tokens *auth.TokenManager
+}
+
+func NewUserService(repo r.Repository, tokens *auth.TokenManager) Service {
+	return &UserService{repo: repo, tokens: tokens}
+}
+
+func (s *UserService) SignUp(ctx context.Context, credentials *models.Credentials) (*models.User, error) {
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentials.Password == "" {
+		return nil, utils.ErrEmptyPassword
+	}
+
+	if len(credentials.Password) < minPasswordLength {
+		return nil, utils.ErrWeakPassword
+	}
+
+	hash, err := auth.HashPassword(credentials.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Email: email, PasswordHash: hash}
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) Login(ctx context.Context, credentials *models.Credentials) (*models.AuthToken, error) {
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, utils.ErrInvalidCredentials
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || !auth.CheckPassword(user.PasswordHash, credentials.Password) {
+		return nil, utils.ErrInvalidCredentials
+	}
+
+	token, expiresAt, err := s.tokens.Issue(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// StreamToken issues the short-lived token a signed in user passes in the
+// query string to open an event stream from a browser.
+func (s *UserService) StreamToken(ctx context.Context, userID int64) (*models.AuthToken, error) {
+	token, expiresAt, err := s.tokens.IssueStreamToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", utils.ErrEmptyEmail
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", utils.ErrInvalidEmail
+	}
+
+	return email, nil
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo_list_api/internal/auth"
+	m "todo_list_api/internal/user/mocks"
+	"todo_list_api/internal/user/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSignUp(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+	svc := service.NewUserService(mockRepo, tokens)
+
+	t.Run("should return error if email is empty", func(t *testing.T) {
+		_, err := svc.SignUp(context.Background(), &models.Credentials{Password: "password123"})
+		assert.ErrorIs(t, err, utils.ErrEmptyEmail)
+	})
+
+	t.Run("should return error if email is invalid", func(t *testing.T) {
+		_, err := svc.SignUp(context.Background(), &models.Credentials{Email: "not-an-email", Password: "password123"})
+		assert.ErrorIs(t, err, utils.ErrInvalidEmail)
+	})
+
+	t.Run("should return error if password is empty", func(t *testing.T) {
+		_, err := svc.SignUp(context.Background(), &models.Credentials{Email: "user@example.com"})
+		assert.ErrorIs(t, err, utils.ErrEmptyPassword)
+	})
+
+	t.Run("should return error if password is too short", func(t *testing.T) {
+		_, err := svc.SignUp(context.Background(), &models.Credentials{Email: "user@example.com", Password: "short"})
+		assert.ErrorIs(t, err, utils.ErrWeakPassword)
+	})
+
+	t.Run("should normalize the email and store a hashed password", func(t *testing.T) {
+		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+			return user.Email == "user@example.com" && auth.CheckPassword(user.PasswordHash, "password123")
+		})).Return(nil).Once()
+
+		user, err := svc.SignUp(context.Background(), &models.Credentials{Email: " User@Example.com ", Password: "password123"})
+		assert.NoError(t, err)
+		assert.Equal(t, "user@example.com", user.Email)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if repository fails", func(t *testing.T) {
+		mockRepo.On("CreateUser", mock.Anything, mock.Anything).Return(utils.ErrEmailTaken).Once()
+
+		_, err := svc.SignUp(context.Background(), &models.Credentials{Email: "user@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, utils.ErrEmailTaken)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+	svc := service.NewUserService(mockRepo, tokens)
+
+	hash, err := auth.HashPassword("password123")
+	assert.NoError(t, err)
+	user := &models.User{ID: 7, Email: "user@example.com", PasswordHash: hash}
+
+	t.Run("should return a signed token if the credentials are valid", func(t *testing.T) {
+		mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(user, nil).Once()
+
+		token, err := svc.Login(context.Background(), &models.Credentials{Email: "user@example.com", Password: "password123"})
+		assert.NoError(t, err)
+
+		userID, err := tokens.Verify(token.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrInvalidCredentials if the password is wrong", func(t *testing.T) {
+		mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(user, nil).Once()
+
+		_, err := svc.Login(context.Background(), &models.Credentials{Email: "user@example.com", Password: "wrong password"})
+		assert.ErrorIs(t, err, utils.ErrInvalidCredentials)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return ErrInvalidCredentials if the user does not exist", func(t *testing.T) {
+		mockRepo.On("GetUserByEmail", mock.Anything, "missing@example.com").Return((*models.User)(nil), nil).Once()
+
+		_, err := svc.Login(context.Background(), &models.Credentials{Email: "missing@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, utils.ErrInvalidCredentials)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if repository fails", func(t *testing.T) {
+		mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return((*models.User)(nil), errors.New("repository error")).Once()
+
+		_, err := svc.Login(context.Background(), &models.Credentials{Email: "user@example.com", Password: "password123"})
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestStreamToken(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	tokens := auth.NewTokenManager([]byte("secret"), time.Hour)
+	svc := service.NewUserService(mockRepo, tokens)
+
+	t.Run("should return a token that only opens streams", func(t *testing.T) {
+		token, err := svc.StreamToken(context.Background(), 7)
+		assert.NoError(t, err)
+
+		userID, err := tokens.VerifyStreamToken(token.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), userID)
+
+		_, err = tokens.Verify(token.Token)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+}
@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo_list_api/internal/auth"
+	"todo_list_api/internal/webhook/handler"
+	m "todo_list_api/internal/webhook/mocks"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) models.ErrorResponse {
+	var response models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response
+}
+
+const userID = int64(7)
+
+func withUser(req *http.Request) *http.Request {
+	return req.WithContext(auth.WithUserID(req.Context(), userID))
+}
+
+func TestCreateWebhook(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the payload is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(`{`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateWebhook(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 201 with the subscription owned by the caller", func(t *testing.T) {
+		expected := &models.Webhook{OwnerID: userID, URL: "https://ci.example.com/hook", Events: []string{models.WebhookTaskDeleted}}
+		mockService.On("CreateWebhook", mock.Anything, expected).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(`{"id":9,"owner_id":1,"url":"https://ci.example.com/hook","events":["task.deleted"]}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateWebhook(rr, withUser(req))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 422 if the url is invalid", func(t *testing.T) {
+		mockService.On("CreateWebhook", mock.Anything, mock.Anything).Return(utils.ErrInvalidWebhookURL).Once()
+
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(`{"url":"ftp://example.com"}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateWebhook(rr, withUser(req))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "invalid_webhook_url", decodeError(t, rr).Code)
+	})
+}
+
+func TestListDeliveries(t *testing.T) {
+	mockService := new(m.MockService)
+	handler := handler.NewHandler(mockService)
+
+	t.Run("should return 400 if the limit is invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/webhooks/2/deliveries?limit=abc", nil)
+		req.SetPathValue("id", "2")
+		rr := httptest.NewRecorder()
+
+		handler.ListDeliveries(rr, withUser(req))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return 404 for someone else's webhook", func(t *testing.T) {
+		mockService.On("ListDeliveries", mock.Anything, userID, int64(2), "", 0).Return((*models.WebhookDeliveryPage)(nil), utils.ErrWebhookNotFound).Once()
+
+		req, _ := http.NewRequest("GET", "/webhooks/2/deliveries", nil)
+		req.SetPathValue("id", "2")
+		rr := httptest.NewRecorder()
+
+		handler.ListDeliveries(rr, withUser(req))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "webhook_not_found", decodeError(t, rr).Code)
+	})
+}
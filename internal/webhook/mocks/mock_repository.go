@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"time"
+	"todo_list_api/internal/webhook/repository"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+// ClaimDeliveries implements Repository.
+func (m *MockRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*repository.DueDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*repository.DueDelivery), args.Error(1)
+}
+
+// CreateWebhook implements Repository.
+func (m *MockRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+// DeleteWebhook implements Repository.
+func (m *MockRepository) DeleteWebhook(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
+	return args.Error(0)
+}
+
+// EnqueueDeliveries implements Repository.
+func (m *MockRepository) EnqueueDeliveries(ctx context.Context, event, key string, taskID int64, payload []byte) (int64, error) {
+	args := m.Called(ctx, event, key, taskID, payload)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// GetWebhook implements Repository.
+func (m *MockRepository) GetWebhook(ctx context.Context, ownerID, id int64) (*models.Webhook, error) {
+	args := m.Called(ctx, ownerID, id)
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+// ListDeliveries implements Repository.
+func (m *MockRepository) ListDeliveries(ctx context.Context, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	args := m.Called(ctx, webhookID, cursor, limit)
+	return args.Get(0).(*models.WebhookDeliveryPage), args.Error(1)
+}
+
+// ListWebhooks implements Repository.
+func (m *MockRepository) ListWebhooks(ctx context.Context, ownerID int64) ([]*models.Webhook, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
+
+// RecordAttempt implements Repository.
+func (m *MockRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+// CreateWebhook implements webhook.Service.
+func (m *MockService) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+// DeleteWebhook implements webhook.Service.
+func (m *MockService) DeleteWebhook(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+// GetWebhook implements webhook.Service.
+func (m *MockService) GetWebhook(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+// ListDeliveries implements webhook.Service.
+func (m *MockService) ListDeliveries(ctx context.Context, userID, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	args := m.Called(ctx, userID, webhookID, cursor, limit)
+	return args.Get(0).(*models.WebhookDeliveryPage), args.Error(1)
+}
+
+// ListWebhooks implements webhook.Service.
+func (m *MockService) ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
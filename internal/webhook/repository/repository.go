@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo_list_api/internal/db"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, ownerID, id int64) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, ownerID int64) ([]*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, ownerID, id int64) error
+	EnqueueDeliveries(ctx context.Context, event, key string, taskID int64, payload []byte) (int64, error)
+	ListDeliveries(ctx context.Context, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*DueDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// DueDelivery is a claimed delivery together with where to send it and how
+// to sign it.
+type DueDelivery struct {
+	Delivery *models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+const (
+	webhookColumns  = "id, owner_id, url, secret, events, created_at"
+	deliveryColumns = "d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at, d.response_status, d.last_error, d.created_at, d.delivered_at"
+)
+
+type WebhookRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func NewWebhookRepository(db *sql.DB, timeout time.Duration) Repository {
+	return &WebhookRepository{db: db, timeout: timeout}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row scanner, webhook *models.Webhook) error {
+	return row.Scan(
+		&webhook.ID,
+		&webhook.OwnerID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.CreatedAt,
+	)
+}
+
+func scanDelivery(row scanner, delivery *models.WebhookDelivery, extra ...any) error {
+	var payload []byte
+	dest := []any{
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.Event,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	delivery.Payload = payload
+	return nil
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "INSERT INTO webhooks (owner_id, url, secret, events, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	webhook.CreatedAt = time.Now()
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		webhook.OwnerID,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(webhook.Events),
+		webhook.CreatedAt,
+	).Scan(&webhook.ID)
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, ownerID, id int64) (*models.Webhook, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + webhookColumns + " FROM webhooks WHERE id = $1 AND owner_id = $2"
+	webhook := &models.Webhook{}
+	if err := scanWebhook(r.db.QueryRowContext(ctx, query, id, ownerID), webhook); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, ownerID int64) ([]*models.Webhook, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "SELECT " + webhookColumns + " FROM webhooks WHERE owner_id = $1 ORDER BY id ASC"
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		var webhook models.Webhook
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, ownerID, id int64) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "DELETE FROM webhooks WHERE id = $1 AND owner_id = $2"
+	result, err := r.db.ExecContext(ctx, query, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return utils.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries queues payload for every webhook subscribed to event
+// whose owner can see the task, and returns how many were queued. Webhooks
+// that already have a delivery for key are skipped.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event, key string, taskID int64, payload []byte) (int64, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `INSERT INTO webhook_deliveries (webhook_id, event, event_key, payload, status, next_attempt_at, created_at)
+		SELECT w.id, $1, $2, $3, 'pending', $5, $5 FROM webhooks w
+		WHERE (cardinality(w.events) = 0 OR $1 = ANY(w.events))
+		AND EXISTS (SELECT 1 FROM task_access a WHERE a.task_id = $4 AND a.user_id = w.owner_id)
+		ON CONFLICT (webhook_id, event_key) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, query, event, key, payload, taskID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListDeliveries pages through the delivery log of a webhook, newest first.
+// The cursor is the opaque id of the last delivery of the previous page.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var before int64
+	if cursor != "" {
+		var err error
+		if before, err = utils.DecodeIDCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	const query = "SELECT " + deliveryColumns + " FROM webhook_deliveries d WHERE d.webhook_id = $1 AND ($2 = 0 OR d.id < $2) ORDER BY d.id DESC LIMIT $3"
+	rows, err := r.db.QueryContext(ctx, query, webhookID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanDelivery(rows, &delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextCursor = utils.EncodeIDCursor(page.Deliveries[len(page.Deliveries)-1].ID)
+	}
+
+	return page, nil
+}
+
+// ClaimDeliveries picks up to limit pending deliveries that are due and
+// pushes their next attempt back by lease, so that other replicas skip them
+// while they are in flight. A claim whose attempt is never recorded, because
+// the process died, becomes due again once the lease runs out.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*DueDelivery, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `UPDATE webhook_deliveries d SET next_attempt_at = $2 FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING ` + deliveryColumns + ", w.url, w.secret"
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []*DueDelivery{}
+	for rows.Next() {
+		claim := &DueDelivery{Delivery: &models.WebhookDelivery{}}
+		if err := scanDelivery(rows, claim.Delivery, &claim.URL, &claim.Secret); err != nil {
+			return nil, err
+		}
+		due = append(due, claim)
+	}
+	return due, rows.Err()
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ctx, cancel := db.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = "UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4, response_status = $5, last_error = $6, delivered_at = $7 WHERE id = $1"
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.DeliveredAt,
+	)
+	return err
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo_list_api/internal/webhook/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var deliveryColumns = []string{"id", "webhook_id", "event", "payload", "status", "attempts", "next_attempt_at", "response_status", "last_error", "created_at", "delivered_at"}
+
+func TestCreateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db, time.Second)
+
+	t.Run("must store the subscription", func(t *testing.T) {
+		webhook := &models.Webhook{OwnerID: 7, URL: "https://ci.example.com/hook", Secret: "s3cret", Events: []string{models.WebhookTaskCreated}}
+
+		mock.ExpectQuery("INSERT INTO webhooks \\(owner_id, url, secret, events, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING id").
+			WithArgs(int64(7), "https://ci.example.com/hook", "s3cret", "{\"task.created\"}", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+		assert.NoError(t, repo.CreateWebhook(context.Background(), webhook))
+		assert.Equal(t, int64(2), webhook.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db, time.Second)
+
+	t.Run("should return ErrWebhookNotFound for someone else's webhook", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM webhooks WHERE id = \\$1 AND owner_id = \\$2").
+			WithArgs(int64(2), int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteWebhook(context.Background(), 8, 2)
+		assert.ErrorIs(t, err, utils.ErrWebhookNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEnqueueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db, time.Second)
+
+	t.Run("must queue the event once for subscribers who can see the task", func(t *testing.T) {
+		payload := []byte(`{"event":"task.updated","task_id":5}`)
+		mock.ExpectExec("INSERT INTO webhook_deliveries \\(webhook_id, event, event_key, .+ SELECT w.id, \\$1, \\$2, \\$3, 'pending', \\$5, \\$5 FROM webhooks w WHERE \\(cardinality\\(w.events\\) = 0 OR \\$1 = ANY\\(w.events\\)\\) AND EXISTS \\(SELECT 1 FROM task_access a WHERE a.task_id = \\$4 AND a.user_id = w.owner_id\\) ON CONFLICT \\(webhook_id, event_key\\) DO NOTHING").
+			WithArgs(models.WebhookTaskUpdated, "task-event-11", payload, int64(5), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		queued, err := repo.EnqueueDeliveries(context.Background(), models.WebhookTaskUpdated, "task-event-11", 5, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), queued)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db, time.Second)
+
+	t.Run("must lease due deliveries along with their target", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("UPDATE webhook_deliveries d SET next_attempt_at = \\$2 FROM webhooks w .+ FOR UPDATE SKIP LOCKED\\) RETURNING .+ w.url, w.secret").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 10).
+			WillReturnRows(sqlmock.NewRows(append(deliveryColumns, "url", "secret")).
+				AddRow(4, 2, models.WebhookTaskCreated, []byte(`{}`), models.DeliveryPending, 1, now, 500, "boom", now, nil, "https://ci.example.com/hook", "s3cret"))
+
+		due, err := repo.ClaimDeliveries(context.Background(), 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, due, 1)
+		assert.Equal(t, "https://ci.example.com/hook", due[0].URL)
+		assert.Equal(t, 500, *due[0].Delivery.ResponseStatus)
+		assert.JSONEq(t, `{}`, string(due[0].Delivery.Payload))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db, time.Second)
+	const query = "SELECT .+ FROM webhook_deliveries d WHERE d.webhook_id = \\$1 AND \\(\\$2 = 0 OR d.id < \\$2\\) ORDER BY d.id DESC LIMIT \\$3"
+
+	t.Run("must return a cursor when there are more deliveries", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WithArgs(int64(2), int64(0), 2).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns).
+				AddRow(9, 2, models.WebhookTaskCreated, []byte(`{}`), models.DeliveryDelivered, 1, now, 200, "", now, now).
+				AddRow(8, 2, models.WebhookTaskDeleted, []byte(`{}`), models.DeliveryFailed, 8, now, nil, "timeout", now, nil))
+
+		page, err := repo.ListDeliveries(context.Background(), 2, "", 1)
+		assert.NoError(t, err)
+		assert.Len(t, page.Deliveries, 1)
+		assert.NotEmpty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		mock.ExpectQuery(query).
+			WithArgs(int64(2), int64(9), 2).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns))
+
+		_, err = repo.ListDeliveries(context.Background(), 2, page.NextCursor, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrInvalidCursor for a malformed cursor", func(t *testing.T) {
+		_, err := repo.ListDeliveries(context.Background(), 2, "!!", 1)
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var errInternalAddress = errors.New("the webhook resolves to an internal address")
+
+// NewClient returns an http.Client fit for posting to user supplied URLs. It
+// refuses to connect to loopback, private, link-local or unspecified
+// addresses, checked on the resolved IP at dial time so that a public name
+// pointing inside the network is caught as well. Redirects are not followed:
+// the 3xx response counts as a failed attempt.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the connection on our behalf, out of reach of the
+	// dial check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if internalAddr(ip) {
+		return errInternalAddress
+	}
+	return nil
+}
+
+// internalHost reports whether a URL host names this machine or the private
+// network outright. Names that only resolve there are left to the dialer.
+func internalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(host)
+	return err == nil && internalAddr(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598. It is not
+// covered by IsPrivate, yet it is routed inside provider networks only.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func internalAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	r "todo_list_api/internal/webhook/repository"
+	"todo_list_api/pkg/models"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign returns the value of SignatureHeader for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const (
+	dispatchBatch = 50
+	// dispatchLeaseMargin pads the lease for the time spent recording
+	// attempts, on top of the time the posts themselves may take.
+	dispatchLeaseMargin = time.Minute
+	// defaultDispatchLease is used with a client that has no timeout, which
+	// leaves nothing to derive the lease from.
+	defaultDispatchLease = 5 * time.Minute
+)
+
+// Dispatcher posts queued deliveries to their webhooks. A delivery that does
+// not get a 2xx response is retried after backoff, doubling on every attempt,
+// and marked as failed after maxAttempts. Receivers may see a delivery more
+// than once and can use DeliveryHeader to ignore repeats.
+type Dispatcher struct {
+	repo        r.Repository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	lease       time.Duration
+	now         func() time.Time
+}
+
+func NewDispatcher(repo r.Repository, client *http.Client, maxAttempts int, backoff time.Duration) *Dispatcher {
+	// A batch is posted one delivery at a time, so its lease must cover every
+	// one of them running into the client timeout, or a slow batch could be
+	// claimed again while still in flight.
+	lease := defaultDispatchLease
+	if client.Timeout > 0 {
+		lease = dispatchBatch*client.Timeout + dispatchLeaseMargin
+	}
+	return &Dispatcher{repo: repo, client: client, maxAttempts: maxAttempts, backoff: backoff, lease: lease, now: time.Now}
+}
+
+// Dispatch attempts every delivery that is due and returns how many were
+// attempted.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		due, err := d.repo.ClaimDeliveries(ctx, dispatchBatch, d.lease)
+		if err != nil {
+			return attempted, err
+		}
+
+		for _, claim := range due {
+			d.attempt(ctx, claim)
+			if err := d.repo.RecordAttempt(ctx, claim.Delivery); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+
+		if len(due) < dispatchBatch {
+			return attempted, nil
+		}
+	}
+}
+
+// attempt posts a delivery once and updates it with the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, claim *r.DueDelivery) {
+	delivery := claim.Delivery
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+
+	status, err := d.post(ctx, claim)
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	now := d.now()
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = models.DeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff << min(delivery.Attempts-1, 16))
+	}
+	delivery.LastError = err.Error()
+}
+
+func (d *Dispatcher) post(ctx context.Context, claim *r.DueDelivery) (int, error) {
+	delivery := claim.Delivery
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, claim.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(claim.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("the webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Run dispatches once immediately and then on every interval until ctx is
+// done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			log.Printf("could not dispatch webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
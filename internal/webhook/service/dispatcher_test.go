@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	m "todo_list_api/internal/webhook/mocks"
+	"todo_list_api/internal/webhook/repository"
+	"todo_list_api/internal/webhook/service"
+	"todo_list_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		service.Sign("key", []byte("The quick brown fox jumps over the lazy dog")),
+	)
+}
+
+func TestDispatch(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	mockRepo := new(m.MockRepository)
+	dispatcher := service.NewDispatcher(mockRepo, receiver.Client(), 3, time.Minute)
+
+	claim := func(attempts int) *repository.DueDelivery {
+		return &repository.DueDelivery{
+			Delivery: &models.WebhookDelivery{ID: 4, WebhookID: 2, Event: models.WebhookTaskCreated, Payload: []byte(`{"task_id":5}`), Status: models.DeliveryPending, Attempts: attempts},
+			URL:      receiver.URL,
+			Secret:   "s3cret",
+		}
+	}
+
+	t.Run("must post a signed payload and mark it delivered", func(t *testing.T) {
+		due := claim(0)
+		mockRepo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.DueDelivery{due}, nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, due.Delivery).Return(nil).Once()
+
+		attempted, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		assert.Equal(t, `{"task_id":5}`, string(body))
+		assert.Equal(t, service.Sign("s3cret", body), received.Header.Get(service.SignatureHeader))
+		assert.Equal(t, "4", received.Header.Get(service.DeliveryHeader))
+		assert.Equal(t, models.WebhookTaskCreated, received.Header.Get(service.EventHeader))
+
+		assert.Equal(t, models.DeliveryDelivered, due.Delivery.Status)
+		assert.Equal(t, 1, due.Delivery.Attempts)
+		assert.Equal(t, http.StatusOK, *due.Delivery.ResponseStatus)
+		assert.NotNil(t, due.Delivery.DeliveredAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should back off exponentially after a failed attempt", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		due := claim(1)
+		mockRepo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.DueDelivery{due}, nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, due.Delivery).Return(nil).Once()
+
+		before := time.Now()
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, models.DeliveryPending, due.Delivery.Status)
+		assert.Equal(t, 2, due.Delivery.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, *due.Delivery.ResponseStatus)
+		assert.Contains(t, due.Delivery.LastError, "503")
+		assert.WithinDuration(t, before.Add(2*time.Minute), due.Delivery.NextAttemptAt, time.Second)
+	})
+
+	t.Run("should give up after the last attempt", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		due := claim(2)
+		mockRepo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.DueDelivery{due}, nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, due.Delivery).Return(nil).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryFailed, due.Delivery.Status)
+		assert.Nil(t, due.Delivery.DeliveredAt)
+	})
+
+	t.Run("should record unreachable receivers without a status", func(t *testing.T) {
+		due := claim(0)
+		due.URL = "http://127.0.0.1:1/hook"
+		mockRepo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.DueDelivery{due}, nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, due.Delivery).Return(nil).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Nil(t, due.Delivery.ResponseStatus)
+		assert.NotEmpty(t, due.Delivery.LastError)
+	})
+}
+
+func TestDispatchLease(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	dispatcher := service.NewDispatcher(mockRepo, &http.Client{Timeout: 10 * time.Second}, 3, time.Minute)
+
+	t.Run("must lease a batch for long enough to time out every delivery in it", func(t *testing.T) {
+		mockRepo.On("ClaimDeliveries", mock.Anything, 50, 50*10*time.Second+time.Minute).Return([]*repository.DueDelivery{}, nil).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	client := service.NewClient(time.Second)
+
+	t.Run("must refuse to connect to internal addresses", func(t *testing.T) {
+		_, err := client.Post(receiver.URL, "application/json", nil)
+		assert.ErrorContains(t, err, "internal address")
+	})
+
+	t.Run("must refuse to connect to the carrier-grade NAT range", func(t *testing.T) {
+		_, err := client.Post("http://100.64.0.1:1/hook", "application/json", nil)
+		assert.ErrorContains(t, err, "internal address")
+	})
+
+	t.Run("must not follow redirects", func(t *testing.T) {
+		assert.ErrorIs(t, client.CheckRedirect(nil, nil), http.ErrUseLastResponse)
+	})
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	r "todo_list_api/internal/webhook/repository"
+	"todo_list_api/pkg/models"
+)
+
+// Publisher queues webhook deliveries for the task events relayed from the
+// outbox. Deliveries are keyed by the event's idempotency key, so an event
+// relayed twice is only queued once per webhook.
+type Publisher struct {
+	repo r.Repository
+}
+
+func NewPublisher(repo r.Repository) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// Publish implements the outbox EventPublisher.
+func (p *Publisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	var taskEvent models.TaskEvent
+	if err := json.Unmarshal(event.Payload, &taskEvent); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&models.WebhookPayload{
+		Event:          event.Topic,
+		IdempotencyKey: event.IdempotencyKey,
+		TaskID:         event.TaskID,
+		ActorID:        taskEvent.ActorID,
+		Changes:        taskEvent.Changes,
+		OccurredAt:     taskEvent.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.EnqueueDeliveries(ctx, event.Topic, event.IdempotencyKey, event.TaskID, body)
+	return err
+}
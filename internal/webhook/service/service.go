@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strings"
+	r "todo_list_api/internal/webhook/repository"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+)
+
+type Service interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, userID, id int64) error
+	GetWebhook(ctx context.Context, userID, id int64) (*models.Webhook, error)
+	ListDeliveries(ctx context.Context, userID, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error)
+	ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error)
+}
+
+type WebhookService struct {
+	repo r.Repository
+}
+
+func NewWebhookService(repo r.Repository) Service {
+	return &WebhookService{repo: repo}
+}
+
+// CreateWebhook subscribes webhook.URL for its owner. URLs that name an
+// internal host outright are refused here; NewClient catches the rest when
+// delivering. A secret is generated when none is given; either way it is
+// returned only here.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" || internalHost(target.Hostname()) {
+		return utils.ErrInvalidWebhookURL
+	}
+
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return utils.ErrInvalidEvent
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	webhook.Events = events
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return s.repo.CreateWebhook(ctx, webhook)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, id int64) error {
+	if id < 0 {
+		return utils.ErrInvalidId
+	}
+
+	return s.repo.DeleteWebhook(ctx, userID, id)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	if id < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	webhook, err := s.storedWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error) {
+	webhooks, err := s.repo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, webhookID int64, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	if webhookID < 0 {
+		return nil, utils.ErrInvalidId
+	}
+
+	if limit < 0 || limit > models.MaxPageSize {
+		return nil, utils.ErrInvalidLimit
+	}
+	if limit == 0 {
+		limit = models.DefaultPageSize
+	}
+
+	if _, err := s.storedWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListDeliveries(ctx, webhookID, cursor, limit)
+}
+
+func (s *WebhookService) storedWebhook(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	webhook, err := s.repo.GetWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook == nil {
+		return nil, utils.ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	m "todo_list_api/internal/webhook/mocks"
+	"todo_list_api/internal/webhook/service"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewWebhookService(mockRepo)
+
+	t.Run("should reject urls that are not absolute http urls", func(t *testing.T) {
+		for _, url := range []string{"", "/hooks", "ftp://example.com", "https://"} {
+			err := svc.CreateWebhook(context.Background(), &models.Webhook{OwnerID: 7, URL: url})
+			assert.ErrorIs(t, err, utils.ErrInvalidWebhookURL, url)
+		}
+	})
+
+	t.Run("should reject urls that name an internal host", func(t *testing.T) {
+		for _, url := range []string{"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://10.0.0.5/hook", "http://169.254.169.254/latest", "http://[::1]/hook", "http://0.0.0.0/hook", "http://100.64.0.1/hook", "http://[::ffff:100.127.255.254]/hook"} {
+			err := svc.CreateWebhook(context.Background(), &models.Webhook{OwnerID: 7, URL: url})
+			assert.ErrorIs(t, err, utils.ErrInvalidWebhookURL, url)
+		}
+	})
+
+	t.Run("should reject unknown events", func(t *testing.T) {
+		err := svc.CreateWebhook(context.Background(), &models.Webhook{OwnerID: 7, URL: "https://ci.example.com", Events: []string{"task.exploded"}})
+		assert.ErrorIs(t, err, utils.ErrInvalidEvent)
+	})
+
+	t.Run("must generate a secret and drop repeated events", func(t *testing.T) {
+		mockRepo.On("CreateWebhook", mock.Anything, mock.Anything).Return(nil).Once()
+
+		webhook := &models.Webhook{OwnerID: 7, URL: " https://ci.example.com/hook ", Events: []string{models.WebhookTaskCreated, models.WebhookTaskCreated}}
+		assert.NoError(t, svc.CreateWebhook(context.Background(), webhook))
+		assert.Equal(t, "https://ci.example.com/hook", webhook.URL)
+		assert.Equal(t, []string{models.WebhookTaskCreated}, webhook.Events)
+		assert.Len(t, webhook.Secret, 64)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetWebhook(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewWebhookService(mockRepo)
+
+	t.Run("must not reveal the secret", func(t *testing.T) {
+		mockRepo.On("GetWebhook", mock.Anything, int64(7), int64(2)).Return(&models.Webhook{ID: 2, OwnerID: 7, Secret: "s3cret"}, nil).Once()
+
+		webhook, err := svc.GetWebhook(context.Background(), 7, 2)
+		assert.NoError(t, err)
+		assert.Empty(t, webhook.Secret)
+	})
+
+	t.Run("should return ErrWebhookNotFound for someone else's webhook", func(t *testing.T) {
+		mockRepo.On("GetWebhook", mock.Anything, int64(8), int64(2)).Return((*models.Webhook)(nil), nil).Once()
+
+		_, err := svc.GetWebhook(context.Background(), 8, 2)
+		assert.ErrorIs(t, err, utils.ErrWebhookNotFound)
+	})
+}
+
+func TestListDeliveries(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	svc := service.NewWebhookService(mockRepo)
+
+	t.Run("should reject a limit above the maximum page size", func(t *testing.T) {
+		_, err := svc.ListDeliveries(context.Background(), 7, 2, "", models.MaxPageSize+1)
+		assert.ErrorIs(t, err, utils.ErrInvalidLimit)
+	})
+
+	t.Run("must only list the deliveries of the caller's webhooks", func(t *testing.T) {
+		mockRepo.On("GetWebhook", mock.Anything, int64(8), int64(2)).Return((*models.Webhook)(nil), nil).Once()
+
+		_, err := svc.ListDeliveries(context.Background(), 8, 2, "", 0)
+		assert.ErrorIs(t, err, utils.ErrWebhookNotFound)
+		mockRepo.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPublisher(t *testing.T) {
+	mockRepo := new(m.MockRepository)
+	publisher := service.NewPublisher(mockRepo)
+
+	event := &models.OutboxEvent{
+		ID:             3,
+		IdempotencyKey: "task-event-11",
+		Topic:          models.WebhookTaskUpdated,
+		TaskID:         5,
+		Payload:        []byte(`{"id":11,"task_id":5,"actor_id":7,"action":"updated","changes":{"title":{"before":"Draft","after":"Ship"}},"created_at":"2024-05-01T10:00:00.5+00:00"}`),
+	}
+
+	t.Run("must queue the event's changes under its idempotency key", func(t *testing.T) {
+		mockRepo.On("EnqueueDeliveries", mock.Anything, models.WebhookTaskUpdated, "task-event-11", int64(5), mock.MatchedBy(func(payload []byte) bool {
+			return assert.JSONEq(t, `{"event":"task.updated","idempotency_key":"task-event-11","task_id":5,"actor_id":7,"changes":{"title":{"before":"Draft","after":"Ship"}},"occurred_at":"2024-05-01T10:00:00.5Z"}`, string(payload))
+		})).Return(int64(1), nil).Once()
+
+		err := publisher.Publish(context.Background(), event)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return the error so the relay retries", func(t *testing.T) {
+		mockRepo.On("EnqueueDeliveries", mock.Anything, models.WebhookTaskUpdated, "task-event-11", int64(5), mock.Anything).
+			Return(int64(0), errors.New("connection reset")).Once()
+
+		err := publisher.Publish(context.Background(), event)
+		assert.EqualError(t, err, "connection reset")
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,177 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example from section 1.3 of RFC 6455.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// maskedFrame builds a frame the way a client sends it.
+func maskedFrame(fin bool, opcode int, payload []byte) []byte {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	key := [4]byte{1, 2, 3, 4}
+	frame = append(frame, key[:]...)
+	masked := append([]byte(nil), payload...)
+	mask(key, masked)
+	return append(frame, masked...)
+}
+
+// pipe returns a server connection along with the raw client end, whose
+// output is collected in the background.
+func pipe(t *testing.T) (*Conn, net.Conn, func() []byte) {
+	server, client := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+
+	var received bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&received, client)
+		close(done)
+	}()
+
+	return newConn(server, bufio.NewReader(server), false), client, func() []byte {
+		client.Close()
+		<-done
+		return received.Bytes()
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	t.Run("must join fragments and answer pings in between", func(t *testing.T) {
+		conn, client, output := pipe(t)
+		go func() {
+			client.Write(maskedFrame(false, TextMessage, []byte("hel")))
+			client.Write(maskedFrame(true, PingMessage, []byte("beat")))
+			client.Write(maskedFrame(true, continuationFrame, []byte("lo")))
+		}()
+
+		opcode, message, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, TextMessage, opcode)
+		assert.Equal(t, "hello", string(message))
+
+		conn.conn.Close()
+		assert.Equal(t, []byte{0x80 | PongMessage, 4, 'b', 'e', 'a', 't'}, output())
+	})
+
+	t.Run("should echo a close from the peer", func(t *testing.T) {
+		conn, client, output := pipe(t)
+		go client.Write(maskedFrame(true, CloseMessage, binary.BigEndian.AppendUint16(nil, CloseGoingAway)))
+
+		_, _, err := conn.ReadMessage()
+		var closeErr *CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equal(t, CloseGoingAway, closeErr.Code)
+		assert.Equal(t, []byte{0x80 | CloseMessage, 2, 0x03, 0xe9}, output())
+	})
+
+	t.Run("must reject unmasked frames from clients", func(t *testing.T) {
+		conn, client, output := pipe(t)
+		go client.Write([]byte{0x80 | TextMessage, 2, 'h', 'i'})
+
+		_, _, err := conn.ReadMessage()
+		var closeErr *CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equal(t, CloseProtocolError, closeErr.Code)
+		assert.Equal(t, []byte{0x03, 0xea}, output()[2:4])
+	})
+
+	t.Run("should close when a message goes over the limit", func(t *testing.T) {
+		conn, client, output := pipe(t)
+		conn.SetReadLimit(4)
+		go func() {
+			client.Write(maskedFrame(false, BinaryMessage, []byte("abc")))
+			client.Write(maskedFrame(true, continuationFrame, []byte("de")))
+		}()
+
+		_, _, err := conn.ReadMessage()
+		var closeErr *CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equal(t, CloseMessageTooBig, closeErr.Code)
+		output()
+	})
+
+	t.Run("should reject text that is not utf-8", func(t *testing.T) {
+		conn, client, output := pipe(t)
+		go client.Write(maskedFrame(true, TextMessage, []byte{0xff, 0xfe}))
+
+		_, _, err := conn.ReadMessage()
+		var closeErr *CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equal(t, CloseInvalidPayload, closeErr.Code)
+		output()
+	})
+}
+
+func TestUpgrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			return
+		}
+		conn.SetReadLimit(1 << 20)
+
+		for {
+			opcode, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(opcode, message)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("should refuse requests that are not a handshake", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+		assert.Equal(t, "13", resp.Header.Get("Sec-WebSocket-Version"))
+	})
+
+	t.Run("must exchange messages of every length encoding", func(t *testing.T) {
+		conn, err := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		assert.NoError(t, err)
+		defer conn.Close(CloseNormalClosure, "")
+		conn.SetReadLimit(1 << 20)
+
+		for _, size := range []int{5, 300, 70000} {
+			sent := bytes.Repeat([]byte("x"), size)
+			assert.NoError(t, conn.WriteMessage(BinaryMessage, sent))
+
+			opcode, received, err := conn.ReadMessage()
+			assert.NoError(t, err)
+			assert.Equal(t, BinaryMessage, opcode)
+			assert.Equal(t, sent, received)
+		}
+	})
+
+	t.Run("should not write after closing", func(t *testing.T) {
+		conn, err := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		assert.NoError(t, err)
+
+		conn.Close(CloseNormalClosure, "")
+		assert.ErrorIs(t, conn.WriteMessage(TextMessage, []byte("late")), ErrClosed)
+	})
+}
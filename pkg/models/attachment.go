@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Attachment describes a file uploaded to a task. The content itself lives in
+// blob storage under StorageKey, which is never exposed to clients.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	TaskID      int64     `json:"task_id"`
+	UploaderID  int64     `json:"uploader_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DefaultMaxAttachmentSize caps the size of an upload, in bytes, unless the
+// server is configured otherwise.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// AttachmentTypes lists the content types that may be uploaded: screenshots,
+// logs and the archives or documents they usually come in.
+var AttachmentTypes = map[string]bool{
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"text/plain":         true,
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+}
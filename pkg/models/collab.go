@@ -0,0 +1,44 @@
+package models
+
+import "encoding/json"
+
+// Message types of the project collaboration channel. Clients send lock,
+// unlock, edit and save; everything else comes from the server.
+const (
+	CollabWelcome     = "welcome"
+	CollabJoined      = "presence.joined"
+	CollabLeft        = "presence.left"
+	CollabLock        = "lock"
+	CollabLocked      = "locked"
+	CollabUnlock      = "unlock"
+	CollabUnlocked    = "unlocked"
+	CollabEdit        = "edit"
+	CollabSave        = "save"
+	CollabTaskUpdated = "task.updated"
+	CollabError       = "error"
+)
+
+// CollabMessage is a message on the collaboration channel of a project.
+//
+// An edit carries the value of a field as it is being typed, and is relayed
+// to the rest of the room without being stored. A save stores Changes, a
+// merge patch, through the same rules as PATCH /tasks/{id}; Version works
+// like If-Match.
+type CollabMessage struct {
+	Type    string          `json:"type"`
+	TaskID  int64           `json:"task_id,omitempty"`
+	UserID  int64           `json:"user_id,omitempty"`
+	Field   string          `json:"field,omitempty"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Changes json.RawMessage `json:"changes,omitempty"`
+	Version int64           `json:"version,omitempty"`
+	Task    *Task           `json:"task,omitempty"`
+	// Users and Locks describe the room in the welcome message: who is
+	// connected, and who holds the lock of each task being edited.
+	Users []int64         `json:"users,omitempty"`
+	Locks map[int64]int64 `json:"locks,omitempty"`
+	Error *ErrorResponse  `json:"error,omitempty"`
+
+	// Patch is Changes once parsed.
+	Patch *TaskPatch `json:"-"`
+}
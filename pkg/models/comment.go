@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Comment is a message in the discussion thread of a task. The body is
+// Markdown and is stored as written; rendering it is left to clients.
+type Comment struct {
+	ID          int64     `json:"id"`
+	TaskID      int64     `json:"task_id"`
+	AuthorID    int64     `json:"author_id"`
+	AuthorEmail string    `json:"author_email"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CommentPage struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// MaxCommentLength caps the size of a comment body, in characters.
+const MaxCommentLength = 10000
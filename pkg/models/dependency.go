@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TaskDependency records that TaskID cannot be completed before BlockedByID.
+type TaskDependency struct {
+	TaskID      int64     `json:"task_id"`
+	BlockedByID int64     `json:"blocked_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TaskBlocker is a task that blocks another. Task is only set when the user
+// can see the blocking task; otherwise the blocker is reduced to its id and
+// status, which is all the blocked task's collaborators need to know.
+type TaskBlocker struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	*Task
+}
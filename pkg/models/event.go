@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	EventCreated    = "created"
+	EventUpdated    = "updated"
+	EventDeleted    = "deleted"
+	EventRestored   = "restored"
+	EventArchived   = "archived"
+	EventUnarchived = "unarchived"
+)
+
+// FieldChange holds the value of a task field before and after a change.
+// Before is nil for fields set on creation and After is nil for cleared ones.
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type TaskEvent struct {
+	ID        int64                  `json:"id"`
+	TaskID    int64                  `json:"task_id"`
+	ActorID   *int64                 `json:"actor_id"`
+	Action    string                 `json:"action"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+	// Position orders the event on the live stream. It is assigned in
+	// commit order when the event is relayed, and is zero until then.
+	Position int64 `json:"-"`
+}
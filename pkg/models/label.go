@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type Label struct {
+	ID        int64     `json:"id"`
+	OwnerID   int64     `json:"owner_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskLabel is the view of a label embedded in task responses.
+type TaskLabel struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// DefaultLabelColor is used when a label is created without a color.
+const DefaultLabelColor = "#808080"
+
+const (
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type TaskMember struct {
+	TaskID    int64     `json:"task_id"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProjectMember shares a project, and every task filed under it, with a
+// user. Projects are shared with viewers and editors only.
+type ProjectMember struct {
+	ProjectID int64     `json:"project_id"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
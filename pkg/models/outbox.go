@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event waiting to be published. It is written in
+// the same transaction as the change it describes, and may be published more
+// than once: consumers should ignore keys they have already seen.
+//
+// Task events have the topic "task.<action>" and a TaskEvent as payload.
+type OutboxEvent struct {
+	ID             int64           `json:"id"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	Topic          string          `json:"topic"`
+	TaskID         int64           `json:"task_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	PublishedAt    *time.Time      `json:"published_at"`
+}
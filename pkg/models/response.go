@@ -3,3 +3,10 @@ package models
 type Response struct {
 	Message string `json:"message"`
 }
+
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
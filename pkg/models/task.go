@@ -3,12 +3,38 @@ package models
 import "time"
 
 type Task struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Overdue     bool       `json:"overdue"`
+	ParentID    *int64     `json:"parent_id"`
+	ProjectID   *int64     `json:"project_id"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	// Recurrence is an iCalendar RRULE. A task carrying one is the template
+	// of a series, and the occurrences generated from it point back to it
+	// through SeriesID.
+	Recurrence string        `json:"recurrence,omitempty"`
+	SeriesID   *int64        `json:"series_id,omitempty"`
+	Labels     []TaskLabel   `json:"labels"`
+	Progress   *TaskProgress `json:"progress,omitempty"`
+	OwnerID    int64         `json:"owner_id"`
+	Version    int64         `json:"version"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	DeletedAt  *time.Time    `json:"deleted_at,omitempty"`
+	// CommentCount is only filled in when a single task is fetched.
+	CommentCount *int `json:"comment_count,omitempty"`
+}
+
+// TaskProgress rolls up the completion of a task's direct subtasks.
+type TaskProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Percent   int `json:"percent"`
 }
 
 const (
@@ -16,3 +42,101 @@ const (
 	StatusInProgress = "In progress"
 	StatusCompleted  = "Completed"
 )
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// PriorityRank orders priorities from least to most pressing; unknown
+// priorities rank below low.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	}
+	return 0
+}
+
+// IsOverdue reports whether the task has passed its due date without being
+// completed.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.CompletedAt == nil && t.DueAt.Before(now)
+}
+
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortTitle     = "title"
+	SortDueAt     = "due_at"
+	SortPriority  = "priority"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type TaskFilter struct {
+	UserID        int64
+	Status        string
+	Priority      string
+	Labels        []string
+	LabelMatch    string
+	ProjectID     *int64
+	Archived      bool
+	Overdue       *bool
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Sort          string
+	Order         string
+	Limit         int
+	Cursor        string
+}
+
+type TaskPage struct {
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type TaskSearchResult struct {
+	Task
+	Rank float64 `json:"rank"`
+	// Snippet is HTML: the matching text, escaped, with the matched terms
+	// wrapped in <mark> tags.
+	Snippet string `json:"snippet"`
+}
+
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	Status      *string
+	Priority    *string
+	// DueAt is set when the patch touches the due date and points to nil
+	// when the due date is cleared.
+	DueAt **time.Time
+	// ParentID follows the same convention, pointing to nil when the task
+	// is detached from its parent.
+	ParentID **int64
+	// ProjectID points to nil when the task is moved out of its project.
+	ProjectID **int64
+	// Recurrence points to "" when the task stops repeating.
+	Recurrence *string
+}
+
+func (p *TaskPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.Status == nil && p.Priority == nil && p.DueAt == nil && p.ParentID == nil && p.ProjectID == nil && p.Recurrence == nil
+}
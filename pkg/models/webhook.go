@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookTaskCreated    = "task.created"
+	WebhookTaskUpdated    = "task.updated"
+	WebhookTaskDeleted    = "task.deleted"
+	WebhookTaskRestored   = "task.restored"
+	WebhookTaskArchived   = "task.archived"
+	WebhookTaskUnarchived = "task.unarchived"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to: one per task
+// history action.
+var WebhookEvents = []string{
+	WebhookTaskCreated,
+	WebhookTaskUpdated,
+	WebhookTaskDeleted,
+	WebhookTaskRestored,
+	WebhookTaskArchived,
+	WebhookTaskUnarchived,
+}
+
+// Webhook subscribes a URL to the lifecycle events of the tasks its owner
+// can see. An empty Events list subscribes to all of them. Secret keys the
+// HMAC-SHA256 signature of every payload and is only returned on creation.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	OwnerID   int64     `json:"owner_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for one webhook, along with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted to webhooks. It carries the same
+// changes as the task history; IdempotencyKey is unique to the event, so
+// receivers can use it to ignore repeats.
+//
+// Since deliveries are fed from the outbox, the payload no longer embeds the
+// task itself under "task": a receiver gets who made the change in actor_id
+// and the before and after of every field in changes, and fetches the task
+// when it needs the rest of its state.
+type WebhookPayload struct {
+	Event          string                 `json:"event"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	TaskID         int64                  `json:"task_id"`
+	ActorID        *int64                 `json:"actor_id"`
+	Changes        map[string]FieldChange `json:"changes"`
+	OccurredAt     time.Time              `json:"occurred_at"`
+}
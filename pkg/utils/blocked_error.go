@@ -0,0 +1,19 @@
+package utils
+
+// BlockedError reports an attempt to complete a task that still waits on
+// other tasks, listing the ids of those blockers.
+type BlockedError struct {
+	Blockers []int64
+}
+
+func (e *BlockedError) Error() string {
+	return ErrTaskBlocked.Error()
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrTaskBlocked
+}
+
+func (e *BlockedError) ErrorDetails() any {
+	return map[string]any{"blockers": e.Blockers}
+}
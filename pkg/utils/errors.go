@@ -3,12 +3,74 @@ package utils
 import "errors"
 
 var (
-	ErrEmptyID        = errors.New("ID cannot be empty")
-	ErrEmptyTitle     = errors.New("title cannot be empty")
-	ErrEmptyStatus    = errors.New("status cannot be empty")
-	ErrInvalidStatus  = errors.New("the status is invalid")
-	ErrInvalidId      = errors.New("the id is invalid")
-	ErrTaskNotFound   = errors.New("task not found")
-	ErrInvalidPayload = errors.New("invalid request payload")
-	ErrFailedEncode   = errors.New("failed to encode task")
+	ErrEmptyID            = errors.New("ID cannot be empty")
+	ErrEmptyTitle         = errors.New("title cannot be empty")
+	ErrEmptyStatus        = errors.New("status cannot be empty")
+	ErrInvalidStatus      = errors.New("the status is invalid")
+	ErrInvalidId          = errors.New("the id is invalid")
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrInvalidPayload     = errors.New("invalid request payload")
+	ErrFailedEncode       = errors.New("failed to encode task")
+	ErrInvalidSort        = errors.New("the sort field is invalid")
+	ErrInvalidOrder       = errors.New("the sort order is invalid")
+	ErrInvalidLimit       = errors.New("the limit is invalid")
+	ErrInvalidCursor      = errors.New("the cursor is invalid")
+	ErrInvalidDate        = errors.New("the date is invalid")
+	ErrEmptyQuery         = errors.New("search query cannot be empty")
+	ErrInvalidPatch       = errors.New("the patch document is invalid")
+	ErrImmutableField     = errors.New("the field cannot be modified")
+	ErrUnsupportedMedia   = errors.New("unsupported content type")
+	ErrPreconditionFailed = errors.New("the task was modified by another request")
+	ErrInvalidPriority    = errors.New("the priority is invalid")
+	ErrInvalidFilter      = errors.New("the filter is invalid")
+	ErrInvalidTransition  = errors.New("the status transition is not allowed")
+	ErrInvalidParent      = errors.New("the parent task is invalid")
+	ErrParentCycle        = errors.New("a task cannot be nested under itself or its subtasks")
+	ErrInvalidDependency  = errors.New("the blocking task is invalid")
+	ErrDependencyCycle    = errors.New("the dependency would make the task wait on itself")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrTaskBlocked        = errors.New("the task has incomplete blockers")
+	ErrEmptyLabelName     = errors.New("label name cannot be empty")
+	ErrInvalidColor       = errors.New("the color must be a hex value like #1f6feb")
+	ErrLabelNotFound      = errors.New("label not found")
+	ErrLabelTaken         = errors.New("a label with this name already exists")
+	ErrEmptyProjectName   = errors.New("project name cannot be empty")
+	ErrInvalidProject     = errors.New("the project is invalid")
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrProjectArchived    = errors.New("the project is archived")
+	ErrInvalidRecurrence  = errors.New("the recurrence rule is invalid")
+	ErrMissingDueDate     = errors.New("a recurring task needs a due date")
+	ErrEmptyComment       = errors.New("comment cannot be empty")
+	ErrCommentTooLong     = errors.New("comment is too long")
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrMissingFile        = errors.New("the upload must include a file part")
+	ErrAttachmentTooLarge = errors.New("the file is too large")
+	ErrAttachmentType     = errors.New("the file type is not allowed")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrInvalidRange       = errors.New("the requested range cannot be satisfied")
+	ErrInvalidWebhookURL  = errors.New("the webhook url must be an absolute http or https url to a public host")
+	ErrInvalidEvent       = errors.New("the webhook event is invalid")
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrInvalidEventID     = errors.New("the last event id is invalid")
+	ErrWebSocketRequired  = errors.New("this endpoint only accepts websocket connections")
+	ErrInvalidMessage     = errors.New("the message is invalid")
+	ErrTaskLocked         = errors.New("the task is being edited by someone else")
+	ErrLockRequired       = errors.New("the task must be locked before editing it")
+
+	ErrEmptyEmail         = errors.New("email cannot be empty")
+	ErrInvalidEmail       = errors.New("the email is invalid")
+	ErrEmptyPassword      = errors.New("password cannot be empty")
+	ErrWeakPassword       = errors.New("password must have at least 8 characters")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUnauthorized       = errors.New("authentication required")
+	ErrInvalidToken       = errors.New("the token is invalid")
+	ErrExpiredToken       = errors.New("the token has expired")
+	ErrForbidden          = errors.New("you do not have permission to perform this action")
+	ErrOriginNotAllowed   = errors.New("requests from this origin are not allowed")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmptyRole          = errors.New("role cannot be empty")
+	ErrInvalidRole        = errors.New("the role is invalid")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrCannotShareOwner   = errors.New("the owner cannot be added as a member")
 )
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// EncodeIDCursor turns the id of the last row of a page into an opaque
+// cursor, for listings that page through rows in id order.
+func EncodeIDCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// DecodeIDCursor reverses EncodeIDCursor, rejecting anything it did not
+// produce with ErrInvalidCursor.
+func DecodeIDCursor(encoded string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,23 @@
+package utils_test
+
+import (
+	"testing"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDCursor(t *testing.T) {
+	t.Run("must round-trip an id", func(t *testing.T) {
+		id, err := utils.DecodeIDCursor(utils.EncodeIDCursor(42))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("should return ErrInvalidCursor for a malformed cursor", func(t *testing.T) {
+		for _, cursor := range []string{"!!", utils.EncodeIDCursor(0), "YWJj"} {
+			_, err := utils.DecodeIDCursor(cursor)
+			assert.ErrorIs(t, err, utils.ErrInvalidCursor, cursor)
+		}
+	})
+}
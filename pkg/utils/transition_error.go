@@ -0,0 +1,32 @@
+package utils
+
+import "fmt"
+
+// TransitionError reports a status change the workflow does not allow, along
+// with the statuses the task may move to instead.
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("a task cannot move from %q to %q", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+func (e *TransitionError) ErrorDetails() any {
+	allowed := e.Allowed
+	if allowed == nil {
+		allowed = []string{}
+	}
+
+	return map[string]any{
+		"from":    e.From,
+		"to":      e.To,
+		"allowed": allowed,
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "regexp"
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateColor accepts hex colors in the #rrggbb form.
+func ValidateColor(color string) error {
+	if !colorPattern.MatchString(color) {
+		return ErrInvalidColor
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "todo_list_api/pkg/models"
+
+func ValidatePriority(priority string) error {
+	if models.PriorityRank(priority) == 0 {
+		return ErrInvalidPriority
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "todo_list_api/pkg/models"
+
+var roleRanks = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleOwner:  3,
+}
+
+func ValidateRole(role string) error {
+	if _, ok := roleRanks[role]; !ok {
+		return ErrInvalidRole
+	}
+
+	return nil
+}
+
+func HasRole(role, required string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[required]
+}
+
+// HighestRole returns the strongest of roles, or an empty role if there are
+// none.
+func HighestRole(roles []string) string {
+	highest := ""
+	for _, role := range roles {
+		if roleRanks[role] > roleRanks[highest] {
+			highest = role
+		}
+	}
+	return highest
+}
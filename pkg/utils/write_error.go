@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"todo_list_api/pkg/models"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{ErrEmptyID, http.StatusBadRequest, "empty_id"},
+	{ErrInvalidId, http.StatusBadRequest, "invalid_id"},
+	{ErrInvalidPayload, http.StatusBadRequest, "invalid_payload"},
+	{ErrInvalidSort, http.StatusBadRequest, "invalid_sort"},
+	{ErrInvalidOrder, http.StatusBadRequest, "invalid_order"},
+	{ErrInvalidLimit, http.StatusBadRequest, "invalid_limit"},
+	{ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{ErrInvalidEventID, http.StatusBadRequest, "invalid_event_id"},
+	{ErrInvalidMessage, http.StatusBadRequest, "invalid_message"},
+	{ErrWebSocketRequired, http.StatusUpgradeRequired, "websocket_required"},
+	{ErrInvalidDate, http.StatusBadRequest, "invalid_date"},
+	{ErrEmptyQuery, http.StatusBadRequest, "empty_query"},
+	{ErrInvalidFilter, http.StatusBadRequest, "invalid_filter"},
+	{ErrInvalidPatch, http.StatusBadRequest, "invalid_patch"},
+	{ErrUnsupportedMedia, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{ErrMissingFile, http.StatusBadRequest, "missing_file"},
+	{ErrAttachmentType, http.StatusUnsupportedMediaType, "unsupported_attachment_type"},
+	{ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large"},
+	{ErrInvalidRange, http.StatusRequestedRangeNotSatisfiable, "invalid_range"},
+
+	{ErrEmptyTitle, http.StatusUnprocessableEntity, "empty_title"},
+	{ErrEmptyStatus, http.StatusUnprocessableEntity, "empty_status"},
+	{ErrInvalidStatus, http.StatusUnprocessableEntity, "invalid_status"},
+	{ErrInvalidPriority, http.StatusUnprocessableEntity, "invalid_priority"},
+	{ErrEmptyEmail, http.StatusUnprocessableEntity, "empty_email"},
+	{ErrInvalidEmail, http.StatusUnprocessableEntity, "invalid_email"},
+	{ErrEmptyPassword, http.StatusUnprocessableEntity, "empty_password"},
+	{ErrWeakPassword, http.StatusUnprocessableEntity, "weak_password"},
+	{ErrEmptyRole, http.StatusUnprocessableEntity, "empty_role"},
+	{ErrInvalidRole, http.StatusUnprocessableEntity, "invalid_role"},
+	{ErrCannotShareOwner, http.StatusUnprocessableEntity, "cannot_share_owner"},
+	{ErrImmutableField, http.StatusUnprocessableEntity, "immutable_field"},
+	{ErrInvalidParent, http.StatusUnprocessableEntity, "invalid_parent"},
+	{ErrParentCycle, http.StatusUnprocessableEntity, "parent_cycle"},
+	{ErrInvalidDependency, http.StatusUnprocessableEntity, "invalid_dependency"},
+	{ErrDependencyCycle, http.StatusUnprocessableEntity, "dependency_cycle"},
+	{ErrEmptyLabelName, http.StatusUnprocessableEntity, "empty_label_name"},
+	{ErrInvalidColor, http.StatusUnprocessableEntity, "invalid_color"},
+	{ErrEmptyProjectName, http.StatusUnprocessableEntity, "empty_project_name"},
+	{ErrInvalidProject, http.StatusUnprocessableEntity, "invalid_project"},
+	{ErrInvalidRecurrence, http.StatusUnprocessableEntity, "invalid_recurrence"},
+	{ErrMissingDueDate, http.StatusUnprocessableEntity, "missing_due_date"},
+	{ErrEmptyComment, http.StatusUnprocessableEntity, "empty_comment"},
+	{ErrCommentTooLong, http.StatusUnprocessableEntity, "comment_too_long"},
+	{ErrInvalidWebhookURL, http.StatusUnprocessableEntity, "invalid_webhook_url"},
+	{ErrInvalidEvent, http.StatusUnprocessableEntity, "invalid_event"},
+
+	{ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{ErrExpiredToken, http.StatusUnauthorized, "expired_token"},
+	{ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{ErrForbidden, http.StatusForbidden, "forbidden"},
+	{ErrOriginNotAllowed, http.StatusForbidden, "origin_not_allowed"},
+
+	{ErrTaskNotFound, http.StatusNotFound, "task_not_found"},
+	{ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{ErrMemberNotFound, http.StatusNotFound, "member_not_found"},
+	{ErrDependencyNotFound, http.StatusNotFound, "dependency_not_found"},
+	{ErrLabelNotFound, http.StatusNotFound, "label_not_found"},
+	{ErrProjectNotFound, http.StatusNotFound, "project_not_found"},
+	{ErrCommentNotFound, http.StatusNotFound, "comment_not_found"},
+	{ErrAttachmentNotFound, http.StatusNotFound, "attachment_not_found"},
+	{ErrWebhookNotFound, http.StatusNotFound, "webhook_not_found"},
+	{ErrEmailTaken, http.StatusConflict, "email_taken"},
+	{ErrLabelTaken, http.StatusConflict, "label_taken"},
+	{ErrInvalidTransition, http.StatusConflict, "invalid_transition"},
+	{ErrTaskBlocked, http.StatusConflict, "task_blocked"},
+	{ErrProjectArchived, http.StatusConflict, "project_archived"},
+	{ErrTaskLocked, http.StatusConflict, "task_locked"},
+	{ErrLockRequired, http.StatusConflict, "lock_required"},
+	{ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+
+	{ErrFailedEncode, http.StatusInternalServerError, "encode_failed"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+}
+
+// ErrorDetailer is implemented by errors that carry structured details for
+// the "details" field of the error response.
+type ErrorDetailer interface {
+	ErrorDetails() any
+}
+
+// DescribeError returns the status and response body for err, as WriteError
+// sends them. Errors without a mapping are internal errors, whose message is
+// not revealed.
+func DescribeError(err error) (int, models.ErrorResponse) {
+	response := models.ErrorResponse{
+		Code:    "internal_error",
+		Message: "internal server error",
+	}
+	status := http.StatusInternalServerError
+
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			status = mapping.status
+			response.Code = mapping.code
+			response.Message = err.Error()
+			break
+		}
+	}
+
+	var detailer ErrorDetailer
+	if errors.As(err, &detailer) {
+		response.Details = detailer.ErrorDetails()
+	}
+
+	return status, response
+}
+
+func WriteError(w http.ResponseWriter, err error) {
+	status, response := DescribeError(err)
+	response.RequestID = w.Header().Get(RequestIDHeader)
+
+	if status == http.StatusInternalServerError {
+		log.Printf("request %s failed: %v", response.RequestID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&response)
+}
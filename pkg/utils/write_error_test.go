@@ -0,0 +1,87 @@
+package utils_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo_list_api/pkg/models"
+	"todo_list_api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type detailedError struct{}
+
+func (detailedError) Error() string     { return "detailed" }
+func (detailedError) Unwrap() error     { return utils.ErrInvalidStatus }
+func (detailedError) ErrorDetails() any { return map[string]string{"field": "status"} }
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"bad request", utils.ErrInvalidPayload, http.StatusBadRequest, "invalid_payload"},
+		{"validation", utils.ErrEmptyTitle, http.StatusUnprocessableEntity, "empty_title"},
+		{"wrapped validation", fmt.Errorf("create: %w", utils.ErrEmptyTitle), http.StatusUnprocessableEntity, "empty_title"},
+		{"unauthorized", utils.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+		{"forbidden", utils.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"not found", utils.ErrTaskNotFound, http.StatusNotFound, "task_not_found"},
+		{"conflict", utils.ErrEmailTaken, http.StatusConflict, "email_taken"},
+		{"transition", &utils.TransitionError{From: "Completed", To: "Pending"}, http.StatusConflict, "invalid_transition"},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should map "+tt.name+" errors to their status and code", func(t *testing.T) {
+			rr := httptest.NewRecorder()
+
+			utils.WriteError(rr, tt.err)
+
+			var response models.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.code, response.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		})
+	}
+
+	t.Run("should hide the message of unexpected errors", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		utils.WriteError(rr, errors.New("pq: connection refused"))
+
+		var response models.ErrorResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, "internal server error", response.Message)
+	})
+
+	t.Run("should include the request id and error details", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		rr.Header().Set(utils.RequestIDHeader, "req-1")
+
+		utils.WriteError(rr, detailedError{})
+
+		var response models.ErrorResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, "req-1", response.RequestID)
+		assert.Equal(t, map[string]any{"field": "status"}, response.Details)
+	})
+
+	t.Run("should list the allowed statuses of a rejected transition", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		utils.WriteError(rr, &utils.TransitionError{From: "Completed", To: "Pending", Allowed: []string{"In progress"}})
+
+		var response models.ErrorResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, map[string]any{"from": "Completed", "to": "Pending", "allowed": []any{"In progress"}}, response.Details)
+	})
+}